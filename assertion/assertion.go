@@ -0,0 +1,286 @@
+package assertion
+
+import (
+	"github.com/leobrines/curlman/executor"
+	"github.com/leobrines/curlman/jsonpath"
+	"github.com/leobrines/curlman/models"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result is the outcome of evaluating a single assertion
+type Result struct {
+	Assertion models.Assertion
+	Passed    bool
+	Message   string
+}
+
+// AllPassed reports whether every result passed
+func AllPassed(results []Result) bool {
+	for _, r := range results {
+		if !r.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+// Parse builds an assertion from its textual form, for example:
+//
+//	status 200
+//	status equals 2xx
+//	header Content-Type exists
+//	header Content-Type contains json
+//	json $.data.id equals 42
+//	json $.items[?(@.id == 7)].name equals "Ann"
+//	body contains "ok"
+//	header X-Debug equals ""
+//	duration max 500ms
+func Parse(text string) (models.Assertion, error) {
+	kind, rest := nextWord(text)
+	if kind == "" || strings.TrimSpace(rest) == "" {
+		return models.Assertion{}, fmt.Errorf("expected '<type> [property] <operator> [value]'")
+	}
+
+	a := models.Assertion{Type: strings.ToLower(kind)}
+
+	// Header and JSON assertions take a property before the operator
+	if a.Type == models.AssertHeader || a.Type == models.AssertJSONPath {
+		a.Property, rest = nextProperty(rest)
+		if strings.TrimSpace(rest) == "" {
+			return models.Assertion{}, fmt.Errorf("missing operator after '%s'", a.Property)
+		}
+	}
+
+	word, afterWord := nextWord(rest)
+	if isOperator(strings.ToLower(word)) {
+		a.Operator = strings.ToLower(word)
+		rest = afterWord
+	} else {
+		// Shorthand: "status 200" and "duration 500ms"
+		switch a.Type {
+		case models.AssertStatus:
+			a.Operator = models.OpEquals
+		case models.AssertDuration:
+			a.Operator = models.OpMax
+		default:
+			return models.Assertion{}, fmt.Errorf("unknown operator '%s'", word)
+		}
+	}
+
+	// The expected value keeps its inner spacing, only surrounding quotes are removed
+	value := strings.TrimSpace(rest)
+	if value == "" && a.Operator != models.OpExists && (a.Type == models.AssertHeader || a.Type == models.AssertJSONPath) {
+		return models.Assertion{}, fmt.Errorf("%s assertions need an expected value, write \"\" for an empty one", a.Type)
+	}
+	a.Expected = unquote(value)
+
+	if err := a.Validate(); err != nil {
+		return models.Assertion{}, err
+	}
+
+	if a.Type == models.AssertDuration {
+		if _, err := parseDuration(a.Expected); err != nil {
+			return models.Assertion{}, err
+		}
+	}
+	if a.Operator == models.OpMatches {
+		if _, err := regexp.Compile(a.Expected); err != nil {
+			return models.Assertion{}, fmt.Errorf("invalid regular expression: %w", err)
+		}
+	}
+
+	return a, nil
+}
+
+// Evaluate checks every assertion against a response
+func Evaluate(assertions []models.Assertion, resp *executor.Response) []Result {
+	results := make([]Result, 0, len(assertions))
+	for _, a := range assertions {
+		results = append(results, evaluate(a, resp))
+	}
+	return results
+}
+
+func evaluate(a models.Assertion, resp *executor.Response) Result {
+	result := Result{Assertion: a}
+
+	if resp == nil || resp.Error != nil {
+		result.Message = "no response to check"
+		if resp != nil && a.Type == models.AssertDuration {
+			// A failed request can still be slow, but never passes a check
+			result.Message = fmt.Sprintf("request failed after %s", resp.Duration.Round(time.Millisecond))
+		}
+		return result
+	}
+
+	switch a.Type {
+	case models.AssertStatus:
+		result.Passed = matchStatus(a.Expected, resp.StatusCode)
+		result.Message = fmt.Sprintf("status is %d", resp.StatusCode)
+
+	case models.AssertHeader:
+		values, exists := resp.Headers[httpHeaderKey(resp, a.Property)]
+		if !exists {
+			result.Message = fmt.Sprintf("header %s is missing", a.Property)
+			return result
+		}
+		result.Passed, result.Message = compare(a, strings.Join(values, ", "))
+
+	case models.AssertJSONPath:
+		value, err := jsonpath.GetFromJSON(resp.Body, a.Property)
+		if err != nil {
+			result.Message = err.Error()
+			return result
+		}
+		result.Passed, result.Message = compare(a, jsonpath.ValueString(value))
+
+	case models.AssertBody:
+		result.Passed, result.Message = compare(a, resp.Body)
+		if len(resp.Body) > 80 {
+			// Avoid echoing a whole body back into the message
+			if result.Passed {
+				result.Message = "body matched"
+			} else {
+				result.Message = "body did not match"
+			}
+		}
+
+	case models.AssertDuration:
+		limit, err := parseDuration(a.Expected)
+		if err != nil {
+			result.Message = err.Error()
+			return result
+		}
+		result.Passed = resp.Duration <= limit
+		result.Message = fmt.Sprintf("took %s", resp.Duration.Round(time.Millisecond))
+
+	default:
+		result.Message = fmt.Sprintf("unknown assertion type: %s", a.Type)
+	}
+
+	return result
+}
+
+// compare applies the operator of a string-valued assertion
+func compare(a models.Assertion, actual string) (bool, string) {
+	switch a.Operator {
+	case models.OpExists:
+		return true, "present"
+	case models.OpEquals:
+		return actual == a.Expected, fmt.Sprintf("got %q", actual)
+	case models.OpContains:
+		return strings.Contains(actual, a.Expected), fmt.Sprintf("got %q", actual)
+	case models.OpMatches:
+		re, err := regexp.Compile(a.Expected)
+		if err != nil {
+			return false, fmt.Sprintf("invalid regular expression: %s", err)
+		}
+		return re.MatchString(actual), fmt.Sprintf("got %q", actual)
+	}
+	return false, fmt.Sprintf("unsupported operator: %s", a.Operator)
+}
+
+// matchStatus compares a status code with an exact value or a class such as 2xx
+func matchStatus(expected string, status int) bool {
+	expected = strings.ToLower(strings.TrimSpace(expected))
+	if len(expected) == 3 && strings.HasSuffix(expected, "xx") {
+		return strconv.Itoa(status)[:1] == expected[:1]
+	}
+	code, err := strconv.Atoi(expected)
+	return err == nil && code == status
+}
+
+// parseDuration accepts Go durations ("1.5s", "500ms") or plain milliseconds ("500")
+func parseDuration(value string) (time.Duration, error) {
+	if ms, err := strconv.Atoi(value); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration '%s'", value)
+	}
+	return d, nil
+}
+
+// httpHeaderKey finds the stored key of a header regardless of its case
+func httpHeaderKey(resp *executor.Response, name string) string {
+	for key := range resp.Headers {
+		if strings.EqualFold(key, name) {
+			return key
+		}
+	}
+	return name
+}
+
+func isOperator(word string) bool {
+	switch word {
+	case models.OpEquals, models.OpExists, models.OpContains, models.OpMatches, models.OpMax:
+		return true
+	}
+	return false
+}
+
+// nextWord splits off the first whitespace-separated word of text
+func nextWord(text string) (string, string) {
+	text = strings.TrimLeft(text, " \t")
+	end := strings.IndexAny(text, " \t")
+	if end == -1 {
+		return text, ""
+	}
+	return text[:end], text[end:]
+}
+
+// nextProperty is nextWord for a header name or JSON path, whose filters may hold spaces inside brackets and quotes
+func nextProperty(text string) (string, string) {
+	text = strings.TrimLeft(text, " \t")
+	depth := 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '(':
+			depth++
+		case c == ']' || c == ')':
+			depth--
+		case (c == ' ' || c == '\t') && depth <= 0:
+			return text[:i], text[i:]
+		}
+	}
+	return text, ""
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// FormatResults formats assertion results as a plain-text checklist
+func FormatResults(results []Result) string {
+	var s strings.Builder
+
+	passed := 0
+	for _, r := range results {
+		mark := "x"
+		if r.Passed {
+			mark = "+"
+			passed++
+		}
+		s.WriteString(fmt.Sprintf("  %s %s (%s)\n", mark, r.Assertion, r.Message))
+	}
+
+	return fmt.Sprintf("Tests: %d/%d passed\n", passed, len(results)) + s.String()
+}
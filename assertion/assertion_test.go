@@ -0,0 +1,66 @@
+package assertion
+
+import (
+	"github.com/leobrines/curlman/models"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want models.Assertion
+	}{
+		{"status 200", models.Assertion{Type: models.AssertStatus, Operator: models.OpEquals, Expected: "200"}},
+		{"body equals \"\"", models.Assertion{Type: models.AssertBody, Operator: models.OpEquals}},
+		{"header X-Debug equals ''", models.Assertion{Type: models.AssertHeader, Property: "X-Debug", Operator: models.OpEquals}},
+		{"json $.name equals \"Ann Lee\"", models.Assertion{Type: models.AssertJSONPath, Property: "$.name", Operator: models.OpEquals, Expected: "Ann Lee"}},
+		{"body contains \" ok \"", models.Assertion{Type: models.AssertBody, Operator: models.OpContains, Expected: " ok "}},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.text)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, text := range []string{
+		"status",
+		"status equals \"\"",
+		"header X-Debug equals",
+		"json $.id equals",
+		"duration max",
+		"body exists",
+	} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", text)
+		}
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	for _, a := range []models.Assertion{
+		{Type: models.AssertBody, Operator: models.OpEquals},
+		{Type: models.AssertHeader, Property: "X-Debug", Operator: models.OpEquals},
+		{Type: models.AssertBody, Operator: models.OpContains, Expected: " padded "},
+		{Type: models.AssertJSONPath, Property: "$.quote", Operator: models.OpEquals, Expected: "\"quoted\""},
+		{Type: models.AssertJSONPath, Property: "$.name", Operator: models.OpEquals, Expected: "Ann Lee"},
+		{Type: models.AssertHeader, Property: "ETag", Operator: models.OpExists},
+		{Type: models.AssertDuration, Operator: models.OpMax, Expected: "500ms"},
+	} {
+		got, err := Parse(a.String())
+		if err != nil {
+			t.Errorf("Parse(%q): %v", a.String(), err)
+			continue
+		}
+		if got != a {
+			t.Errorf("Parse(%q) = %+v, want %+v", a.String(), got, a)
+		}
+	}
+}
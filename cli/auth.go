@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"github.com/leobrines/curlman/oauth2"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+)
+
+func authCommand(app *App, args []string) error {
+	const usage = "auth [-clear] [-no-browser] <collection> <request>"
+
+	fs := flag.NewFlagSet("auth", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	clearToken := fs.Bool("clear", false, "forget the cached token instead of fetching one")
+	noBrowser := fs.Bool("no-browser", false, "print the authorization URL without opening a browser")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 2 {
+		return usageError(usage)
+	}
+
+	collection, err := app.loadCollection(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	_, request, err := app.requestService.FindRequest(collection, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	allVars, secretErr := app.variableService.GetAllVariables(collection)
+	if _, err := app.variableService.FindUnresolvedVariables(collection.Resolve(request), allVars, secretErr); err != nil {
+		return err
+	}
+
+	if *clearToken {
+		if err := app.authService.ClearOAuth2Token(collection, request, allVars); err != nil {
+			return err
+		}
+		fmt.Fprintln(app.stdout, "Token cleared")
+		return nil
+	}
+
+	open := func(authURL string) {
+		fmt.Fprintf(app.stderr, "Open this URL to authorize:\n%s\n", authURL)
+		if !*noBrowser {
+			if err := oauth2.OpenBrowser(authURL); err != nil {
+				fmt.Fprintf(app.stderr, "Warning: failed to open a browser: %s\n", err)
+			}
+		}
+	}
+
+	token, err := app.authService.AuthorizeOAuth2(context.Background(), collection, request, allVars, open)
+	if err != nil {
+		return err
+	}
+
+	if token.ExpiresAt.IsZero() {
+		fmt.Fprintln(app.stdout, "Token acquired (no expiry)")
+	} else {
+		fmt.Fprintf(app.stdout, "Token acquired, expires at %s\n", token.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"github.com/leobrines/curlman/config"
+	"github.com/leobrines/curlman/models"
+	"github.com/leobrines/curlman/services"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// App bundles the services used by the headless commands
+type App struct {
+	stdout io.Writer
+	stderr io.Writer
+
+	collectionService  *services.CollectionService
+	requestService     *services.RequestService
+	variableService    *services.VariableService
+	environmentService *services.EnvironmentService
+	runnerService      *services.RunnerService
+	extractionService  *services.ExtractionService
+	historyService     *services.HistoryService
+	authService        *services.AuthService
+	cookieService      *services.CookieService
+}
+
+// command is a single CLI subcommand
+type command struct {
+	name    string
+	usage   string
+	summary string
+	run     func(app *App, args []string) error
+}
+
+// exitError carries a specific exit code out of a command
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func commands() []command {
+	return []command{
+		{"run", "run [-fail] [-strict] [-summary] [-save] [-folder path] [-filter expr] [-output file] [-json] [-reveal] <collection> [request...]", "Execute one request, or run a folder, several or all requests with a summary", runCommand},
+		{"export", "export [-format curl|json|postman|postman-env] [-strict] [-reveal] <collection> [request|environment]", "Print requests as curl, or a collection or environment as JSON or Postman", exportCommand},
+		{"import", "import [-format auto|openapi|postman|insomnia|har|postman-env|curl] [-collection name] <file|->", "Import an OpenAPI, Postman, Insomnia or HAR file as a collection, or a Postman environment or curl command into a collection", importCommand},
+		{"env", "env list|use|clear ...", "List, activate or clear environments of a collection", envCommand},
+		{"vars", "vars [-reveal] <collection>", "List the effective variables with the layer each value comes from, the values it overrides and the requests using it", varsCommand},
+		{"auth", "auth [-clear] [-no-browser] <collection> <request>", "Fetch an OAuth2 token for a request (authorizing in a browser for the authorization code grant), or clear it", authCommand},
+	}
+}
+
+// Run executes the subcommand described by args and returns the process exit code
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(stdout)
+		return 0
+	}
+
+	// Running with the defaults would hide the global variables the commands rely on
+	globalConfig, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %s\n", err)
+		return 1
+	}
+
+	collectionService := services.NewCollectionService()
+	requestService := services.NewRequestService()
+	variableService := services.NewVariableService(globalConfig)
+	environmentService := services.NewEnvironmentService()
+	extractionService := services.NewExtractionService(variableService, environmentService)
+	historyService := services.NewHistoryService(requestService, variableService, collectionService, environmentService)
+	cookieService := services.NewCookieService()
+
+	app := &App{
+		stdout:             stdout,
+		stderr:             stderr,
+		collectionService:  collectionService,
+		requestService:     requestService,
+		variableService:    variableService,
+		environmentService: environmentService,
+		runnerService:      services.NewRunnerService(requestService, variableService, extractionService, historyService, cookieService),
+		extractionService:  extractionService,
+		historyService:     historyService,
+		authService:        services.NewAuthService(),
+		cookieService:      cookieService,
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name != args[0] {
+			continue
+		}
+		if err := cmd.run(app, args[1:]); err != nil {
+			fmt.Fprintf(stderr, "Error: %s\n", err)
+			if exitErr, ok := err.(*exitError); ok {
+				return exitErr.code
+			}
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(stderr, "Unknown command: %s\n\n", args[0])
+	printUsage(stderr)
+	return 2
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage:")
+	fmt.Fprintln(w, "  curlman                 Start the interactive TUI")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  curlman %s\n", cmd.usage)
+		fmt.Fprintf(w, "      %s\n", cmd.summary)
+	}
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Collections are referenced by their file name in ~/.curlman (the .json suffix is optional).")
+}
+
+// loadCollection loads a collection and restores its active environments
+func (app *App) loadCollection(name string) (*models.Collection, error) {
+	collection, err := app.collectionService.LoadCollection(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := app.environmentService.RestoreActiveEnvironments(collection); err != nil {
+		fmt.Fprintf(app.stderr, "Warning: %s\n", err)
+	}
+
+	return collection, nil
+}
+
+// saveCollection persists a collection back to the file it was loaded from
+func (app *App) saveCollection(collection *models.Collection, name string) error {
+	_, err := app.collectionService.SaveCollection(collection, name)
+	return err
+}
+
+// checkUnresolved warns about the undefined variables of a request, which keep their {{placeholders}},
+// or fails on them when strict
+func (app *App) checkUnresolved(request *models.Request, variables map[string]string, secretErr error, strict bool) error {
+	unresolved, err := app.variableService.FindUnresolvedVariables(request, variables, secretErr)
+	if err != nil || len(unresolved) == 0 {
+		return err
+	}
+	if strict {
+		return &services.UnresolvedVariablesError{Names: unresolved}
+	}
+	fmt.Fprintf(app.stderr, "Warning: request '%s' uses undefined variables, kept as {{placeholders}}: %s\n",
+		request.Name, strings.Join(unresolved, ", "))
+	return nil
+}
+
+// usageError builds an error that points the user at the command usage
+func usageError(usage string) error {
+	return &exitError{code: 2, err: fmt.Errorf("usage: curlman %s", strings.TrimSpace(usage))}
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+func envCommand(app *App, args []string) error {
+	const usage = "env list <collection> | env use [-global] <collection> <environment> | env clear <collection>"
+
+	if len(args) == 0 {
+		return usageError(usage)
+	}
+
+	switch args[0] {
+	case "list":
+		if len(args) != 2 {
+			return usageError(usage)
+		}
+		collection, err := app.loadCollection(args[1])
+		if err != nil {
+			return err
+		}
+
+		globalEnvs, err := app.environmentService.ListGlobalEnvironments()
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintln(app.stdout, "Global environments:")
+		for _, name := range globalEnvs {
+			fmt.Fprintln(app.stdout, formatEnvName(name, collection.ActiveEnvironment))
+		}
+		fmt.Fprintln(app.stdout, "Collection environments:")
+		for _, name := range app.environmentService.ListCollectionEnvironments(collection) {
+			fmt.Fprintln(app.stdout, formatEnvName(name, collection.ActiveCollectionEnv))
+		}
+		return nil
+
+	case "use":
+		fs := flag.NewFlagSet("env use", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		global := fs.Bool("global", false, "activate a global environment instead of a collection environment")
+		if err := fs.Parse(args[1:]); err != nil || fs.NArg() != 2 {
+			return usageError(usage)
+		}
+
+		collection, err := app.loadCollection(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		envName := fs.Arg(1)
+		if *global {
+			err = app.environmentService.ActivateGlobalEnvironment(collection, envName)
+		} else {
+			err = app.environmentService.ActivateCollectionEnvironment(collection, envName)
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := app.saveCollection(collection, fs.Arg(0)); err != nil {
+			return err
+		}
+		fmt.Fprintf(app.stdout, "Environment '%s' activated for %s\n", envName, collection.Name)
+		return nil
+
+	case "clear":
+		if len(args) != 2 {
+			return usageError(usage)
+		}
+		collection, err := app.loadCollection(args[1])
+		if err != nil {
+			return err
+		}
+
+		app.environmentService.DeactivateGlobalEnvironment(collection)
+		app.environmentService.DeactivateCollectionEnvironment(collection)
+
+		if err := app.saveCollection(collection, args[1]); err != nil {
+			return err
+		}
+		fmt.Fprintf(app.stdout, "Environments cleared for %s\n", collection.Name)
+		return nil
+	}
+
+	return usageError(usage)
+}
+
+func formatEnvName(name, active string) string {
+	if name == active {
+		return fmt.Sprintf("  %s (active)", name)
+	}
+	return "  " + name
+}
@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"github.com/leobrines/curlman/models"
+	"flag"
+	"fmt"
+	"io"
+)
+
+func exportCommand(app *App, args []string) error {
+	const usage = "export [-format curl|json|postman|postman-env] [-strict] [-reveal] <collection> [request|environment]"
+
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	format := fs.String("format", "curl", "output format: curl, json, postman or postman-env")
+	reveal := fs.Bool("reveal", false, "show the values of secret variables in curl commands instead of masking them")
+	strict := fs.Bool("strict", false, "fail on requests using undefined variables instead of exporting them as written")
+	if err := fs.Parse(args); err != nil || fs.NArg() < 1 || fs.NArg() > 2 {
+		return usageError(usage)
+	}
+
+	collection, err := app.loadCollection(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "curl":
+		allVars, secretErr := app.variableService.GetAllVariables(collection)
+
+		// A single request when named, otherwise every request in the collection
+		requests := collection.Requests
+		if fs.NArg() == 2 {
+			_, request, err := app.requestService.FindRequest(collection, fs.Arg(1))
+			if err != nil {
+				return err
+			}
+			requests = []*models.Request{request}
+		}
+
+		for _, request := range requests {
+			resolved := collection.Resolve(request)
+			if err := app.checkUnresolved(resolved, allVars, secretErr, *strict); err != nil {
+				return fmt.Errorf("request '%s': %w", request.Name, err)
+			}
+			curlCmd, err := app.requestService.ExportToCurl(resolved, allVars, !*strict)
+			if err != nil {
+				return fmt.Errorf("request '%s': %w", request.Name, err)
+			}
+			if !*reveal {
+				curlCmd = app.variableService.Mask(collection, curlCmd)
+			}
+			if len(requests) > 1 {
+				fmt.Fprintf(app.stdout, "# %s\n", request.Name)
+			}
+			fmt.Fprintln(app.stdout, curlCmd)
+		}
+	case "json":
+		if fs.NArg() == 2 {
+			return fmt.Errorf("json export works on whole collections only")
+		}
+		data, err := collection.ToJSON()
+		if err != nil {
+			return fmt.Errorf("failed to serialize collection: %w", err)
+		}
+		fmt.Fprintln(app.stdout, data)
+	case "postman":
+		if fs.NArg() == 2 {
+			return fmt.Errorf("postman export works on whole collections only")
+		}
+		data, err := app.collectionService.ExportToPostman(collection)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(app.stdout, data)
+	case "postman-env":
+		if fs.NArg() != 2 {
+			return fmt.Errorf("postman-env export needs an environment name")
+		}
+		data, err := app.environmentService.ExportPostmanEnvironment(collection, fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(app.stdout, data)
+	default:
+		return fmt.Errorf("unknown export format: %s", *format)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"github.com/leobrines/curlman/models"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+func importCommand(app *App, args []string) error {
+	const usage = "import [-format auto|openapi|postman|insomnia|har|postman-env|curl] [-collection name] <file|->"
+
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	format := fs.String("format", "auto", "input format: auto, openapi, postman, insomnia, har, postman-env or curl")
+	collectionName := fs.String("collection", "", "collection receiving imported requests or environments (curl, postman-env)")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return usageError(usage)
+	}
+
+	if *format == "auto" {
+		if fs.Arg(0) == "-" {
+			return fmt.Errorf("set -format when reading from stdin")
+		}
+		detected, err := app.collectionService.DetectImportFormat(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		*format = detected
+	}
+
+	switch *format {
+	case "openapi", "postman", "insomnia", "har":
+		importers := map[string]func(string) (*models.Collection, string, error){
+			"openapi":  app.collectionService.ImportFromOpenAPI,
+			"postman":  app.collectionService.ImportFromPostman,
+			"insomnia": app.collectionService.ImportFromInsomnia,
+			"har":      app.collectionService.ImportFromHAR,
+		}
+		collection, savedPath, err := importers[*format](fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(app.stdout, "Imported %d requests from %s (saved to %s)\n", len(collection.Requests), collection.Name, savedPath)
+
+	case "postman-env":
+		if *collectionName == "" {
+			return fmt.Errorf("postman-env imports need -collection")
+		}
+		collection, err := app.loadCollection(*collectionName)
+		if err != nil {
+			return err
+		}
+		env, err := app.environmentService.ImportPostmanEnvironment(collection, fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		if err := app.saveCollection(collection, *collectionName); err != nil {
+			return err
+		}
+		fmt.Fprintf(app.stdout, "Imported environment '%s' with %d variables into %s\n", env.Name, len(env.Variables), collection.Name)
+
+	case "curl":
+		if *collectionName == "" {
+			return fmt.Errorf("curl imports need -collection")
+		}
+		command, err := readInput(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+
+		collection, err := app.loadCollection(*collectionName)
+		if err != nil {
+			return err
+		}
+		request, err := app.requestService.ImportFromCurl(collection, command)
+		if err != nil {
+			return err
+		}
+		if err := app.saveCollection(collection, *collectionName); err != nil {
+			return err
+		}
+		fmt.Fprintf(app.stdout, "Imported request '%s' into %s\n", request.Name, collection.Name)
+
+	default:
+		return fmt.Errorf("unknown import format: %s", *format)
+	}
+
+	return nil
+}
+
+// readInput reads a file, or standard input when path is "-"
+func readInput(path string) (string, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"github.com/leobrines/curlman/assertion"
+	"github.com/leobrines/curlman/executor"
+	"github.com/leobrines/curlman/extract"
+	"github.com/leobrines/curlman/services"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+)
+
+func runCommand(app *App, args []string) error {
+	const usage = "run [-fail] [-strict] [-summary] [-save] [-folder path] [-filter expr] [-output file] [-json] [-reveal] <collection> [request...]"
+
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fail := fs.Bool("fail", false, "exit with status 22 when the response status is 400 or above")
+	strict := fs.Bool("strict", false, "fail requests using undefined variables instead of sending them as written")
+	summary := fs.Bool("summary", false, "print a run summary even for a single request")
+	save := fs.Bool("save", false, "save variables extracted from responses back to the collection")
+	folder := fs.String("folder", "", "run every request of a folder and its subfolders")
+	filter := fs.String("filter", "", "print only the part of the JSON body matching a JSONPath or jq-style expression")
+	output := fs.String("output", "", "stream the response body to a file, showing progress on stderr")
+	jsonOutput := fs.Bool("json", false, "print the response as JSON, with its timing breakdown")
+	reveal := fs.Bool("reveal", false, "show the values of secret variables in the output instead of masking them")
+	if err := fs.Parse(args); err != nil || fs.NArg() < 1 || (*folder != "" && fs.NArg() > 1) {
+		return usageError(usage)
+	}
+	if *filter != "" && (*output != "" || *jsonOutput) {
+		return fmt.Errorf("-filter cannot be combined with -output or -json")
+	}
+
+	collection, err := app.loadCollection(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	mask := func(text string) string {
+		if *reveal {
+			return text
+		}
+		return app.variableService.Mask(collection, text)
+	}
+
+	// Zero or several requests run as a batch with a summary report
+	names := fs.Args()[1:]
+	if len(names) != 1 || *summary {
+		if *filter != "" || *output != "" || *jsonOutput {
+			return fmt.Errorf("-filter, -output and -json apply to a single request")
+		}
+		var report *services.RunReport
+		if *folder != "" {
+			report, err = app.runnerService.RunFolder(context.Background(), collection, *folder, *strict)
+		} else {
+			report, err = app.runnerService.RunCollection(context.Background(), collection, names, *strict)
+		}
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprint(app.stdout, mask(services.FormatRunReport(report)))
+
+		if *save {
+			if err := app.saveCollection(collection, fs.Arg(0)); err != nil {
+				return err
+			}
+		}
+
+		if report.FailedCount() > 0 {
+			return &exitError{code: 1, err: fmt.Errorf("%d of %d requests failed", report.FailedCount(), len(report.Results))}
+		}
+		return nil
+	}
+
+	_, request, err := app.requestService.FindRequest(collection, names[0])
+	if err != nil {
+		return err
+	}
+
+	allVars, secretErr := app.variableService.GetAllVariables(collection)
+	resolved := collection.Resolve(request)
+	if err := app.checkUnresolved(resolved, allVars, secretErr, *strict); err != nil {
+		return err
+	}
+	jar, err := app.cookieService.Jar(collection)
+	if err != nil {
+		return err
+	}
+	var response *executor.Response
+	if *output != "" {
+		progress := &executor.Progress{}
+		stop := showProgress(app.stderr, progress)
+		response, err = app.requestService.DownloadRequest(context.Background(), resolved, allVars, jar, *output, progress, !*strict)
+		stop()
+	} else {
+		response, err = app.requestService.ExecuteRequest(context.Background(), resolved, allVars, jar, !*strict)
+	}
+	if err != nil && response == nil {
+		return err
+	}
+	if err != nil {
+		fmt.Fprintf(app.stderr, "Warning: %s\n", err)
+	}
+
+	if err := app.historyService.Record(collection, resolved, response); err != nil {
+		fmt.Fprintf(app.stderr, "Warning: %s\n", err)
+	}
+
+	if *jsonOutput {
+		data, err := executor.FormatResponseJSON(response)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(app.stdout, mask(data))
+	} else if response.Error != nil || *filter == "" {
+		fmt.Fprintln(app.stdout, mask(executor.FormatResponse(response)))
+	}
+	if response.Error != nil {
+		return &exitError{code: 1, err: errors.New(mask(response.Error.Error()))}
+	}
+
+	// With a filter or JSON output only JSON goes to stdout, so reports go to stderr
+	report := app.stdout
+	if *jsonOutput {
+		report = app.stderr
+	}
+	if *filter != "" {
+		filtered, err := executor.FilterBody(response, *filter)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(app.stdout, mask(filtered))
+		report = app.stderr
+	}
+
+	extracted := app.extractionService.ApplyExtractions(collection, request, response)
+	if len(extracted) > 0 {
+		fmt.Fprint(report, mask("\n"+extract.FormatResults(extracted)))
+		if *save {
+			if err := app.saveCollection(collection, fs.Arg(0)); err != nil {
+				return err
+			}
+		}
+	}
+
+	results := app.requestService.CheckAssertions(request, response, allVars)
+	if len(results) > 0 {
+		fmt.Fprint(report, mask("\n"+assertion.FormatResults(results)))
+		if !assertion.AllPassed(results) {
+			return &exitError{code: 1, err: fmt.Errorf("assertions failed")}
+		}
+	}
+	if *fail && response.StatusCode >= 400 {
+		return &exitError{code: 22, err: fmt.Errorf("server responded with %s", response.Status)}
+	}
+
+	return nil
+}
+
+// showProgress prints the bytes received by a download until stop is called
+func showProgress(w io.Writer, progress *executor.Progress) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	report := func() {
+		received := executor.FormatSize(progress.Received())
+		if total := progress.Total(); total > 0 {
+			received = fmt.Sprintf("%s / %s (%d%%)", received, executor.FormatSize(total), progress.Received()*100/total)
+		}
+		fmt.Fprintf(w, "\rDownloading... %s\033[K", received)
+	}
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(200 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			report()
+			select {
+			case <-done:
+				report()
+				fmt.Fprintln(w)
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
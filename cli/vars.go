@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"github.com/leobrines/curlman/services"
+	"flag"
+	"fmt"
+	"io"
+)
+
+func varsCommand(app *App, args []string) error {
+	const usage = "vars [-reveal] <collection>"
+
+	fs := flag.NewFlagSet("vars", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	reveal := fs.Bool("reveal", false, "show the values of secret variables instead of masking them")
+	if err := fs.Parse(args); err != nil || fs.NArg() != 1 {
+		return usageError(usage)
+	}
+
+	collection, err := app.loadCollection(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	variables, err := app.variableService.ResolveVariables(collection, *reveal)
+	if err != nil {
+		fmt.Fprintf(app.stderr, "Warning: %s\n", err)
+	}
+	fmt.Fprint(app.stdout, services.FormatResolvedVariables(variables))
+	return nil
+}
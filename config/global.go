@@ -9,7 +9,8 @@ import (
 
 // GlobalConfig represents global configuration settings
 type GlobalConfig struct {
-	Variables map[string]string `json:"variables"` // Global variables usable across all collections
+	Variables      map[string]string `json:"variables"`                 // Global variables usable across all collections
+	LastCollection string            `json:"last_collection,omitempty"` // File name of the last opened collection
 }
 
 // NewGlobalConfig creates a new global configuration with default values
@@ -100,3 +101,9 @@ func (gc *GlobalConfig) GetVariable(key string) (string, bool) {
 	value, exists := gc.Variables[key]
 	return value, exists
 }
+
+// SetLastCollection remembers the last opened collection and persists the config
+func (gc *GlobalConfig) SetLastCollection(fileName string) error {
+	gc.LastCollection = fileName
+	return gc.Save()
+}
@@ -0,0 +1,460 @@
+package cookies
+
+import (
+	"github.com/leobrines/curlman/models"
+	"github.com/leobrines/curlman/storage"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// DefaultScope names the jar used when no environment is active
+const DefaultScope = "default"
+
+// Cookie is a cookie stored in a jar
+type Cookie struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`
+	Path     string    `json:"path"`
+	Expires  time.Time `json:"expires,omitzero"` // Zero for session cookies, which are kept until cleared
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"http_only,omitempty"`
+	HostOnly bool      `json:"host_only,omitempty"` // Sent to Domain only, not to its subdomains
+}
+
+// Expired reports whether the cookie expired at the given time
+func (c *Cookie) Expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+// String renders the cookie in the Set-Cookie syntax read by Parse
+func (c *Cookie) String() string {
+	parts := []string{c.Name + "=" + c.Value, "Domain=" + c.Domain, "Path=" + c.Path}
+	if !c.Expires.IsZero() {
+		parts = append(parts, "Expires="+c.Expires.UTC().Format(http.TimeFormat))
+	}
+	if c.Secure {
+		parts = append(parts, "Secure")
+	}
+	if c.HttpOnly {
+		parts = append(parts, "HttpOnly")
+	}
+	if c.HostOnly {
+		parts = append(parts, "HostOnly")
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Parse reads a cookie written like a Set-Cookie header, which must name its domain:
+//
+//	name=value; Domain=example.com[; Path=/][; Expires=...|Max-Age=...][; Secure][; HttpOnly][; HostOnly]
+//
+// A cookie for an IP address, a single-label host or a public suffix is sent to that host only
+func Parse(text string) (*Cookie, error) {
+	parsed, err := http.ParseSetCookie(strings.TrimSpace(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid cookie: %w", err)
+	}
+
+	cookie := &Cookie{
+		Name:     parsed.Name,
+		Value:    parsed.Value,
+		Domain:   strings.ToLower(strings.TrimPrefix(parsed.Domain, ".")),
+		Path:     parsed.Path,
+		Secure:   parsed.Secure,
+		HttpOnly: parsed.HttpOnly,
+	}
+	if cookie.Domain == "" {
+		return nil, fmt.Errorf("cookie needs a Domain")
+	}
+	if cookie.Path == "" || cookie.Path[0] != '/' {
+		cookie.Path = "/"
+	}
+
+	switch {
+	case parsed.MaxAge > 0:
+		cookie.Expires = time.Now().Add(time.Duration(parsed.MaxAge) * time.Second)
+	case parsed.MaxAge < 0:
+		cookie.Expires = time.Unix(0, 0)
+	case !parsed.Expires.IsZero():
+		cookie.Expires = parsed.Expires
+	}
+
+	for _, attr := range parsed.Unparsed {
+		if strings.EqualFold(strings.TrimSpace(attr), "HostOnly") {
+			cookie.HostOnly = true
+		}
+	}
+	if !sharedDomain(cookie.Domain) {
+		cookie.HostOnly = true
+	}
+
+	return cookie, nil
+}
+
+// Jar is a persistent cookie jar, usable as the http.CookieJar of a client
+type Jar struct {
+	mu      sync.Mutex
+	path    string
+	cookies []*Cookie
+}
+
+// Scope names the jar of a collection and its active environments
+func Scope(collection *models.Collection) string {
+	var envs []string
+	if collection.ActiveCollectionEnv != "" {
+		envs = append(envs, collection.ActiveCollectionEnv)
+	}
+	if collection.ActiveEnvironment != "" {
+		envs = append(envs, collection.ActiveEnvironment)
+	}
+	if len(envs) == 0 {
+		return DefaultScope
+	}
+	return strings.Join(envs, "+")
+}
+
+// jarDir returns the directory holding the jars of a collection file
+func jarDir(dir, collectionFile string) string {
+	return filepath.Join(dir, fileName(strings.TrimSuffix(collectionFile, ".json")))
+}
+
+// GetCookiesDir returns the directory holding the cookie jars, creating it if needed
+func GetCookiesDir() (string, error) {
+	storageDir, err := storage.GetStorageDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get storage directory: %w", err)
+	}
+
+	dir := filepath.Join(storageDir, "cookies")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create cookies directory: %w", err)
+	}
+	return dir, nil
+}
+
+// memoryJars are the jars of collections without a file, by collection and scope, kept in memory only
+var (
+	memoryMu   sync.Mutex
+	memoryJars = make(map[memoryKey]*Jar)
+)
+
+type memoryKey struct {
+	collection *models.Collection
+	scope      string
+}
+
+// Load opens the jar of a collection and its active environments, kept with the collection file
+// A jar that was never saved is empty; a collection without a file, never saved or just imported,
+// has its own jar kept in memory until it is saved, so unsaved collections do not share one
+func Load(collection *models.Collection) (*Jar, error) {
+	if collection.File == "" {
+		memoryMu.Lock()
+		defer memoryMu.Unlock()
+		key := memoryKey{collection: collection, scope: Scope(collection)}
+		if memoryJars[key] == nil {
+			memoryJars[key] = &Jar{}
+		}
+		return memoryJars[key], nil
+	}
+
+	dir, err := GetCookiesDir()
+	if err != nil {
+		return nil, err
+	}
+
+	jar := &Jar{path: filepath.Join(jarDir(dir, collection.File), fileName(Scope(collection))+".json")}
+	data, err := os.ReadFile(jar.path)
+	if os.IsNotExist(err) {
+		return jar, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cookie jar: %w", err)
+	}
+	if err := json.Unmarshal(data, &jar.cookies); err != nil {
+		return nil, fmt.Errorf("failed to parse cookie jar: %w", err)
+	}
+	return jar, nil
+}
+
+// Rename moves the jars of a collection file to its new file name
+func Rename(fromFile, toFile string) error {
+	dir, err := GetCookiesDir()
+	if err != nil {
+		return err
+	}
+
+	from, to := jarDir(dir, fromFile), jarDir(dir, toFile)
+	if from == to {
+		return nil
+	}
+	if err := os.RemoveAll(to); err != nil {
+		return fmt.Errorf("failed to replace cookie jars: %w", err)
+	}
+	if err := os.Rename(from, to); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to move cookie jars: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the jars of a collection file
+func Delete(collectionFile string) error {
+	dir, err := GetCookiesDir()
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(jarDir(dir, collectionFile)); err != nil {
+		return fmt.Errorf("failed to delete cookie jars: %w", err)
+	}
+	return nil
+}
+
+// Persistent reports whether the jar is kept on disk, which needs its collection to have a file
+func (j *Jar) Persistent() bool {
+	return j.path != ""
+}
+
+// Save writes the jar to disk, dropping expired cookies; jars kept in memory only are not written
+func (j *Jar) Save() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.removeExpired(time.Now())
+	if j.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0700); err != nil {
+		return fmt.Errorf("failed to create cookie jar directory: %w", err)
+	}
+	data, err := json.MarshalIndent(j.cookies, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cookie jar: %w", err)
+	}
+	if err := os.WriteFile(j.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write cookie jar: %w", err)
+	}
+	return nil
+}
+
+// List returns the unexpired cookies sorted by domain, path and name
+func (j *Jar) List() []*Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.removeExpired(time.Now())
+	list := append([]*Cookie(nil), j.cookies...)
+	sort.SliceStable(list, func(a, b int) bool {
+		if list[a].Domain != list[b].Domain {
+			return list[a].Domain < list[b].Domain
+		}
+		if list[a].Path != list[b].Path {
+			return list[a].Path < list[b].Path
+		}
+		return list[a].Name < list[b].Name
+	})
+	return list
+}
+
+// Set stores a cookie, replacing the one with the same name, domain and path
+// An expired cookie only removes its previous value
+func (j *Jar) Set(cookie *Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.set(cookie, time.Now())
+}
+
+// Delete removes a cookie
+func (j *Jar) Delete(cookie *Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	for i, c := range j.cookies {
+		if c == cookie {
+			j.cookies = append(j.cookies[:i], j.cookies[i+1:]...)
+			return
+		}
+	}
+}
+
+// Clear removes every cookie
+func (j *Jar) Clear() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.cookies = nil
+}
+
+// SetCookies stores the cookies a response from u sets
+func (j *Jar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	host := canonicalHost(u)
+	for _, c := range cookies {
+		cookie := &Cookie{
+			Name:     c.Name,
+			Value:    c.Value,
+			Domain:   strings.ToLower(strings.TrimPrefix(c.Domain, ".")),
+			Path:     c.Path,
+			Secure:   c.Secure,
+			HttpOnly: c.HttpOnly,
+		}
+
+		// Without a Domain attribute the cookie goes back to the same host only
+		if cookie.Domain == "" {
+			cookie.Domain = host
+			cookie.HostOnly = true
+		} else if !domainMatch(host, cookie.Domain) {
+			continue
+		} else if !sharedDomain(cookie.Domain) {
+			// A public suffix or single label is not shared with other hosts
+			if host != cookie.Domain {
+				continue
+			}
+			cookie.HostOnly = true
+		}
+
+		if cookie.Path == "" || cookie.Path[0] != '/' {
+			cookie.Path = defaultPath(u.Path)
+		}
+
+		switch {
+		case c.MaxAge > 0:
+			cookie.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+		case c.MaxAge < 0:
+			cookie.Expires = now
+		case !c.Expires.IsZero():
+			cookie.Expires = c.Expires
+		}
+
+		j.set(cookie, now)
+	}
+}
+
+// Cookies returns the cookies to send in a request to u
+func (j *Jar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	j.removeExpired(time.Now())
+
+	host := canonicalHost(u)
+	path := u.Path
+	if path == "" {
+		path = "/"
+	}
+
+	var matched []*Cookie
+	for _, c := range j.cookies {
+		if c.HostOnly && host != c.Domain || !c.HostOnly && !domainMatch(host, c.Domain) {
+			continue
+		}
+		if !pathMatch(path, c.Path) || c.Secure && u.Scheme != "https" {
+			continue
+		}
+		matched = append(matched, c)
+	}
+
+	// Cookies with longer paths are sent first
+	sort.SliceStable(matched, func(a, b int) bool {
+		return len(matched[a].Path) > len(matched[b].Path)
+	})
+
+	cookies := make([]*http.Cookie, len(matched))
+	for i, c := range matched {
+		cookies[i] = &http.Cookie{Name: c.Name, Value: c.Value}
+	}
+	return cookies
+}
+
+func (j *Jar) set(cookie *Cookie, now time.Time) {
+	for i, c := range j.cookies {
+		if c.Name == cookie.Name && c.Domain == cookie.Domain && c.Path == cookie.Path {
+			j.cookies = append(j.cookies[:i], j.cookies[i+1:]...)
+			break
+		}
+	}
+	if !cookie.Expired(now) {
+		j.cookies = append(j.cookies, cookie)
+	}
+}
+
+func (j *Jar) removeExpired(now time.Time) {
+	kept := j.cookies[:0]
+	for _, c := range j.cookies {
+		if !c.Expired(now) {
+			kept = append(kept, c)
+		}
+	}
+	j.cookies = kept
+}
+
+func canonicalHost(u *url.URL) string {
+	return strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+}
+
+// sharedDomain reports whether a cookie domain may be sent to its subdomains,
+// which excludes IP addresses, single labels such as com or localhost, and public suffixes such as co.uk
+func sharedDomain(domain string) bool {
+	if net.ParseIP(domain) != nil || !strings.Contains(domain, ".") {
+		return false
+	}
+	suffix, _ := publicsuffix.PublicSuffix(domain)
+	return suffix != domain
+}
+
+// domainMatch reports whether host is domain or one of its subdomains
+func domainMatch(host, domain string) bool {
+	if host == domain {
+		return true
+	}
+	return net.ParseIP(host) == nil && strings.HasSuffix(host, "."+domain)
+}
+
+// pathMatch reports whether a cookie path applies to a request path
+func pathMatch(requestPath, cookiePath string) bool {
+	if requestPath == cookiePath {
+		return true
+	}
+	if !strings.HasPrefix(requestPath, cookiePath) {
+		return false
+	}
+	return strings.HasSuffix(cookiePath, "/") || requestPath[len(cookiePath)] == '/'
+}
+
+// defaultPath is the directory of the request path, the path of cookies that set none
+func defaultPath(requestPath string) string {
+	i := strings.LastIndex(requestPath, "/")
+	if i <= 0 {
+		return "/"
+	}
+	return requestPath[:i]
+}
+
+// fileName turns a collection or environment name into a safe file name
+func fileName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' || r == '+' {
+			return r
+		}
+		return '_'
+	}, name)
+	if safe == "" || strings.Trim(safe, ".") == "" {
+		return "_"
+	}
+	return safe
+}
@@ -0,0 +1,246 @@
+package cookies
+
+import (
+	"github.com/leobrines/curlman/models"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustURL(t *testing.T, raw string) *url.URL {
+	t.Helper()
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+// sent returns the cookies the jar sends to a URL as name=value pairs
+func sent(t *testing.T, jar *Jar, raw string) string {
+	t.Helper()
+	var pairs []string
+	for _, c := range jar.Cookies(mustURL(t, raw)) {
+		pairs = append(pairs, c.Name+"="+c.Value)
+	}
+	return strings.Join(pairs, "; ")
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		want Cookie
+	}{
+		{"a=1; Domain=.Example.com", Cookie{Name: "a", Value: "1", Domain: "example.com", Path: "/"}},
+		{"a=1; Domain=example.com; Path=/api; Secure; HttpOnly; HostOnly",
+			Cookie{Name: "a", Value: "1", Domain: "example.com", Path: "/api", Secure: true, HttpOnly: true, HostOnly: true}},
+		{"a=1; Domain=example.com; Path=relative", Cookie{Name: "a", Value: "1", Domain: "example.com", Path: "/"}},
+		{"a=1; Domain=example.com; Expires=Wed, 21 Oct 2099 07:28:00 GMT",
+			Cookie{Name: "a", Value: "1", Domain: "example.com", Path: "/", Expires: time.Date(2099, 10, 21, 7, 28, 0, 0, time.UTC)}},
+		{"a=1; Domain=localhost", Cookie{Name: "a", Value: "1", Domain: "localhost", Path: "/", HostOnly: true}},
+		{"a=1; Domain=127.0.0.1", Cookie{Name: "a", Value: "1", Domain: "127.0.0.1", Path: "/", HostOnly: true}},
+		{"a=1; Domain=co.uk", Cookie{Name: "a", Value: "1", Domain: "co.uk", Path: "/", HostOnly: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Parse(tt.text)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Parse(%q)\n got %+v\nwant %+v", tt.text, *got, tt.want)
+			}
+			again, err := Parse(got.String())
+			if err != nil || !reflect.DeepEqual(again, got) {
+				t.Errorf("Parse(%q) = %+v, %v, want the same cookie", got.String(), again, err)
+			}
+		})
+	}
+
+	for _, text := range []string{"", "a=1", "=1; Domain=example.com"} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", text)
+		}
+	}
+
+	expired, err := Parse("a=1; Domain=example.com; Max-Age=-1")
+	if err != nil || !expired.Expired(time.Now()) {
+		t.Errorf("Parse with a negative Max-Age = %+v, %v, want an expired cookie", expired, err)
+	}
+}
+
+func TestSetCookiesDomains(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		cookie http.Cookie
+		sentTo map[string]bool
+	}{
+		{"host only", "https://api.example.com/", http.Cookie{Name: "a", Value: "1"},
+			map[string]bool{"https://api.example.com/": true, "https://x.api.example.com/": false, "https://example.com/": false}},
+		{"parent domain", "https://api.example.com/", http.Cookie{Name: "a", Value: "1", Domain: ".Example.com"},
+			map[string]bool{"https://example.com/": true, "https://www.example.com/": true, "https://notexample.com/": false}},
+		{"foreign domain", "https://api.example.com/", http.Cookie{Name: "a", Value: "1", Domain: "other.com"},
+			map[string]bool{"https://other.com/": false, "https://api.example.com/": false}},
+		{"public suffix", "https://shop.example.co.uk/", http.Cookie{Name: "a", Value: "1", Domain: "co.uk"},
+			map[string]bool{"https://shop.example.co.uk/": false, "https://evil.co.uk/": false}},
+		{"private public suffix", "https://me.github.io/", http.Cookie{Name: "a", Value: "1", Domain: "github.io"},
+			map[string]bool{"https://me.github.io/": false, "https://you.github.io/": false}},
+		{"top level domain", "https://example.com/", http.Cookie{Name: "a", Value: "1", Domain: "com"},
+			map[string]bool{"https://example.com/": false, "https://other.com/": false}},
+		{"single label host", "http://localhost:8080/", http.Cookie{Name: "a", Value: "1", Domain: "localhost"},
+			map[string]bool{"http://localhost:9090/": true, "http://api.localhost/": false}},
+		{"ip address", "http://127.0.0.1/", http.Cookie{Name: "a", Value: "1", Domain: "127.0.0.1"},
+			map[string]bool{"http://127.0.0.1/": true}},
+		{"secure", "https://example.com/", http.Cookie{Name: "a", Value: "1", Secure: true},
+			map[string]bool{"https://example.com/": true, "http://example.com/": false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jar := &Jar{}
+			jar.SetCookies(mustURL(t, tt.from), []*http.Cookie{&tt.cookie})
+			for target, want := range tt.sentTo {
+				if got := sent(t, jar, target) == "a=1"; got != want {
+					t.Errorf("cookie sent to %s = %v, want %v", target, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCookiesPaths(t *testing.T) {
+	jar := &Jar{}
+	u := mustURL(t, "https://example.com/api/users/7")
+	jar.SetCookies(u, []*http.Cookie{
+		{Name: "root", Value: "1", Path: "/"},
+		{Name: "api", Value: "2", Path: "/api"},
+		{Name: "default", Value: "3"},
+		{Name: "gone", Value: "4", MaxAge: -1},
+	})
+
+	tests := map[string]string{
+		"https://example.com/":              "root=1",
+		"https://example.com/api":           "api=2; root=1",
+		"https://example.com/apiary":        "root=1",
+		"https://example.com/api/users/8":   "default=3; api=2; root=1",
+		"https://example.com/api/users":     "default=3; api=2; root=1",
+		"https://example.com/api/usersx/1":  "api=2; root=1",
+		"https://other.example.com/api/x/y": "",
+	}
+	for target, want := range tests {
+		if got := sent(t, jar, target); got != want {
+			t.Errorf("cookies sent to %s = %q, want %q", target, got, want)
+		}
+	}
+
+	// A cookie with the same name, domain and path replaces the previous one, and an expired one removes it
+	jar.SetCookies(u, []*http.Cookie{{Name: "root", Value: "new", Path: "/"}})
+	jar.SetCookies(u, []*http.Cookie{{Name: "api", Path: "/api", Expires: time.Unix(1, 0)}})
+	if got := sent(t, jar, "https://example.com/api"); got != "root=new" {
+		t.Errorf("cookies after replacing = %q, want %q", got, "root=new")
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	collection := &models.Collection{Name: "Shop", File: "shop.json", ActiveEnvironment: "staging"}
+	jar, err := Load(collection)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(jar.List()) != 0 {
+		t.Fatalf("new jar has cookies")
+	}
+	jar.SetCookies(mustURL(t, "https://example.com/"), []*http.Cookie{
+		{Name: "session", Value: "s1"},
+		{Name: "short", Value: "x", Expires: time.Now().Add(time.Hour)},
+	})
+	if err := jar.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	info, err := os.Stat(jar.path)
+	if err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("jar file mode = %v, %v, want 0600", info, err)
+	}
+
+	// The jar belongs to the collection file, whatever the collection is called
+	collection.Name = "Renamed"
+	loaded, err := Load(collection)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := sent(t, loaded, "https://example.com/"); got != "session=s1; short=x" {
+		t.Errorf("loaded jar sends %q", got)
+	}
+
+	// Each environment has its own jar
+	other, err := Load(&models.Collection{File: "shop.json"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(other.List()) != 0 {
+		t.Errorf("jar without environment shares the staging cookies")
+	}
+
+	if err := Rename("shop.json", "store.json"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	collection.File = "store.json"
+	moved, err := Load(collection)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(moved.List()) != 2 {
+		t.Errorf("renamed jar has %d cookies, want 2", len(moved.List()))
+	}
+	if stale, _ := Load(&models.Collection{File: "shop.json", ActiveEnvironment: "staging"}); len(stale.List()) != 0 {
+		t.Errorf("old collection file still has cookies")
+	}
+
+	if err := Delete("store.json"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if deleted, _ := Load(collection); len(deleted.List()) != 0 {
+		t.Errorf("deleted jar still has cookies")
+	}
+	if err := Rename("missing.json", "other.json"); err != nil {
+		t.Errorf("Rename without jars: %v", err)
+	}
+}
+
+func TestUnsavedCollectionJar(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	collection := &models.Collection{Name: "Imported"}
+	jar, err := Load(collection)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if jar.Persistent() {
+		t.Errorf("jar of an unsaved collection is persistent")
+	}
+	jar.SetCookies(mustURL(t, "https://example.com/"), []*http.Cookie{{Name: "session", Value: "s1"}})
+	if err := jar.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if got := sent(t, jar, "https://example.com/"); got != "session=s1" {
+		t.Errorf("jar sends %q, want its cookies kept in memory", got)
+	}
+	if again, _ := Load(collection); len(again.List()) != 1 {
+		t.Errorf("jar loaded again has %d cookies, want the one kept in memory", len(again.List()))
+	}
+
+	// Another unsaved collection does not get those cookies
+	other, err := Load(&models.Collection{Name: "Other"})
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(other.List()) != 0 {
+		t.Errorf("unsaved collections share a jar")
+	}
+}
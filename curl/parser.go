@@ -0,0 +1,416 @@
+package curl
+
+import (
+	"github.com/leobrines/curlman/models"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// valueFlags lists curl options that consume the next argument but have no effect on the request
+var valueFlags = map[string]bool{
+	"-o": true, "--output": true, "--connect-timeout": true,
+	"-w": true, "--write-out": true, "--retry": true, "-T": true, "--upload-file": true,
+	"-c": true, "--cookie-jar": true, "--resolve": true, "--limit-rate": true, "-r": true,
+	"--range": true, "-K": true, "--config": true,
+}
+
+// valueShortFlags lists the short options that take a value, attached (-XPOST) or as the next argument
+const valueShortFlags = "XHduAebFmxEowTcrK"
+
+// Parse converts a curl command line into a request
+func Parse(command string) (*models.Request, error) {
+	args, err := tokenize(command)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) > 0 && (args[0] == "curl" || strings.HasSuffix(args[0], "/curl")) {
+		args = args[1:]
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty curl command")
+	}
+
+	request := &models.Request{
+		ID:          uuid.New().String(),
+		Headers:     make(map[string]string),
+		QueryParams: make(map[string]string),
+	}
+
+	var (
+		rawURL       string
+		method       string
+		data         []string
+		encodeFields []models.FormField // --data-urlencode name=content pairs
+		formFields   []models.FormField
+		fileData     bool // Data read from a file with @path
+		getData      bool
+		digest       bool
+		head         bool
+	)
+
+	// Client settings are only attached when the command sets one
+	settings := func() *models.ClientSettings {
+		if request.Settings == nil {
+			request.Settings = &models.ClientSettings{}
+		}
+		return request.Settings
+	}
+	enabled := func() *bool {
+		b := true
+		return &b
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		// Fetch the value of an option, either attached (-XPOST, --data=x) or as the next argument
+		value := func() (string, error) {
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("option %s requires a value", arg)
+			}
+			i++
+			return args[i], nil
+		}
+
+		if len(arg) > 2 && arg[0] == '-' && arg[1] != '-' {
+			// Short options may be bundled (-sSL) and take their value attached (-XPOST, -sXPOST)
+			args = append(args[:i], append(splitShortFlags(arg), args[i+1:]...)...)
+			arg = args[i]
+		}
+
+		name := arg
+		if strings.HasPrefix(arg, "--") && strings.Contains(arg, "=") {
+			parts := strings.SplitN(arg, "=", 2)
+			name = parts[0]
+			args = append(args[:i+1], append([]string{parts[1]}, args[i+1:]...)...)
+		}
+
+		switch name {
+		case "-X", "--request":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			method = strings.ToUpper(v)
+		case "-H", "--header":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			key, val, ok := strings.Cut(v, ":")
+			if !ok {
+				return nil, fmt.Errorf("invalid header: %s", v)
+			}
+			request.Headers[strings.TrimSpace(key)] = strings.TrimSpace(val)
+		case "-d", "--data", "--data-raw", "--data-binary", "--data-ascii":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, v)
+			fileData = name != "--data-raw" && strings.HasPrefix(v, "@")
+		case "--data-urlencode":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, urlEncodeData(v))
+			if name, content, ok := strings.Cut(v, "="); ok && name != "" {
+				encodeFields = append(encodeFields, models.FormField{Key: name, Value: content, Type: models.FormFieldText})
+			}
+		case "-F", "--form", "--form-string":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			field, err := parseFormField(v, name == "--form-string")
+			if err != nil {
+				return nil, err
+			}
+			formFields = append(formFields, field)
+		case "-u", "--user":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			username, password, _ := strings.Cut(v, ":")
+			request.Auth = &models.Auth{Type: models.AuthBasic, Username: username, Password: password}
+		case "--digest":
+			digest = true
+		case "--oauth2-bearer":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			request.Auth = &models.Auth{Type: models.AuthBearer, Token: v}
+		case "-A", "--user-agent":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			request.Headers["User-Agent"] = v
+		case "-e", "--referer":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			request.Headers["Referer"] = v
+		case "-b", "--cookie":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			request.Headers["Cookie"] = v
+		case "--url":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			rawURL = v
+		case "-m", "--max-time":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			seconds, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid max time: %s", v)
+			}
+			settings().Timeout = (time.Duration(seconds * float64(time.Second))).String()
+		case "--max-redirs":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid max redirects: %s", v)
+			}
+			settings().MaxRedirects = n
+		case "-L", "--location":
+			settings().FollowRedirects = enabled()
+		case "-k", "--insecure":
+			settings().Insecure = enabled()
+		case "-x", "--proxy":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			settings().Proxy = v
+		case "--noproxy":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			settings().NoProxy = v
+		case "--cacert":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			settings().CACert = v
+		case "-E", "--cert":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			settings().ClientCert = v
+		case "--key":
+			v, err := value()
+			if err != nil {
+				return nil, err
+			}
+			settings().ClientKey = v
+		case "--http1.1":
+			settings().HTTPVersion = models.HTTPVersion11
+		case "--http2", "--http2-prior-knowledge":
+			settings().HTTPVersion = models.HTTPVersion2
+		case "-G", "--get":
+			getData = true
+		case "-I", "--head":
+			head = true
+		default:
+			if valueFlags[name] {
+				if _, err := value(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if strings.HasPrefix(arg, "-") && arg != "-" {
+				// Other flags without a value (-s, --compressed, ...) do not change the request
+				continue
+			}
+			if rawURL == "" {
+				rawURL = arg
+			}
+		}
+	}
+
+	if rawURL == "" {
+		return nil, fmt.Errorf("no URL found in curl command")
+	}
+
+	if err := splitURL(request, rawURL); err != nil {
+		return nil, err
+	}
+
+	body := strings.Join(data, "&")
+	switch {
+	case len(formFields) > 0:
+		request.BodyMode = models.BodyFormData
+		request.FormFields = formFields
+		if method == "" {
+			method = "POST"
+		}
+	case getData:
+		// -G moves the data into the query string
+		for _, pair := range data {
+			for _, param := range strings.Split(pair, "&") {
+				key, val, _ := strings.Cut(param, "=")
+				if key != "" {
+					request.QueryParams[key] = val
+				}
+			}
+		}
+		if method == "" {
+			method = "GET"
+		}
+	case len(data) > 0:
+		switch {
+		case len(encodeFields) == len(data):
+			request.BodyMode = models.BodyURLEncoded
+			request.FormFields = encodeFields
+		case len(data) == 1 && fileData:
+			// -d @file and --data-binary @file send the contents of a file
+			request.BodyMode = models.BodyBinary
+			request.BodyFile = strings.TrimPrefix(data[0], "@")
+		default:
+			request.Body = body
+		}
+		if method == "" {
+			method = "POST"
+		}
+		if !request.HasHeader("Content-Type") {
+			// curl sends -d data as a form unless told otherwise
+			request.Headers["Content-Type"] = "application/x-www-form-urlencoded"
+		}
+	}
+
+	if digest && request.Auth != nil && request.Auth.Type == models.AuthBasic {
+		request.Auth.Type = models.AuthDigest
+	}
+
+	if method == "" {
+		method = "GET"
+		if head {
+			method = "HEAD"
+		}
+	}
+	request.Method = method
+
+	// Name requests like the OpenAPI importer does when there is no summary
+	request.Name = fmt.Sprintf("%s %s", request.Method, request.Path)
+	if request.Path == "" {
+		request.Name = fmt.Sprintf("%s %s", request.Method, request.URL)
+	}
+
+	return request, nil
+}
+
+// splitShortFlags separates bundled short options like -sSLk into -s -S -L -k
+// The first option taking a value gets the rest of the argument as its value: -sXPOST is -s -X POST
+func splitShortFlags(arg string) []string {
+	var flags []string
+	for j := 1; j < len(arg); j++ {
+		flags = append(flags, "-"+arg[j:j+1])
+		if strings.IndexByte(valueShortFlags, arg[j]) >= 0 {
+			if j+1 < len(arg) {
+				flags = append(flags, arg[j+1:])
+			}
+			break
+		}
+	}
+	return flags
+}
+
+// splitURL fills URL, Path and QueryParams of the request from a full URL
+// Query values are kept as written so that FullURL reproduces them
+func splitURL(request *models.Request, rawURL string) error {
+	rawURL = strings.TrimSpace(rawURL)
+	if !strings.Contains(rawURL, "://") {
+		// curl assumes http when no scheme is given
+		rawURL = "http://" + rawURL
+	}
+
+	base, query, _ := strings.Cut(rawURL, "?")
+	base, _, _ = strings.Cut(base, "#")
+
+	schemeEnd := strings.Index(base, "://") + 3
+	pathStart := strings.Index(base[schemeEnd:], "/")
+	if pathStart == -1 {
+		request.URL = base
+	} else {
+		request.URL = base[:schemeEnd+pathStart]
+		request.Path = base[schemeEnd+pathStart:]
+		if request.Path == "/" {
+			request.Path = ""
+		}
+	}
+
+	if request.URL == "http://" || request.URL == "https://" {
+		return fmt.Errorf("invalid URL: %s", rawURL)
+	}
+
+	query, _, _ = strings.Cut(query, "#")
+	for _, param := range strings.Split(query, "&") {
+		if param == "" {
+			continue
+		}
+		key, val, _ := strings.Cut(param, "=")
+		request.QueryParams[key] = val
+	}
+
+	return nil
+}
+
+// parseFormField reads a -F value: "name=value", "name=@file[;type=...]" uploading a file,
+// or "name=<file" whose text is read from the file, as curl does
+func parseFormField(value string, literal bool) (models.FormField, error) {
+	name, content, ok := strings.Cut(value, "=")
+	if !ok || name == "" {
+		return models.FormField{}, fmt.Errorf("invalid form field: %s", value)
+	}
+	if literal || (!strings.HasPrefix(content, "@") && !strings.HasPrefix(content, "<")) {
+		return models.FormField{Key: name, Value: content, Type: models.FormFieldText}, nil
+	}
+
+	// Drop ;type= and ;filename= modifiers after the path
+	path, _, _ := strings.Cut(content[1:], ";")
+	if content[0] == '@' {
+		return models.FormField{Key: name, Value: path, Type: models.FormFieldFile}, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return models.FormField{}, fmt.Errorf("failed to read form field %s: %w", name, err)
+	}
+	return models.FormField{Key: name, Value: string(data), Type: models.FormFieldText}, nil
+}
+
+// urlEncodeData mirrors curl's --data-urlencode forms: "content", "=content" and "name=content"
+func urlEncodeData(value string) string {
+	name, content, found := strings.Cut(value, "=")
+	if !found {
+		return url.QueryEscape(value)
+	}
+	if name == "" {
+		return url.QueryEscape(content)
+	}
+	return name + "=" + url.QueryEscape(content)
+}
@@ -0,0 +1,349 @@
+package curl
+
+import (
+	"github.com/leobrines/curlman/models"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+	}{
+		{"blanks", "curl  -s\thttp://x", []string{"curl", "-s", "http://x"}},
+		{"single quotes", `curl -H 'X-A: "b" \c'`, []string{"curl", "-H", `X-A: "b" \c`}},
+		{"double quotes", `curl -d "a \"b\" \$c \x"`, []string{"curl", "-d", `a "b" $c \x`}},
+		{"ansi-c quotes", `curl --data-raw $'{"a":\n1}\'x'`, []string{"curl", "--data-raw", "{\"a\":\n1}'x"}},
+		{"adjacent quotes", `curl 'a'"b"c`, []string{"curl", "abc"}},
+		{"escaped blank", `curl a\ b`, []string{"curl", "a b"}},
+		{"empty quotes", `curl -d ''`, []string{"curl", "-d", ""}},
+		{"line continuation", "curl \\\n  -s \\\r\n  http://x", []string{"curl", "-s", "http://x"}},
+		{"pasted continuation", `curl \  -s \ http://x`, []string{"curl", "-s", "http://x"}},
+		{"line breaks", "curl\n-s\r\nhttp://x", []string{"curl", "-s", "http://x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tokenize(tt.command)
+			if err != nil {
+				t.Fatalf("tokenize(%q): %v", tt.command, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q) = %q, want %q", tt.command, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeErrors(t *testing.T) {
+	for _, command := range []string{`curl 'a`, `curl "a`, `curl $'a`, `curl $'a\`} {
+		if _, err := tokenize(command); err == nil {
+			t.Errorf("tokenize(%q) succeeded, want an error", command)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	enabled := true
+
+	tests := []struct {
+		name    string
+		command string
+		want    models.Request
+	}{
+		{
+			name:    "url only",
+			command: "curl https://api.example.com/users?page=2&q=a%20b#top",
+			want: models.Request{
+				Method: "GET", URL: "https://api.example.com", Path: "/users",
+				QueryParams: map[string]string{"page": "2", "q": "a%20b"},
+			},
+		},
+		{
+			name:    "url without scheme",
+			command: "curl api.example.com",
+			want:    models.Request{Method: "GET", URL: "http://api.example.com"},
+		},
+		{
+			name:    "url option",
+			command: "curl --url=https://x.io/a -s",
+			want:    models.Request{Method: "GET", URL: "https://x.io", Path: "/a"},
+		},
+		{
+			name:    "method",
+			command: "curl -X delete https://x.io/a",
+			want:    models.Request{Method: "DELETE", URL: "https://x.io", Path: "/a"},
+		},
+		{
+			name:    "attached method",
+			command: "curl -XPUT https://x.io",
+			want:    models.Request{Method: "PUT", URL: "https://x.io"},
+		},
+		{
+			name:    "long method with equals",
+			command: "curl --request=PATCH https://x.io",
+			want:    models.Request{Method: "PATCH", URL: "https://x.io"},
+		},
+		{
+			name:    "headers",
+			command: `curl -H 'Accept: application/json' --header "X-Id:  7 " -A agent -e https://ref https://x.io`,
+			want: models.Request{
+				Method: "GET", URL: "https://x.io",
+				Headers: map[string]string{"Accept": "application/json", "X-Id": "7", "User-Agent": "agent", "Referer": "https://ref"},
+			},
+		},
+		{
+			name:    "data posts a form",
+			command: "curl -d a=1 -d b=2 https://x.io",
+			want: models.Request{
+				Method: "POST", URL: "https://x.io", Body: "a=1&b=2",
+				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			},
+		},
+		{
+			name:    "raw data keeps content type",
+			command: `curl --data-raw '{"a":1}' -H 'content-type: application/json' https://x.io`,
+			want: models.Request{
+				Method: "POST", URL: "https://x.io", Body: `{"a":1}`,
+				Headers: map[string]string{"content-type": "application/json"},
+			},
+		},
+		{
+			name:    "data with explicit method",
+			command: "curl -X PUT --data-binary x https://x.io",
+			want: models.Request{
+				Method: "PUT", URL: "https://x.io", Body: "x",
+				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			},
+		},
+		{
+			name:    "data from file",
+			command: "curl --data-binary @body.json https://x.io",
+			want: models.Request{
+				Method: "POST", URL: "https://x.io", BodyMode: models.BodyBinary, BodyFile: "body.json",
+				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			},
+		},
+		{
+			name:    "raw data is never a file",
+			command: "curl --data-raw @body.json https://x.io",
+			want: models.Request{
+				Method: "POST", URL: "https://x.io", Body: "@body.json",
+				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			},
+		},
+		{
+			name:    "urlencoded fields",
+			command: "curl --data-urlencode 'q=a b' --data-urlencode n=1 https://x.io",
+			want: models.Request{
+				Method: "POST", URL: "https://x.io", BodyMode: models.BodyURLEncoded,
+				FormFields: []models.FormField{
+					{Key: "q", Value: "a b", Type: models.FormFieldText},
+					{Key: "n", Value: "1", Type: models.FormFieldText},
+				},
+				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			},
+		},
+		{
+			name:    "urlencoded mixed with data",
+			command: "curl -d a=1 --data-urlencode 'q=a b' https://x.io",
+			want: models.Request{
+				Method: "POST", URL: "https://x.io", Body: "a=1&q=a+b",
+				Headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			},
+		},
+		{
+			name:    "get moves data to the query",
+			command: "curl -G -d a=1 --data-urlencode 'q=a b' https://x.io",
+			want: models.Request{
+				Method: "GET", URL: "https://x.io",
+				QueryParams: map[string]string{"a": "1", "q": "a+b"},
+			},
+		},
+		{
+			name:    "multipart form",
+			command: "curl -F name=x -F 'file=@a.png;type=image/png' --form-string 'raw=@b' https://x.io",
+			want: models.Request{
+				Method: "POST", URL: "https://x.io", BodyMode: models.BodyFormData,
+				FormFields: []models.FormField{
+					{Key: "name", Value: "x", Type: models.FormFieldText},
+					{Key: "file", Value: "a.png", Type: models.FormFieldFile},
+					{Key: "raw", Value: "@b", Type: models.FormFieldText},
+				},
+			},
+		},
+		{
+			name:    "basic auth",
+			command: "curl -u user:p:ss https://x.io",
+			want: models.Request{
+				Method: "GET", URL: "https://x.io",
+				Auth: &models.Auth{Type: models.AuthBasic, Username: "user", Password: "p:ss"},
+			},
+		},
+		{
+			name:    "attached digest auth",
+			command: "curl --digest -uuser:pw https://x.io",
+			want: models.Request{
+				Method: "GET", URL: "https://x.io",
+				Auth: &models.Auth{Type: models.AuthDigest, Username: "user", Password: "pw"},
+			},
+		},
+		{
+			name:    "bearer token",
+			command: "curl --oauth2-bearer tok https://x.io",
+			want: models.Request{
+				Method: "GET", URL: "https://x.io",
+				Auth: &models.Auth{Type: models.AuthBearer, Token: "tok"},
+			},
+		},
+		{
+			name:    "head",
+			command: "curl -I https://x.io",
+			want:    models.Request{Method: "HEAD", URL: "https://x.io"},
+		},
+		{
+			name:    "bundled head",
+			command: "curl -sI https://x.io",
+			want:    models.Request{Method: "HEAD", URL: "https://x.io"},
+		},
+		{
+			name:    "bundled flags",
+			command: "curl -sSLk https://x.io",
+			want: models.Request{
+				Method: "GET", URL: "https://x.io",
+				Settings: &models.ClientSettings{FollowRedirects: &enabled, Insecure: &enabled},
+			},
+		},
+		{
+			name:    "bundled flags do not match letters of values",
+			command: "curl -sH 'X-Lock: k' https://x.io",
+			want: models.Request{
+				Method: "GET", URL: "https://x.io",
+				Headers: map[string]string{"X-Lock": "k"},
+			},
+		},
+		{
+			name:    "bundled flag with attached value",
+			command: "curl -sXPOST https://x.io",
+			want:    models.Request{Method: "POST", URL: "https://x.io"},
+		},
+		{
+			name:    "bundled flag with value argument",
+			command: "curl -sLo out.html https://x.io",
+			want: models.Request{
+				Method: "GET", URL: "https://x.io",
+				Settings: &models.ClientSettings{FollowRedirects: &enabled},
+			},
+		},
+		{
+			name:    "ignored options",
+			command: "curl --compressed -o out -w '%{http_code}' -v https://x.io",
+			want:    models.Request{Method: "GET", URL: "https://x.io"},
+		},
+		{
+			name:    "client settings",
+			command: "curl -m 2.5 --max-redirs 3 -x http://proxy:8080 --noproxy localhost --cacert ca.pem -E c.pem --key k.pem --http2 https://x.io",
+			want: models.Request{
+				Method: "GET", URL: "https://x.io",
+				Settings: &models.ClientSettings{
+					Timeout: "2.5s", MaxRedirects: 3, Proxy: "http://proxy:8080", NoProxy: "localhost",
+					CACert: "ca.pem", ClientCert: "c.pem", ClientKey: "k.pem", HTTPVersion: models.HTTPVersion2,
+				},
+			},
+		},
+		{
+			name:    "cookies",
+			command: "curl -b 'a=1; b=2' https://x.io",
+			want: models.Request{
+				Method: "GET", URL: "https://x.io",
+				Headers: map[string]string{"Cookie": "a=1; b=2"},
+			},
+		},
+		{
+			name: "copied from a browser",
+			command: "curl 'https://x.io/api?id=1' \\\n" +
+				"  -H 'accept: */*' \\\n" +
+				"  --data-raw $'{\"name\":\"it\\'s\"}' \\\n" +
+				"  --compressed",
+			want: models.Request{
+				Method: "POST", URL: "https://x.io", Path: "/api", Body: `{"name":"it's"}`,
+				QueryParams: map[string]string{"id": "1"},
+				Headers:     map[string]string{"accept": "*/*", "Content-Type": "application/x-www-form-urlencoded"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.command)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			want := tt.want
+			if want.Headers == nil {
+				want.Headers = map[string]string{}
+			}
+			if want.QueryParams == nil {
+				want.QueryParams = map[string]string{}
+			}
+			want.ID = got.ID
+			want.Name = got.Name
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("Parse(%q)\n got %+v\nwant %+v", tt.command, *got, want)
+			}
+		})
+	}
+}
+
+func TestParseName(t *testing.T) {
+	got, err := Parse("curl -X POST https://x.io/users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "POST /users" {
+		t.Errorf("Name = %q, want %q", got.Name, "POST /users")
+	}
+}
+
+func TestParseFormFieldFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "note.txt")
+	if err := os.WriteFile(path, []byte("hello from a file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Parse("curl -F 'note=<" + path + "' -F 'photo=@" + path + "' https://x.io")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.FormField{
+		{Key: "note", Value: "hello from a file", Type: models.FormFieldText},
+		{Key: "photo", Value: path, Type: models.FormFieldFile},
+	}
+	if !reflect.DeepEqual(got.FormFields, want) {
+		t.Errorf("FormFields = %+v, want %+v", got.FormFields, want)
+	}
+
+	if _, err := Parse("curl -F 'note=<" + filepath.Join(t.TempDir(), "missing.txt") + "' https://x.io"); err == nil {
+		t.Errorf("Parse of a missing <file succeeded, want an error")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, command := range []string{
+		"curl",
+		"curl -s",
+		"curl -H",
+		"curl -H nocolon https://x.io",
+		"curl -m soon https://x.io",
+		"curl -F novalue https://x.io",
+		"curl https://",
+		"curl 'https://x.io",
+	} {
+		if _, err := Parse(command); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", command)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package curl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenize splits a shell command line into arguments
+// It understands single quotes, double quotes, $'...' strings and line continuations
+func tokenize(command string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inToken bool
+	)
+
+	flush := func() {
+		if inToken {
+			args = append(args, current.String())
+			current.Reset()
+			inToken = false
+		}
+	}
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case r == '\\':
+			// A backslash before a line break (or the blank a pasted line break became) continues the line
+			if i+1 < len(runes) && (runes[i+1] == '\n' || runes[i+1] == '\r' || (!inToken && isBlank(runes[i+1]))) {
+				i++
+				if runes[i] == '\r' && i+1 < len(runes) && runes[i+1] == '\n' {
+					i++
+				}
+				continue
+			}
+			if i+1 < len(runes) {
+				i++
+				current.WriteRune(runes[i])
+				inToken = true
+			}
+
+		case r == '\'':
+			end := indexRune(runes, i+1, '\'')
+			if end == -1 {
+				return nil, fmt.Errorf("unterminated single quote")
+			}
+			current.WriteString(string(runes[i+1 : end]))
+			inToken = true
+			i = end
+
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '\'':
+			// ANSI-C quoting, as produced by browser "copy as cURL"
+			value, end, err := readANSIQuoted(runes, i+2)
+			if err != nil {
+				return nil, err
+			}
+			current.WriteString(value)
+			inToken = true
+			i = end
+
+		case r == '"':
+			i++
+			for ; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\"\\$`\n", runes[i+1]) {
+					i++
+					if runes[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteRune(runes[i])
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated double quote")
+			}
+			inToken = true
+
+		case isBlank(r) || r == '\n' || r == '\r':
+			flush()
+
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+	flush()
+
+	return args, nil
+}
+
+// readANSIQuoted reads a $'...' string starting after the opening quote
+func readANSIQuoted(runes []rune, start int) (string, int, error) {
+	var s strings.Builder
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case '\'':
+			return s.String(), i, nil
+		case '\\':
+			if i+1 >= len(runes) {
+				return "", 0, fmt.Errorf("unterminated $'' string")
+			}
+			i++
+			switch runes[i] {
+			case 'n':
+				s.WriteRune('\n')
+			case 't':
+				s.WriteRune('\t')
+			case 'r':
+				s.WriteRune('\r')
+			default:
+				s.WriteRune(runes[i])
+			}
+		default:
+			s.WriteRune(runes[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated $'' string")
+}
+
+func indexRune(runes []rune, start int, target rune) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == target {
+			return i
+		}
+	}
+	return -1
+}
+
+func isBlank(r rune) bool {
+	return r == ' ' || r == '\t'
+}
@@ -0,0 +1,121 @@
+package executor
+
+import (
+	"github.com/leobrines/curlman/models"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+// digestChallenge returns the parameters of the Digest challenge of a 401 response
+func digestChallenge(headers http.Header) (map[string]string, bool) {
+	for _, value := range headers.Values("WWW-Authenticate") {
+		scheme, params, _ := strings.Cut(strings.TrimSpace(value), " ")
+		if strings.EqualFold(scheme, "Digest") {
+			return parseAuthParams(params), true
+		}
+	}
+	return nil, false
+}
+
+// parseAuthParams reads comma separated key=value pairs whose values may be quoted
+func parseAuthParams(text string) map[string]string {
+	params := make(map[string]string)
+	for text != "" {
+		text = strings.TrimLeft(text, " ,")
+		key, rest, ok := strings.Cut(text, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimSpace(rest)
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+			value = strings.TrimSpace(value)
+		}
+
+		params[key] = value
+		text = rest
+	}
+	return params
+}
+
+// digestAuthorization computes the Authorization header answering a Digest challenge (RFC 7616)
+// Only the "auth" quality of protection is supported; MD5 and SHA-256, with their -sess variants
+func digestAuthorization(challenge map[string]string, auth *models.Auth, req *http.Request) string {
+	algorithm := challenge["algorithm"]
+	newHash := func() hash.Hash { return md5.New() }
+	if strings.HasPrefix(strings.ToUpper(algorithm), "SHA-256") {
+		newHash = sha256.New
+	}
+	digest := func(parts ...string) string {
+		h := newHash()
+		h.Write([]byte(strings.Join(parts, ":")))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+
+	realm, nonce := challenge["realm"], challenge["nonce"]
+	uri := req.URL.RequestURI()
+	cnonce := newCnonce()
+	nc := "00000001"
+
+	ha1 := digest(auth.Username, realm, auth.Password)
+	if strings.HasSuffix(strings.ToLower(algorithm), "-sess") {
+		ha1 = digest(ha1, nonce, cnonce)
+	}
+	ha2 := digest(req.Method, uri)
+
+	qop := ""
+	for _, option := range strings.Split(challenge["qop"], ",") {
+		if strings.TrimSpace(option) == "auth" {
+			qop = "auth"
+		}
+	}
+
+	var response string
+	if qop != "" {
+		response = digest(ha1, nonce, nc, cnonce, qop, ha2)
+	} else {
+		response = digest(ha1, nonce, ha2)
+	}
+
+	parts := []string{
+		fmt.Sprintf(`username="%s"`, auth.Username),
+		fmt.Sprintf(`realm="%s"`, realm),
+		fmt.Sprintf(`nonce="%s"`, nonce),
+		fmt.Sprintf(`uri="%s"`, uri),
+		fmt.Sprintf(`response="%s"`, response),
+	}
+	if algorithm != "" {
+		parts = append(parts, "algorithm="+algorithm)
+	}
+	if qop != "" {
+		parts = append(parts, "qop="+qop, "nc="+nc, fmt.Sprintf(`cnonce="%s"`, cnonce))
+	}
+	if opaque, ok := challenge["opaque"]; ok {
+		parts = append(parts, fmt.Sprintf(`opaque="%s"`, opaque))
+	}
+
+	return "Digest " + strings.Join(parts, ", ")
+}
+
+// newCnonce returns a random client nonce
+func newCnonce() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
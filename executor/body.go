@@ -0,0 +1,105 @@
+package executor
+
+import (
+	"bytes"
+	"github.com/leobrines/curlman/models"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BuildBody encodes the body of a request according to its body mode
+// It returns the body reader (nil when there is no body) and the Content-Type
+// the body needs, or "" when any Content-Type header of the request should be kept
+func BuildBody(request *models.Request) (io.Reader, string, error) {
+	if !request.HasBody() {
+		return nil, "", nil
+	}
+
+	switch request.GetBodyMode() {
+	case models.BodyURLEncoded:
+		return strings.NewReader(EncodeForm(request.FormFields)), "application/x-www-form-urlencoded", nil
+
+	case models.BodyFormData:
+		return buildMultipart(request.FormFields)
+
+	case models.BodyBinary:
+		data, err := os.ReadFile(request.BodyFile)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read body file: %w", err)
+		}
+		return bytes.NewReader(data), FileContentType(request.BodyFile), nil
+
+	case models.BodyRaw:
+		return strings.NewReader(request.Body), models.LanguageContentType(request.BodyLanguage), nil
+	}
+
+	return nil, "", fmt.Errorf("unknown body mode: %s", request.BodyMode)
+}
+
+// EncodeForm encodes form fields as application/x-www-form-urlencoded, keeping their order
+func EncodeForm(fields []models.FormField) string {
+	pairs := []string{}
+	for _, field := range fields {
+		pairs = append(pairs, url.QueryEscape(field.Key)+"="+url.QueryEscape(field.Value))
+	}
+	return strings.Join(pairs, "&")
+}
+
+// buildMultipart encodes form fields as multipart/form-data, reading file parts from disk
+func buildMultipart(fields []models.FormField) (io.Reader, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	for _, field := range fields {
+		if !field.IsFile() {
+			if err := writer.WriteField(field.Key, field.Value); err != nil {
+				return nil, "", fmt.Errorf("failed to write form field '%s': %w", field.Key, err)
+			}
+			continue
+		}
+
+		file, err := os.Open(field.Value)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to open file for form field '%s': %w", field.Key, err)
+		}
+
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(field.Key), escapeQuotes(filepath.Base(field.Value))))
+		header.Set("Content-Type", FileContentType(field.Value))
+
+		part, err := writer.CreatePart(header)
+		if err == nil {
+			_, err = io.Copy(part, file)
+		}
+		file.Close()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to write file for form field '%s': %w", field.Key, err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to finish multipart body: %w", err)
+	}
+
+	return &buf, writer.FormDataContentType(), nil
+}
+
+// FileContentType guesses the Content-Type of a file from its extension
+func FileContentType(path string) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		return contentType
+	}
+	return "application/octet-stream"
+}
+
+// escapeQuotes escapes a value for a quoted Content-Disposition parameter
+func escapeQuotes(s string) string {
+	return strings.NewReplacer("\\", "\\\\", `"`, "\\\"").Replace(s)
+}
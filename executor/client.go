@@ -0,0 +1,189 @@
+package executor
+
+import (
+	"github.com/leobrines/curlman/models"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds a request when its settings set no timeout
+const DefaultTimeout = 30 * time.Second
+
+// DefaultMaxRedirects is the number of redirects followed when no limit is set
+const DefaultMaxRedirects = 10
+
+// NewClient builds an HTTP client from client settings with variables already injected
+// The jar, when given, is used unless the settings disable cookies
+func NewClient(settings *models.ClientSettings, jar http.CookieJar) (*http.Client, error) {
+	if settings == nil {
+		settings = &models.ClientSettings{}
+	}
+
+	timeout := DefaultTimeout
+	if settings.Timeout != "" {
+		d, err := time.ParseDuration(settings.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q: %w", settings.Timeout, err)
+		}
+		timeout = d
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	proxy, err := proxyFunc(settings)
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = proxy
+
+	tlsConfig, err := tlsConfig(settings)
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	switch settings.HTTPVersion {
+	case "":
+	case models.HTTPVersion11:
+		transport.Protocols = new(http.Protocols)
+		transport.Protocols.SetHTTP1(true)
+	case models.HTTPVersion2:
+		// HTTP/2 over TLS, and with prior knowledge over plain HTTP
+		transport.Protocols = new(http.Protocols)
+		transport.Protocols.SetHTTP2(true)
+		transport.Protocols.SetUnencryptedHTTP2(true)
+	default:
+		return nil, fmt.Errorf("invalid HTTP version %q (use 1.1 or 2)", settings.HTTPVersion)
+	}
+
+	follow := settings.FollowRedirects == nil || *settings.FollowRedirects
+	maxRedirects := settings.MaxRedirects
+	if maxRedirects <= 0 {
+		maxRedirects = DefaultMaxRedirects
+	}
+
+	client := &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !follow {
+				// Hand the redirect response back instead of following it
+				return http.ErrUseLastResponse
+			}
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("stopped after %d redirects", maxRedirects)
+			}
+			return nil
+		},
+	}
+	if jar != nil && settings.CookiesEnabled() {
+		client.Jar = jar
+	}
+	return client, nil
+}
+
+// proxyFunc picks the proxy of a request: the configured one, or the environment's
+// Hosts listed in NoProxy always connect directly
+func proxyFunc(settings *models.ClientSettings) (func(*http.Request) (*url.URL, error), error) {
+	var proxyURL *url.URL
+	if settings.Proxy != "" {
+		raw := settings.Proxy
+		if !strings.Contains(raw, "://") {
+			raw = "http://" + raw
+		}
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy %q", settings.Proxy)
+		}
+		proxyURL = u
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		if settings.NoProxy != "" && bypassProxy(req.URL.Hostname(), settings.NoProxy) {
+			return nil, nil
+		}
+		if proxyURL != nil {
+			return proxyURL, nil
+		}
+		return http.ProxyFromEnvironment(req)
+	}, nil
+}
+
+// bypassProxy matches a host against a NO_PROXY list: "*", domains (matching
+// their subdomains too, with or without a leading dot), IP addresses and CIDR ranges
+func bypassProxy(host, noProxy string) bool {
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+
+	for _, entry := range strings.Split(noProxy, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if entry == "*" {
+			return true
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if h, _, err := net.SplitHostPort(entry); err == nil {
+			entry = h
+		}
+		entry = strings.TrimPrefix(entry, ".")
+		if host == entry || strings.HasSuffix(host, "."+entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// tlsConfig builds the TLS settings: verification, extra CA bundle and client certificate
+func tlsConfig(settings *models.ClientSettings) (*tls.Config, error) {
+	config := &tls.Config{}
+
+	if settings.Insecure != nil && *settings.Insecure {
+		config.InsecureSkipVerify = true
+	}
+
+	if settings.CACert != "" {
+		pem, err := os.ReadFile(settings.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", settings.CACert)
+		}
+		config.RootCAs = pool
+	}
+
+	if settings.ClientCert != "" {
+		// The key may be bundled in the certificate file
+		keyFile := settings.ClientKey
+		if keyFile == "" {
+			keyFile = settings.ClientCert
+		}
+		cert, err := tls.LoadX509KeyPair(settings.ClientCert, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	} else if settings.ClientKey != "" {
+		return nil, fmt.Errorf("client_key needs a client_cert")
+	}
+
+	return config, nil
+}
@@ -0,0 +1,140 @@
+package executor
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// MaxBodySize is the number of body bytes kept in memory; larger bodies are truncated unless downloaded
+const MaxBodySize = 10 << 20
+
+// sniffSize is the number of leading body bytes inspected to tell text from binary
+const sniffSize = 8 << 10
+
+// Progress reports how much of a response body was received; it is safe for concurrent use
+type Progress struct {
+	received atomic.Int64
+	total    atomic.Int64
+}
+
+// Received is the number of body bytes read so far
+func (p *Progress) Received() int64 {
+	return p.received.Load()
+}
+
+// Total is the announced Content-Length, -1 when unknown and 0 until the response arrives
+func (p *Progress) Total() int64 {
+	return p.total.Load()
+}
+
+// readBody reads a response body, keeping up to MaxBodySize bytes in memory
+// When file is not nil the whole body is written to it as well
+func readBody(r io.Reader, file io.Writer, progress *Progress) (kept []byte, size int64, err error) {
+	buf := make([]byte, 32<<10)
+	for {
+		n, readErr := r.Read(buf)
+		if n > 0 {
+			if file != nil {
+				if _, err := file.Write(buf[:n]); err != nil {
+					return kept, size, fmt.Errorf("failed to write body: %w", err)
+				}
+			}
+			if room := MaxBodySize - len(kept); room > 0 {
+				kept = append(kept, buf[:min(n, room)]...)
+			}
+			size += int64(n)
+			if progress != nil {
+				progress.received.Add(int64(n))
+			}
+		}
+		if readErr == io.EOF {
+			return kept, size, nil
+		}
+		if readErr != nil {
+			return kept, size, fmt.Errorf("failed to read response body: %w", readErr)
+		}
+	}
+}
+
+// DetectContentType returns the Content-Type header, or the type sniffed from the body without one
+func DetectContentType(header http.Header, body []byte) string {
+	if contentType := header.Get("Content-Type"); contentType != "" {
+		return contentType
+	}
+	if len(body) == 0 {
+		return ""
+	}
+	return http.DetectContentType(body)
+}
+
+// IsBinary reports whether a body is not text, from its content type then from its first bytes
+func IsBinary(contentType string, body []byte) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "text/"), strings.Contains(mediaType, "json"), strings.Contains(mediaType, "xml"),
+		strings.Contains(mediaType, "javascript"), strings.Contains(mediaType, "yaml"),
+		mediaType == "application/x-www-form-urlencoded":
+		return false
+	case strings.HasPrefix(mediaType, "image/"), strings.HasPrefix(mediaType, "audio/"),
+		strings.HasPrefix(mediaType, "video/"), strings.HasPrefix(mediaType, "font/"),
+		mediaType == "application/pdf", mediaType == "application/zip", mediaType == "application/gzip":
+		return true
+	}
+
+	sample := body[:min(len(body), sniffSize)]
+	if strings.IndexByte(string(sample), 0) != -1 {
+		return true
+	}
+	// The sample may end in the middle of a character
+	for cut := 0; cut < utf8.UTFMax && cut <= len(sample); cut++ {
+		if utf8.Valid(sample[:len(sample)-cut]) {
+			return false
+		}
+	}
+	return true
+}
+
+// copyBodyFile copies a downloaded body to another file
+func copyBodyFile(source, destination string) error {
+	if source == destination {
+		return nil
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("failed to open downloaded body: %w", err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(destination)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// FormatSize renders a byte count in a human readable unit
+func FormatSize(size int64) string {
+	switch {
+	case size >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(size)/(1<<30))
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", size)
+}
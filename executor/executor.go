@@ -1,11 +1,13 @@
 package executor
 
 import (
-	"bytes"
+	"github.com/leobrines/curlman/jsonpath"
 	"github.com/leobrines/curlman/models"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptrace"
 	"os"
 	"strings"
 	"time"
@@ -13,58 +15,129 @@ import (
 
 // Response represents the HTTP response
 type Response struct {
-	StatusCode int
-	Status     string
-	Headers    http.Header
-	Body       string
-	Duration   time.Duration
-	Error      error
+	StatusCode  int
+	Status      string
+	Headers     http.Header
+	Body        string // Up to MaxBodySize bytes of the body
+	Size        int64  // Size of the whole body in bytes
+	Truncated   bool   // Body holds only the beginning of the body
+	ContentType string // Content-Type header, or the type sniffed from the body
+	Binary      bool   // Body is not text
+	BodyFile    string // File the whole body was downloaded to, "" when it was only read into memory
+	Duration    time.Duration
+	Timing      Timing
+	Error       error
+	Request     *models.Request // Request as sent, with its variables injected; nil when they could not be
 }
 
 // Execute executes an HTTP request and returns the response
-func Execute(request *models.Request, variables map[string]string) *Response {
+// Cookies are read from and stored in jar, which may be nil
+// Cancelling ctx aborts the request
+func Execute(ctx context.Context, request *models.Request, variables map[string]string, jar http.CookieJar) *Response {
+	return execute(ctx, request, variables, jar, "", nil)
+}
+
+// Download executes an HTTP request and streams the whole body to a file, reporting progress when given
+// Only the beginning of the body is kept in the response; requests without a timeout setting have none
+func Download(ctx context.Context, request *models.Request, variables map[string]string, jar http.CookieJar, path string, progress *Progress) *Response {
+	return execute(ctx, request, variables, jar, path, progress)
+}
+
+func execute(ctx context.Context, request *models.Request, variables map[string]string, jar http.CookieJar, path string, progress *Progress) *Response {
 	start := time.Now()
 	response := &Response{}
 
-	// Inject variables
-	injected := request.InjectVariables(variables)
-
-	// Create HTTP request
-	url := injected.FullURL()
-	var bodyReader io.Reader
-	if injected.Body != "" {
-		bodyReader = bytes.NewBufferString(injected.Body)
-	}
+	// Phases are traced on every connection the request makes, through redirects and retries
+	trace := newTracer()
+	ctx = httptrace.WithClientTrace(ctx, trace.clientTrace())
+	var resp *http.Response
+	defer func() {
+		response.Duration = time.Since(start)
+		response.Timing = trace.finish(resp)
+	}()
 
-	req, err := http.NewRequest(injected.Method, url, bodyReader)
+	// Inject variables
+	injected, err := request.InjectVariables(variables)
 	if err != nil {
-		response.Error = fmt.Errorf("failed to create request: %w", err)
+		response.Error = err
 		return response
 	}
+	response.Request = injected
 
-	// Add headers
-	for key, value := range injected.Headers {
-		req.Header.Set(key, value)
+	req, err := newHTTPRequest(ctx, injected)
+	if err != nil {
+		response.Error = err
+		return response
 	}
 
 	// Execute the request
-	client := &http.Client{
-		Timeout: 30 * time.Second,
+	client, err := NewClient(injected.Settings, jar)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+	if path != "" && (injected.Settings == nil || injected.Settings.Timeout == "") {
+		// The default timeout covers reading the body, which large downloads outlast
+		client.Timeout = 0
 	}
 
-	resp, err := client.Do(req)
+	trace.send()
+	resp, err = client.Do(req)
 	if err != nil {
 		response.Error = fmt.Errorf("request failed: %w", err)
-		response.Duration = time.Since(start)
 		return response
 	}
+
+	// Digest auth answers the server challenge with a second request
+	if injected.Auth != nil && injected.Auth.Type == models.AuthDigest && resp.StatusCode == http.StatusUnauthorized {
+		if challenge, ok := digestChallenge(resp.Header); ok {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+
+			req, err = newHTTPRequest(ctx, injected)
+			if err != nil {
+				response.Error = err
+				return response
+			}
+			req.Header.Set("Authorization", digestAuthorization(challenge, injected.Auth, req))
+
+			resp, err = client.Do(req)
+			if err != nil {
+				response.Error = fmt.Errorf("request failed: %w", err)
+				return response
+			}
+		}
+	}
 	defer resp.Body.Close()
 
+	var file *os.File
+	if path != "" {
+		file, err = os.Create(path)
+		if err != nil {
+			response.Error = fmt.Errorf("failed to create file: %w", err)
+			return response
+		}
+	}
+	if progress != nil {
+		progress.total.Store(resp.ContentLength)
+	}
+
 	// Read response body
-	bodyBytes, err := io.ReadAll(resp.Body)
+	var sink io.Writer
+	if file != nil {
+		sink = file
+	}
+	bodyBytes, size, err := readBody(resp.Body, sink, progress)
+	if file != nil {
+		if closeErr := file.Close(); err == nil && closeErr != nil {
+			err = fmt.Errorf("failed to write body: %w", closeErr)
+		}
+		if err != nil {
+			os.Remove(path)
+		}
+	}
 	if err != nil {
-		response.Error = fmt.Errorf("failed to read response body: %w", err)
-		response.Duration = time.Since(start)
+		response.Error = err
 		return response
 	}
 
@@ -72,11 +145,47 @@ func Execute(request *models.Request, variables map[string]string) *Response {
 	response.Status = resp.Status
 	response.Headers = resp.Header
 	response.Body = string(bodyBytes)
-	response.Duration = time.Since(start)
-
+	response.Size = size
+	response.Truncated = size > int64(len(bodyBytes))
+	response.ContentType = DetectContentType(resp.Header, bodyBytes)
+	response.Binary = IsBinary(response.ContentType, bodyBytes)
+	response.BodyFile = path
 	return response
 }
 
+// newHTTPRequest builds the HTTP request for a request with variables already injected
+func newHTTPRequest(ctx context.Context, request *models.Request) (*http.Request, error) {
+	bodyReader, contentType, err := BuildBody(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, request.Method, request.Auth.ApplyToURL(request.FullURL()), bodyReader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Add headers
+	for key, value := range request.Headers {
+		req.Header.Set(key, value)
+	}
+
+	// Multipart bodies always need their own boundary; other modes keep an explicit Content-Type
+	if contentType != "" && (req.Header.Get("Content-Type") == "" || request.GetBodyMode() == models.BodyFormData) {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	// Auth credentials replace a hand-written header of the same name
+	if name := request.Auth.HeaderName(); name != "" {
+		req.Header.Del(name)
+	}
+	if name, value, ok := request.Auth.Header(); ok {
+		req.Header.Set(name, value)
+	}
+
+	return req, nil
+}
+
 // FormatResponse formats the response for display
 func FormatResponse(resp *Response) string {
 	if resp.Error != nil {
@@ -85,7 +194,12 @@ func FormatResponse(resp *Response) string {
 
 	var result strings.Builder
 	result.WriteString(fmt.Sprintf("Status: %s\n", resp.Status))
-	result.WriteString(fmt.Sprintf("Duration: %s\n\n", resp.Duration))
+	result.WriteString(fmt.Sprintf("Duration: %s\n", resp.Duration))
+	result.WriteString(fmt.Sprintf("Timing: %s\n", FormatTiming(resp.Timing)))
+	if connection := FormatConnection(resp.Timing); connection != "" {
+		result.WriteString(fmt.Sprintf("Connection: %s\n", connection))
+	}
+	result.WriteString("\n")
 
 	result.WriteString("Headers:\n")
 	for key, values := range resp.Headers {
@@ -94,14 +208,43 @@ func FormatResponse(resp *Response) string {
 		}
 	}
 
-	result.WriteString("\nBody:\n")
-	result.WriteString(resp.Body)
+	switch {
+	case resp.BodyFile != "":
+		result.WriteString(fmt.Sprintf("\nBody: %s saved to %s\n", FormatSize(resp.Size), resp.BodyFile))
+	case resp.Binary:
+		result.WriteString(fmt.Sprintf("\nBody: %s of binary data (%s), not shown\n", FormatSize(resp.Size), resp.ContentType))
+	default:
+		result.WriteString("\nBody:\n")
+		result.WriteString(resp.Body)
+		if resp.Truncated {
+			result.WriteString(fmt.Sprintf("\n... truncated, %s in total\n", FormatSize(resp.Size)))
+		}
+	}
 
 	return result.String()
 }
 
+// FilterBody applies a JSONPath or jq-style expression to a JSON response body
+func FilterBody(resp *Response, filter string) (string, error) {
+	if resp == nil {
+		return "", fmt.Errorf("response is nil")
+	}
+	if resp.Error != nil {
+		return "", fmt.Errorf("cannot filter response with error: %w", resp.Error)
+	}
+	if resp.Truncated {
+		return "", fmt.Errorf("cannot filter a body truncated at %s", FormatSize(int64(len(resp.Body))))
+	}
+	filtered, err := jsonpath.Filter(resp.Body, filter)
+	if err != nil {
+		return "", fmt.Errorf("failed to filter body: %w", err)
+	}
+	return filtered, nil
+}
+
 // SaveResponseBody saves the response body to a file
-func SaveResponseBody(resp *Response, filepath string) error {
+// A non-empty filter saves only the matching part of a JSON body; downloaded bodies are copied whole
+func SaveResponseBody(resp *Response, filepath string, filter string) error {
 	if resp == nil {
 		return fmt.Errorf("response is nil")
 	}
@@ -110,7 +253,22 @@ func SaveResponseBody(resp *Response, filepath string) error {
 		return fmt.Errorf("cannot save response with error: %w", resp.Error)
 	}
 
-	err := os.WriteFile(filepath, []byte(resp.Body), 0644)
+	body := resp.Body
+	switch {
+	case filter != "":
+		filtered, err := FilterBody(resp, filter)
+		if err != nil {
+			return err
+		}
+		body = filtered + "\n"
+	case resp.BodyFile != "":
+		return copyBodyFile(resp.BodyFile, filepath)
+	case resp.Truncated:
+		return fmt.Errorf("only the first %s of the %s body were kept; download the request to a file to save all of it",
+			FormatSize(int64(len(resp.Body))), FormatSize(resp.Size))
+	}
+
+	err := os.WriteFile(filepath, []byte(body), 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
@@ -0,0 +1,228 @@
+package executor
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Timing breaks the duration of a request down into phases
+// Phases of redirected and retried requests add up
+type Timing struct {
+	DNSLookup        time.Duration `json:"dns_lookup,omitempty"`
+	TCPConnect       time.Duration `json:"tcp_connect,omitempty"`
+	TLSHandshake     time.Duration `json:"tls_handshake,omitempty"`
+	TimeToFirstByte  time.Duration `json:"time_to_first_byte,omitempty"` // From when the request is sent
+	ContentTransfer  time.Duration `json:"content_transfer,omitempty"`
+	RemoteAddr       string        `json:"remote_addr,omitempty"`
+	Protocol         string        `json:"protocol,omitempty"`    // Like HTTP/1.1 or HTTP/2.0
+	TLSVersion       string        `json:"tls_version,omitempty"` // Like TLS 1.3, "" for plain HTTP
+	ReusedConnection bool          `json:"reused_connection,omitempty"`
+}
+
+// Waiting is the time between the connection being ready and the first response byte
+func (t Timing) Waiting() time.Duration {
+	return max(t.TimeToFirstByte-t.DNSLookup-t.TCPConnect-t.TLSHandshake, 0)
+}
+
+// tracer collects the timing of a request through httptrace hooks, which may run concurrently
+type tracer struct {
+	mu           sync.Mutex
+	start        time.Time
+	dnsStart     time.Time
+	connectStart map[string]time.Time
+	tlsStart     time.Time
+	firstByte    time.Time
+	timing       Timing
+}
+
+func newTracer() *tracer {
+	return &tracer{connectStart: make(map[string]time.Time)}
+}
+
+// send starts the time to first byte clock, once the request is built and about to go out
+// Retries, like the digest auth answer, keep the clock of the first send
+func (t *tracer) send() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.start.IsZero() {
+		t.start = time.Now()
+	}
+}
+
+func (t *tracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.DNSLookup += time.Since(t.dnsStart)
+		},
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.connectStart[network+addr] = time.Now()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			// Only the attempt that connected counts when several addresses are tried
+			if err == nil {
+				t.timing.TCPConnect += time.Since(t.connectStart[network+addr])
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.TLSHandshake += time.Since(t.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.RemoteAddr = info.Conn.RemoteAddr().String()
+			t.timing.ReusedConnection = info.Reused
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.firstByte = time.Now()
+			t.timing.TimeToFirstByte = t.firstByte.Sub(t.start)
+		},
+	}
+}
+
+// finish records the end of the body transfer and what was negotiated for the response, which may be nil
+func (t *tracer) finish(resp *http.Response) Timing {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.firstByte.IsZero() {
+		t.timing.ContentTransfer = time.Since(t.firstByte)
+	}
+	if resp != nil {
+		t.timing.Protocol = resp.Proto
+		if resp.TLS != nil {
+			t.timing.TLSVersion = tls.VersionName(resp.TLS.Version)
+		}
+	}
+	return t.timing
+}
+
+// FormatTiming summarizes the phases of a request on one line
+func FormatTiming(t Timing) string {
+	return fmt.Sprintf("DNS %s, connect %s, TLS %s, waiting %s, transfer %s (first byte after %s)",
+		FormatDuration(t.DNSLookup), FormatDuration(t.TCPConnect), FormatDuration(t.TLSHandshake),
+		FormatDuration(t.Waiting()), FormatDuration(t.ContentTransfer), FormatDuration(t.TimeToFirstByte))
+}
+
+// FormatConnection describes the remote address, protocol and TLS version of a request
+func FormatConnection(t Timing) string {
+	var parts []string
+	for _, part := range []string{t.RemoteAddr, t.Protocol, t.TLSVersion} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if t.ReusedConnection {
+		parts = append(parts, "reused connection")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// FormatDuration renders a phase duration readably: microseconds below a millisecond, then tenths of milliseconds
+func FormatDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(100 * time.Microsecond).String()
+}
+
+// responseJSON is the machine readable form of a response
+type responseJSON struct {
+	StatusCode  int                 `json:"status_code,omitempty"`
+	Status      string              `json:"status,omitempty"`
+	Headers     map[string][]string `json:"headers,omitempty"`
+	Body        *string             `json:"body,omitempty"` // Left out for binary and downloaded bodies
+	Size        int64               `json:"size"`
+	Truncated   bool                `json:"truncated,omitempty"`
+	ContentType string              `json:"content_type,omitempty"`
+	Binary      bool                `json:"binary,omitempty"`
+	BodyFile    string              `json:"body_file,omitempty"`
+	DurationMS  float64             `json:"duration_ms"`
+	Timing      timingJSON          `json:"timing"`
+	Error       string              `json:"error,omitempty"`
+}
+
+// timingJSON reports phases in milliseconds
+type timingJSON struct {
+	DNSLookupMS       float64 `json:"dns_lookup_ms"`
+	TCPConnectMS      float64 `json:"tcp_connect_ms"`
+	TLSHandshakeMS    float64 `json:"tls_handshake_ms"`
+	WaitingMS         float64 `json:"waiting_ms"`
+	TimeToFirstByteMS float64 `json:"time_to_first_byte_ms"`
+	ContentTransferMS float64 `json:"content_transfer_ms"`
+	RemoteAddr        string  `json:"remote_addr,omitempty"`
+	Protocol          string  `json:"protocol,omitempty"`
+	TLSVersion        string  `json:"tls_version,omitempty"`
+	ReusedConnection  bool    `json:"reused_connection"`
+}
+
+// FormatResponseJSON renders a response, with its timing, as indented JSON
+func FormatResponseJSON(resp *Response) (string, error) {
+	ms := func(d time.Duration) float64 {
+		return float64(d.Microseconds()) / 1000
+	}
+
+	out := responseJSON{
+		StatusCode:  resp.StatusCode,
+		Status:      resp.Status,
+		Headers:     resp.Headers,
+		Size:        resp.Size,
+		Truncated:   resp.Truncated,
+		ContentType: resp.ContentType,
+		Binary:      resp.Binary,
+		BodyFile:    resp.BodyFile,
+		DurationMS:  ms(resp.Duration),
+		Timing: timingJSON{
+			DNSLookupMS:       ms(resp.Timing.DNSLookup),
+			TCPConnectMS:      ms(resp.Timing.TCPConnect),
+			TLSHandshakeMS:    ms(resp.Timing.TLSHandshake),
+			WaitingMS:         ms(resp.Timing.Waiting()),
+			TimeToFirstByteMS: ms(resp.Timing.TimeToFirstByte),
+			ContentTransferMS: ms(resp.Timing.ContentTransfer),
+			RemoteAddr:        resp.Timing.RemoteAddr,
+			Protocol:          resp.Timing.Protocol,
+			TLSVersion:        resp.Timing.TLSVersion,
+			ReusedConnection:  resp.Timing.ReusedConnection,
+		},
+	}
+	if resp.Error != nil {
+		out.Error = resp.Error.Error()
+	} else if !resp.Binary && resp.BodyFile == "" {
+		out.Body = &resp.Body
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		return "", fmt.Errorf("failed to encode response: %w", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
@@ -1,9 +1,12 @@
 package exporter
 
 import (
+	"github.com/leobrines/curlman/executor"
 	"github.com/leobrines/curlman/models"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 )
 
 // ToCurl converts a request to a curl command
@@ -19,25 +22,167 @@ func ToCurl(request *models.Request) string {
 	}
 
 	// Add headers
+	mode := request.GetBodyMode()
+	authHeader := request.Auth.HeaderName()
 	for key, value := range request.Headers {
-		parts = append(parts, fmt.Sprintf("-H '%s: %s'", key, value))
+		// curl writes the multipart Content-Type itself, boundary included
+		if mode == models.BodyFormData && strings.EqualFold(key, "Content-Type") {
+			continue
+		}
+		// Auth credentials replace a hand-written header of the same name
+		if authHeader != "" && strings.EqualFold(key, authHeader) {
+			continue
+		}
+		parts = append(parts, "-H "+shellQuote(fmt.Sprintf("%s: %s", key, value)))
+	}
+
+	// Add auth
+	parts = append(parts, authFlags(request.Auth)...)
+
+	// Add the Content-Type the executor sends for raw bodies with a language and binary bodies
+	contentType := ""
+	switch {
+	case mode == models.BodyRaw && request.Body != "":
+		contentType = models.LanguageContentType(request.BodyLanguage)
+	case mode == models.BodyBinary && request.BodyFile != "":
+		contentType = executor.FileContentType(request.BodyFile)
+	}
+	if contentType != "" {
+		if _, ok := request.GetHeader("Content-Type"); !ok {
+			parts = append(parts, "-H "+shellQuote("Content-Type: "+contentType))
+		}
 	}
 
 	// Add body if present
-	if request.Body != "" {
-		escapedBody := strings.ReplaceAll(request.Body, "'", "'\\''")
-		parts = append(parts, fmt.Sprintf("-d '%s'", escapedBody))
+	if request.HasBody() {
+		parts = append(parts, bodyFlags(request)...)
 	}
 
 	// Add URL (including query params)
-	url := request.FullURL()
-	parts = append(parts, fmt.Sprintf("'%s'", url))
+	url := request.Auth.ApplyToURL(request.FullURL())
+
+	// Add client settings
+	parts = append(parts, settingsFlags(request.Settings, url)...)
+
+	parts = append(parts, shellQuote(url))
 
 	return strings.Join(parts, " ")
 }
 
 // ToCurlWithVariables converts a request to a curl command with variables injected
-func ToCurlWithVariables(request *models.Request, variables map[string]string) string {
-	injected := request.InjectVariables(variables)
-	return ToCurl(injected)
+func ToCurlWithVariables(request *models.Request, variables map[string]string) (string, error) {
+	injected, err := request.InjectVariables(variables)
+	if err != nil {
+		return "", err
+	}
+	return ToCurl(injected), nil
+}
+
+// authFlags returns the curl options sending the credentials of an auth
+// API keys sent in the query string are part of the URL instead
+func authFlags(auth *models.Auth) []string {
+	if auth == nil {
+		return nil
+	}
+	switch auth.Type {
+	case models.AuthBasic:
+		return []string{"-u " + shellQuote(auth.Username+":"+auth.Password)}
+	case models.AuthDigest:
+		return []string{"--digest", "-u " + shellQuote(auth.Username+":"+auth.Password)}
+	}
+	if name, value, ok := auth.Header(); ok {
+		return []string{"-H " + shellQuote(fmt.Sprintf("%s: %s", name, value))}
+	}
+	return nil
+}
+
+// settingsFlags returns the curl options matching the client settings
+// Redirects are followed unless turned off, like the executor does, while curl needs -L for it
+func settingsFlags(settings *models.ClientSettings, url string) []string {
+	if settings == nil {
+		settings = &models.ClientSettings{}
+	}
+
+	var flags []string
+	if settings.Timeout != "" {
+		if d, err := time.ParseDuration(settings.Timeout); err == nil {
+			flags = append(flags, "--max-time "+strconv.FormatFloat(d.Seconds(), 'f', -1, 64))
+		}
+	}
+	if settings.FollowRedirects == nil || *settings.FollowRedirects {
+		flags = append(flags, "-L")
+		if settings.MaxRedirects > 0 {
+			flags = append(flags, "--max-redirs "+strconv.Itoa(settings.MaxRedirects))
+		}
+	}
+	if settings.Proxy != "" {
+		flags = append(flags, "-x "+shellQuote(settings.Proxy))
+	}
+	if settings.NoProxy != "" {
+		flags = append(flags, "--noproxy "+shellQuote(settings.NoProxy))
+	}
+	if settings.Insecure != nil && *settings.Insecure {
+		flags = append(flags, "-k")
+	}
+	if settings.CACert != "" {
+		flags = append(flags, "--cacert "+shellQuote(settings.CACert))
+	}
+	if settings.ClientCert != "" {
+		flags = append(flags, "--cert "+shellQuote(settings.ClientCert))
+	}
+	if settings.ClientKey != "" {
+		flags = append(flags, "--key "+shellQuote(settings.ClientKey))
+	}
+	switch settings.HTTPVersion {
+	case models.HTTPVersion11:
+		flags = append(flags, "--http1.1")
+	case models.HTTPVersion2:
+		if strings.HasPrefix(strings.ToLower(url), "https://") {
+			flags = append(flags, "--http2")
+		} else {
+			flags = append(flags, "--http2-prior-knowledge")
+		}
+	}
+	return flags
+}
+
+// bodyFlags returns the curl options sending the body of the request
+func bodyFlags(request *models.Request) []string {
+	var flags []string
+
+	switch request.GetBodyMode() {
+	case models.BodyURLEncoded:
+		// Encoded like the executor does, name included, which --data-urlencode leaves as is
+		for _, field := range request.FormFields {
+			flags = append(flags, "--data-raw "+shellQuote(executor.EncodeForm([]models.FormField{field})))
+		}
+	case models.BodyFormData:
+		for _, field := range request.FormFields {
+			switch {
+			case field.IsFile():
+				flags = append(flags, "-F "+shellQuote(field.Key+"=@"+field.Value))
+			case strings.HasPrefix(field.Value, "@") || strings.HasPrefix(field.Value, "<"):
+				// -F would read a file for these; --form-string sends the text as-is
+				flags = append(flags, "--form-string "+shellQuote(field.Key+"="+field.Value))
+			default:
+				flags = append(flags, "-F "+shellQuote(field.Key+"="+field.Value))
+			}
+		}
+	case models.BodyBinary:
+		flags = append(flags, "--data-binary "+shellQuote("@"+request.BodyFile))
+	default:
+		if strings.HasPrefix(request.Body, "@") {
+			// -d would read a file for a body starting with @
+			flags = append(flags, "--data-raw "+shellQuote(request.Body))
+		} else {
+			flags = append(flags, "-d "+shellQuote(request.Body))
+		}
+	}
+
+	return flags
+}
+
+// shellQuote wraps a value in single quotes, escaping embedded single quotes
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "'\\''") + "'"
 }
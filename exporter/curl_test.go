@@ -0,0 +1,49 @@
+package exporter
+
+import (
+	"github.com/leobrines/curlman/executor"
+	"github.com/leobrines/curlman/models"
+	"strings"
+	"testing"
+)
+
+func TestURLEncodedBodyMatchesExecutor(t *testing.T) {
+	request := &models.Request{
+		Method:   "POST",
+		URL:      "https://api.example.com/login",
+		BodyMode: models.BodyURLEncoded,
+		FormFields: []models.FormField{
+			{Key: "user name", Value: "ann&bob", Type: models.FormFieldText},
+			{Key: "a=b", Value: "c d", Type: models.FormFieldText},
+		},
+	}
+
+	got := ToCurl(request)
+	want := "--data-raw 'user+name=ann%26bob' --data-raw 'a%3Db=c+d'"
+	if !strings.Contains(got, want) {
+		t.Errorf("ToCurl = %s, want %s", got, want)
+	}
+	// curl joins the pieces with &, giving the body the executor sends
+	if body := executor.EncodeForm(request.FormFields); body != "user+name=ann%26bob&a%3Db=c+d" {
+		t.Errorf("EncodeForm = %s", body)
+	}
+}
+
+func TestBinaryBodyContentType(t *testing.T) {
+	request := &models.Request{
+		Method:   "PUT",
+		URL:      "https://api.example.com/upload",
+		BodyMode: models.BodyBinary,
+		BodyFile: "photo.png",
+	}
+
+	got := ToCurl(request)
+	if !strings.Contains(got, "-H 'Content-Type: image/png' --data-binary '@photo.png'") {
+		t.Errorf("ToCurl = %s, want the file Content-Type", got)
+	}
+
+	request.Headers = map[string]string{"Content-Type": "application/octet-stream"}
+	if got := ToCurl(request); strings.Contains(got, "image/png") {
+		t.Errorf("ToCurl = %s, want the Content-Type header of the request kept", got)
+	}
+}
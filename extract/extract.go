@@ -0,0 +1,128 @@
+package extract
+
+import (
+	"github.com/leobrines/curlman/executor"
+	"github.com/leobrines/curlman/jsonpath"
+	"github.com/leobrines/curlman/models"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Result is the outcome of applying a single extraction rule
+type Result struct {
+	Extraction models.Extraction
+	Value      string
+	Error      error
+}
+
+// Parse builds an extraction from its textual form, for example:
+//
+//	json $.data.token as token
+//	header Location as created_url in environment
+//	regex id=(\d+) as user_id in collection
+func Parse(text string) (models.Extraction, error) {
+	text = strings.TrimSpace(text)
+
+	space := strings.IndexAny(text, " \t")
+	if space == -1 {
+		return models.Extraction{}, fmt.Errorf("expected '<json|header|regex> <expression> as <variable> [in collection|environment]'")
+	}
+	e := models.Extraction{Source: strings.ToLower(text[:space])}
+	rest := strings.TrimSpace(text[space:])
+
+	// The expression may contain spaces (regex), so split on the last " as "
+	asIdx := strings.LastIndex(rest, " as ")
+	if asIdx == -1 {
+		return models.Extraction{}, fmt.Errorf("missing 'as <variable>'")
+	}
+	e.Expression = strings.TrimSpace(rest[:asIdx])
+	target := strings.Fields(rest[asIdx+len(" as "):])
+
+	switch {
+	case len(target) == 1:
+		e.Variable = target[0]
+	case len(target) == 3 && strings.ToLower(target[1]) == "in":
+		e.Variable = target[0]
+		e.Scope = strings.ToLower(target[2])
+	default:
+		return models.Extraction{}, fmt.Errorf("expected 'as <variable> [in collection|environment]'")
+	}
+
+	if err := e.Validate(); err != nil {
+		return models.Extraction{}, err
+	}
+	if e.Source == models.ExtractRegex {
+		if _, err := regexp.Compile(e.Expression); err != nil {
+			return models.Extraction{}, fmt.Errorf("invalid regular expression: %w", err)
+		}
+	}
+
+	return e, nil
+}
+
+// Apply evaluates every extraction rule against a response
+func Apply(extractions []models.Extraction, resp *executor.Response) []Result {
+	results := make([]Result, 0, len(extractions))
+	for _, e := range extractions {
+		value, err := apply(e, resp)
+		results = append(results, Result{Extraction: e, Value: value, Error: err})
+	}
+	return results
+}
+
+func apply(e models.Extraction, resp *executor.Response) (string, error) {
+	if resp == nil || resp.Error != nil {
+		return "", fmt.Errorf("no response to extract from")
+	}
+
+	switch e.Source {
+	case models.ExtractJSONPath:
+		value, err := jsonpath.GetFromJSON(resp.Body, e.Expression)
+		if err != nil {
+			return "", err
+		}
+		return jsonpath.ValueString(value), nil
+
+	case models.ExtractHeader:
+		for key, values := range resp.Headers {
+			if strings.EqualFold(key, e.Expression) && len(values) > 0 {
+				return values[0], nil
+			}
+		}
+		return "", fmt.Errorf("header %s is missing", e.Expression)
+
+	case models.ExtractRegex:
+		re, err := regexp.Compile(e.Expression)
+		if err != nil {
+			return "", fmt.Errorf("invalid regular expression: %w", err)
+		}
+		match := re.FindStringSubmatch(resp.Body)
+		if match == nil {
+			return "", fmt.Errorf("pattern did not match the body")
+		}
+		// Prefer the first capture group, fall back to the whole match
+		if len(match) > 1 {
+			return match[1], nil
+		}
+		return match[0], nil
+	}
+
+	return "", fmt.Errorf("unknown extraction source: %s", e.Source)
+}
+
+// FormatResults formats extraction results as plain text
+func FormatResults(results []Result) string {
+	var s strings.Builder
+
+	s.WriteString("Extracted:\n")
+	for _, r := range results {
+		if r.Error != nil {
+			s.WriteString(fmt.Sprintf("  x %s (%s)\n", r.Extraction.Variable, r.Error))
+		} else {
+			s.WriteString(fmt.Sprintf("  + %s = %s\n", r.Extraction.Variable, r.Value))
+		}
+	}
+
+	return s.String()
+}
@@ -0,0 +1,196 @@
+package har
+
+import (
+	"github.com/leobrines/curlman/models"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// File is an HTTP Archive (HAR 1.2) document
+type File struct {
+	Log Log `json:"log"`
+}
+
+// Log holds the recorded pages and entries
+type Log struct {
+	Version string  `json:"version"`
+	Pages   []Page  `json:"pages"`
+	Entries []Entry `json:"entries"`
+}
+
+// Page is a page load the entries belong to
+type Page struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// Entry is a single recorded request/response exchange
+type Entry struct {
+	Request Request `json:"request"`
+}
+
+// Request is the request part of an entry
+type Request struct {
+	Method      string      `json:"method"`
+	URL         string      `json:"url"`
+	Headers     []NameValue `json:"headers"`
+	QueryString []NameValue `json:"queryString"`
+	PostData    *PostData   `json:"postData"`
+}
+
+// PostData is a recorded request body
+type PostData struct {
+	MimeType string      `json:"mimeType"`
+	Text     string      `json:"text"`
+	Params   []NameValue `json:"params"`
+}
+
+// NameValue is a header, query parameter or form field
+type NameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// skippedHeaders are recomputed by the HTTP client on replay
+var skippedHeaders = map[string]bool{
+	"host":              true,
+	"content-length":    true,
+	"connection":        true,
+	"accept-encoding":   true,
+	"transfer-encoding": true,
+}
+
+// IsHAR reports whether data looks like an HTTP Archive
+func IsHAR(data []byte) bool {
+	var probe struct {
+		Log *struct {
+			Entries []json.RawMessage `json:"entries"`
+		} `json:"log"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return probe.Log != nil && probe.Log.Entries != nil
+}
+
+// ImportFromFile imports a HAR file, naming the collection after the file
+func ImportFromFile(filePath string) (*models.Collection, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read HAR file: %w", err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(filePath), filepath.Ext(filePath))
+	return Import(data, name)
+}
+
+// Import converts HAR JSON into a collection, keeping one request per distinct exchange
+func Import(data []byte, name string) (*models.Collection, error) {
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse HAR file: %w", err)
+	}
+
+	if name == "" && len(file.Log.Pages) > 0 {
+		name = file.Log.Pages[0].Title
+	}
+	if name == "" {
+		name = "HAR Import"
+	}
+
+	collection := &models.Collection{
+		Name:      name,
+		Requests:  []*models.Request{},
+		Variables: make(map[string]string),
+	}
+
+	// Browsers repeat identical requests; keep the first of each
+	seen := make(map[string]bool)
+	for _, entry := range file.Log.Entries {
+		request, ok := convertEntry(entry)
+		if !ok {
+			continue
+		}
+
+		key := request.Method + " " + entry.Request.URL + "\n" + request.Body
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		collection.Requests = append(collection.Requests, request)
+	}
+
+	return collection, nil
+}
+
+// convertEntry converts a recorded request, skipping anything that is not HTTP(S)
+func convertEntry(entry Entry) (*models.Request, bool) {
+	u, err := url.Parse(entry.Request.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, false
+	}
+
+	method := strings.ToUpper(entry.Request.Method)
+	if method == "" {
+		method = "GET"
+	}
+
+	request := &models.Request{
+		ID:          uuid.New().String(),
+		Name:        fmt.Sprintf("%s %s", method, u.Path),
+		Method:      method,
+		URL:         u.Scheme + "://" + u.Host,
+		Path:        u.EscapedPath(),
+		Headers:     make(map[string]string),
+		QueryParams: make(map[string]string),
+	}
+
+	if request.Path == "" {
+		request.Name = fmt.Sprintf("%s /", method)
+	}
+
+	if len(entry.Request.QueryString) > 0 {
+		for _, q := range entry.Request.QueryString {
+			request.QueryParams[q.Name] = q.Value
+		}
+	} else {
+		for key, values := range u.Query() {
+			request.QueryParams[key] = values[0]
+		}
+	}
+
+	for _, h := range entry.Request.Headers {
+		// HTTP/2 pseudo headers (:authority, :path...) are not real headers
+		if strings.HasPrefix(h.Name, ":") || skippedHeaders[strings.ToLower(h.Name)] {
+			continue
+		}
+		request.Headers[h.Name] = h.Value
+	}
+
+	if post := entry.Request.PostData; post != nil {
+		request.Body = post.Text
+		request.BodyLanguage = models.ContentTypeLanguage(post.MimeType)
+		if request.Body == "" && len(post.Params) > 0 {
+			// Recorded form fields without the raw text become a structured body
+			request.BodyMode = models.BodyURLEncoded
+			if strings.HasPrefix(post.MimeType, "multipart/form-data") {
+				request.BodyMode = models.BodyFormData
+			}
+			for _, p := range post.Params {
+				request.FormFields = append(request.FormFields, models.FormField{Key: p.Name, Value: p.Value, Type: models.FormFieldText})
+			}
+		}
+		if post.MimeType != "" && !request.HasHeader("Content-Type") {
+			request.Headers["Content-Type"] = post.MimeType
+		}
+	}
+
+	return request, true
+}
@@ -0,0 +1,59 @@
+package history
+
+import (
+	"strings"
+)
+
+// Diff returns a line-based diff between two entries
+// Lines only in a are prefixed with "- ", lines only in b with "+ "
+func Diff(a, b *Entry) string {
+	return DiffText(Format(a), Format(b))
+}
+
+// DiffText returns a line-based diff of two texts using their longest common subsequence
+func DiffText(a, b string) string {
+	left := strings.Split(a, "\n")
+	right := strings.Split(b, "\n")
+
+	// lcs[i][j] holds the LCS length of left[i:] and right[j:]
+	lcs := make([][]int, len(left)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(right)+1)
+	}
+	for i := len(left) - 1; i >= 0; i-- {
+		for j := len(right) - 1; j >= 0; j-- {
+			if left[i] == right[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var s strings.Builder
+	i, j := 0, 0
+	for i < len(left) && j < len(right) {
+		switch {
+		case left[i] == right[j]:
+			s.WriteString("  " + left[i] + "\n")
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			s.WriteString("- " + left[i] + "\n")
+			i++
+		default:
+			s.WriteString("+ " + right[j] + "\n")
+			j++
+		}
+	}
+	for ; i < len(left); i++ {
+		s.WriteString("- " + left[i] + "\n")
+	}
+	for ; j < len(right); j++ {
+		s.WriteString("+ " + right[j] + "\n")
+	}
+
+	return s.String()
+}
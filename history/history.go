@@ -0,0 +1,350 @@
+package history
+
+import (
+	"github.com/leobrines/curlman/executor"
+	"github.com/leobrines/curlman/models"
+	"github.com/leobrines/curlman/storage"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxBodySize is the largest request or response body kept in a history entry
+const MaxBodySize = 64 * 1024
+
+// MaxEntries is the number of entries kept in the history file
+const MaxEntries = 500
+
+// maxFileSize is the size past which the history file is trimmed to its newest entries
+// Trimming keeps at most half of it, so the file is rewritten only after many appends
+const maxFileSize = 16 * 1024 * 1024
+
+// Entry records a single request execution
+type Entry struct {
+	ID                   string           `json:"id"`
+	Timestamp            time.Time        `json:"timestamp"`
+	Collection           string           `json:"collection"`
+	CollectionFile       string           `json:"collection_file,omitempty"` // File of the collection, "" when it was not saved
+	RequestID            string           `json:"request_id,omitempty"`
+	RequestName          string           `json:"request_name"`
+	Request              *models.Request  `json:"request"` // Request with variables already resolved
+	RequestBodyTruncated bool             `json:"request_body_truncated,omitempty"`
+	StatusCode           int              `json:"status_code"`
+	Status               string           `json:"status"`
+	Headers              http.Header      `json:"headers,omitempty"`
+	Body                 string           `json:"body,omitempty"`
+	BodyTruncated        bool             `json:"body_truncated,omitempty"`
+	BodySize             int64            `json:"body_size,omitempty"`
+	Binary               bool             `json:"binary,omitempty"` // Binary bodies are not kept
+	Duration             time.Duration    `json:"duration"`
+	Timing               *executor.Timing `json:"timing,omitempty"`
+	Error                string           `json:"error,omitempty"`
+}
+
+// NewEntry builds a history entry from a resolved request and its response
+func NewEntry(collectionName string, resolved *models.Request, resp *executor.Response) *Entry {
+	entry := &Entry{
+		ID:          uuid.New().String(),
+		Timestamp:   time.Now(),
+		Collection:  collectionName,
+		RequestID:   resolved.ID,
+		RequestName: resolved.Name,
+		Request:     resolved,
+		StatusCode:  resp.StatusCode,
+		Status:      resp.Status,
+		Headers:     resp.Headers,
+		Body:        resp.Body,
+		BodySize:    resp.Size,
+		Binary:      resp.Binary,
+		Duration:    resp.Duration,
+		Timing:      &resp.Timing,
+	}
+
+	if resp.Error != nil {
+		entry.Error = resp.Error.Error()
+	}
+
+	if entry.Binary {
+		entry.Body = ""
+		entry.BodyTruncated = resp.Size > 0
+	} else if resp.Truncated {
+		entry.BodyTruncated = true
+	}
+	if len(entry.Body) > MaxBodySize {
+		entry.Body = entry.Body[:MaxBodySize]
+		entry.BodyTruncated = true
+	}
+	if len(resolved.Body) > MaxBodySize {
+		request := *resolved
+		request.Body = request.Body[:MaxBodySize]
+		entry.Request = &request
+		entry.RequestBodyTruncated = true
+	}
+
+	return entry
+}
+
+// Response rebuilds an executor response from the entry for display
+func (e *Entry) Response() *executor.Response {
+	resp := &executor.Response{
+		StatusCode: e.StatusCode,
+		Status:     e.Status,
+		Headers:    e.Headers,
+		Body:       e.Body,
+		Size:       e.BodySize,
+		Truncated:  e.BodyTruncated,
+		Binary:     e.Binary,
+		Duration:   e.Duration,
+	}
+	if e.Timing != nil {
+		resp.Timing = *e.Timing
+	}
+	if resp.Size == 0 {
+		// Entries recorded before sizes were kept
+		resp.Size = int64(len(e.Body))
+	}
+	resp.ContentType = executor.DetectContentType(e.Headers, []byte(e.Body))
+	if e.Error != "" {
+		resp.Error = fmt.Errorf("%s", e.Error)
+	}
+	return resp
+}
+
+// Summary returns a one-line description of the entry
+func (e *Entry) Summary() string {
+	status := e.Status
+	if e.Error != "" {
+		status = "ERROR"
+	}
+	method := ""
+	if e.Request != nil {
+		method = e.Request.Method
+	}
+	return fmt.Sprintf("%s  %-7s %-30s %-16s %s",
+		e.Timestamp.Format("2006-01-02 15:04:05"), method, e.RequestName, status, e.Duration.Round(time.Millisecond))
+}
+
+// GetHistoryPath returns the path of the history file
+func GetHistoryPath() (string, error) {
+	storageDir, err := storage.GetStorageDir()
+	if err != nil {
+		return "", err
+	}
+
+	historyDir := filepath.Join(storageDir, "history")
+
+	// Create history directory if it doesn't exist
+	if err := os.MkdirAll(historyDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	return filepath.Join(historyDir, "history.jsonl"), nil
+}
+
+// Append adds an entry to the end of the history file
+// The file is trimmed to its newest entries once it grows past maxFileSize
+func Append(entry *Entry) error {
+	path, err := GetHistoryPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history entry: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open history file: %w", err)
+	}
+	// Entries hold resolved credentials; files created by older versions were readable by others
+	if err := file.Chmod(0600); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to protect history file: %w", err)
+	}
+	_, err = file.Write(append(data, '\n'))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil || info.Size() <= maxFileSize {
+		return nil
+	}
+	entries, err := Load()
+	if err != nil {
+		return err
+	}
+	return write(entries)
+}
+
+// Load reads the newest MaxEntries history entries, newest first
+// Lines that cannot be parsed are skipped instead of losing the whole history
+func Load() ([]*Entry, error) {
+	path, err := GetHistoryPath()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return []*Entry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file: %w", err)
+	}
+	defer file.Close()
+
+	entries := []*Entry{}
+	reader := bufio.NewReader(file)
+	for {
+		// Lines have no length limit, entries written by older versions may hold large bodies
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var entry Entry
+			if json.Unmarshal(line, &entry) == nil {
+				entries = append(entries, &entry)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read history file: %w", err)
+		}
+	}
+	if len(entries) > MaxEntries {
+		entries = entries[len(entries)-MaxEntries:]
+	}
+
+	// Reverse to newest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+
+	return entries, nil
+}
+
+// Clear removes all history entries
+func Clear() error {
+	path, err := GetHistoryPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear history: %w", err)
+	}
+
+	return nil
+}
+
+// write replaces the history file with entries (newest first) as JSON lines, oldest first
+// Only the newest entries that fit in half of maxFileSize are kept
+func write(entries []*Entry) error {
+	path, err := GetHistoryPath()
+	if err != nil {
+		return err
+	}
+
+	var lines [][]byte
+	size := 0
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal history entry: %w", err)
+		}
+		if size += len(data) + 1; size > maxFileSize/2 && len(lines) > 0 {
+			break
+		}
+		lines = append(lines, data)
+	}
+
+	var buf bytes.Buffer
+	for i := len(lines) - 1; i >= 0; i-- {
+		buf.Write(lines[i])
+		buf.WriteByte('\n')
+	}
+
+	// Written aside then renamed, so a failure cannot lose the history
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+
+	return nil
+}
+
+// Format renders an entry as text, used for display and diffing
+func Format(e *Entry) string {
+	var s strings.Builder
+
+	if e.Request != nil {
+		s.WriteString(fmt.Sprintf("%s %s\n", e.Request.Method, e.Request.FullURL()))
+		for _, k := range sortedKeys(e.Request.Headers) {
+			s.WriteString(fmt.Sprintf("> %s: %s\n", k, e.Request.Headers[k]))
+		}
+		if e.Request.Body != "" {
+			s.WriteString(e.Request.Body + "\n")
+		}
+		if e.RequestBodyTruncated {
+			s.WriteString("[request body truncated]\n")
+		}
+		s.WriteString("\n")
+	}
+
+	if e.Error != "" {
+		s.WriteString(fmt.Sprintf("Error: %s\n", e.Error))
+		return s.String()
+	}
+
+	s.WriteString(fmt.Sprintf("Status: %s\n", e.Status))
+	headerKeys := make([]string, 0, len(e.Headers))
+	for k := range e.Headers {
+		headerKeys = append(headerKeys, k)
+	}
+	sort.Strings(headerKeys)
+	for _, k := range headerKeys {
+		for _, v := range e.Headers[k] {
+			s.WriteString(fmt.Sprintf("< %s: %s\n", k, v))
+		}
+	}
+	s.WriteString("\n")
+	if e.Binary {
+		s.WriteString(fmt.Sprintf("[%s of binary data]", executor.FormatSize(e.BodySize)))
+		return s.String()
+	}
+	s.WriteString(e.Body)
+	if e.BodyTruncated {
+		s.WriteString("\n[body truncated]")
+	}
+
+	return s.String()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,383 @@
+package insomnia
+
+import (
+	"github.com/leobrines/curlman/models"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// Export is an Insomnia v4 export file
+type Export struct {
+	Type      string     `json:"_type"`
+	Format    int        `json:"__export_format"`
+	Resources []Resource `json:"resources"`
+}
+
+// Resource is any exported object: workspace, request_group, request or environment
+type Resource struct {
+	ID          string                 `json:"_id"`
+	Type        string                 `json:"_type"`
+	ParentID    string                 `json:"parentId"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Method      string                 `json:"method"`
+	URL         string                 `json:"url"`
+	Body        Body                   `json:"body"`
+	Parameters  []Pair                 `json:"parameters"`
+	Headers     []Pair                 `json:"headers"`
+	Auth        *Authentication        `json:"authentication"`
+	Data        map[string]interface{} `json:"data"`
+	SortKey     float64                `json:"metaSortKey"`
+}
+
+// Authentication is the auth of a request or request group
+type Authentication struct {
+	Type     string `json:"type"`
+	Disabled bool   `json:"disabled"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Token    string `json:"token"`
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	AddTo    string `json:"addTo"` // API key location: header, queryParams or cookie
+
+	GrantType        string `json:"grantType"`
+	AccessTokenURL   string `json:"accessTokenUrl"`
+	AuthorizationURL string `json:"authorizationUrl"`
+	RedirectURL      string `json:"redirectUrl"`
+	ClientID         string `json:"clientId"`
+	ClientSecret     string `json:"clientSecret"`
+	Scope            string `json:"scope"`
+	RefreshToken     string `json:"refreshToken"`
+}
+
+// Body is a request body
+type Body struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Params   []Pair `json:"params"`
+	FileName string `json:"fileName"`
+}
+
+// Pair is a header, query parameter or form field
+type Pair struct {
+	Name     string `json:"name"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled"`
+	Type     string `json:"type"`
+	FileName string `json:"fileName"`
+}
+
+// templateTag matches Insomnia variables such as {{ _.base_url }} or {{base_url}}
+var templateTag = regexp.MustCompile(`\{\{\s*(?:_\.)?([A-Za-z0-9_.-]+)\s*\}\}`)
+
+// IsExport reports whether data looks like an Insomnia v4 export
+func IsExport(data []byte) bool {
+	var probe struct {
+		Type   string `json:"_type"`
+		Format int    `json:"__export_format"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return probe.Type == "export" && probe.Format > 0
+}
+
+// ImportFromFile imports an Insomnia v4 export file
+func ImportFromFile(filePath string) (*models.Collection, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Insomnia file: %w", err)
+	}
+	return Import(data)
+}
+
+// Import converts an Insomnia v4 export into a collection. The first workspace
+// is imported; its base environment becomes collection variables and its
+// sub environments become collection environments.
+func Import(data []byte) (*models.Collection, error) {
+	var export Export
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("failed to parse Insomnia export: %w", err)
+	}
+
+	if export.Format != 4 {
+		return nil, fmt.Errorf("unsupported Insomnia export format: %d", export.Format)
+	}
+
+	byID := make(map[string]*Resource)
+	var workspace *Resource
+	for i := range export.Resources {
+		r := &export.Resources[i]
+		byID[r.ID] = r
+		if r.Type == "workspace" && workspace == nil {
+			workspace = r
+		}
+	}
+	if workspace == nil {
+		return nil, fmt.Errorf("Insomnia export contains no workspace")
+	}
+
+	collection := &models.Collection{
+		Name:      workspace.Name,
+		Requests:  []*models.Request{},
+		Variables: make(map[string]string),
+	}
+
+	// Resources are exported in arbitrary order; keep Insomnia's sidebar order
+	resources := append([]Resource{}, export.Resources...)
+	sort.SliceStable(resources, func(i, j int) bool {
+		return resources[i].SortKey < resources[j].SortKey
+	})
+
+	for _, r := range resources {
+		switch r.Type {
+		case "environment":
+			if r.ParentID == workspace.ID {
+				flatten("", r.Data, collection.Variables)
+			} else if parent := byID[r.ParentID]; parent != nil && parent.ParentID == workspace.ID {
+				env := collection.AddCollectionEnvironment(r.Name)
+				flatten("", r.Data, env.Variables)
+			}
+		case "request_group":
+			if path, ok := folderPath(r, byID, workspace.ID); ok {
+				folder := collection.AddFolder(models.JoinFolderPath(path, folderName(r.Name)))
+				folder.Description = r.Description
+				folder.Auth = convertAuth(r.Auth)
+			}
+		case "request":
+			path, ok := folderPath(r, byID, workspace.ID)
+			if !ok {
+				continue
+			}
+			collection.Requests = append(collection.Requests, convertRequest(r, path))
+		}
+	}
+
+	return collection, nil
+}
+
+// folderPath returns the folder path of the request groups above a resource,
+// or false when the resource belongs to another workspace
+func folderPath(r Resource, byID map[string]*Resource, workspaceID string) (string, bool) {
+	path := ""
+	parentID := r.ParentID
+	for parentID != workspaceID {
+		parent := byID[parentID]
+		if parent == nil || parent.Type != "request_group" {
+			return "", false
+		}
+		path = models.JoinFolderPath(folderName(parent.Name), path)
+		parentID = parent.ParentID
+	}
+	return path, true
+}
+
+// folderName keeps slashes in request group names from creating nested folders
+func folderName(name string) string {
+	return strings.ReplaceAll(name, models.FolderSeparator, "-")
+}
+
+// convertRequest converts an Insomnia request into a Request
+func convertRequest(r Resource, folder string) *models.Request {
+	method := strings.ToUpper(r.Method)
+	if method == "" {
+		method = "GET"
+	}
+
+	request := &models.Request{
+		ID:          uuid.New().String(),
+		Name:        r.Name,
+		Method:      method,
+		Headers:     make(map[string]string),
+		QueryParams: make(map[string]string),
+		Description: r.Description,
+		Folder:      folder,
+	}
+
+	rawURL := convertTemplate(r.URL)
+	if i := strings.Index(rawURL, "?"); i >= 0 {
+		for _, pair := range strings.Split(rawURL[i+1:], "&") {
+			if pair == "" {
+				continue
+			}
+			key, value, _ := strings.Cut(pair, "=")
+			request.QueryParams[key] = value
+		}
+		rawURL = rawURL[:i]
+	}
+	request.URL, request.Path = splitURL(rawURL)
+
+	for _, p := range r.Parameters {
+		if !p.Disabled && p.Name != "" {
+			request.QueryParams[p.Name] = convertTemplate(p.Value)
+		}
+	}
+
+	for _, h := range r.Headers {
+		if !h.Disabled && h.Name != "" {
+			request.Headers[h.Name] = convertTemplate(h.Value)
+		}
+	}
+
+	applyBody(request, r.Body)
+	request.Auth = convertAuth(r.Auth)
+
+	// Same fallback as the OpenAPI importer when there is no name
+	if request.Name == "" {
+		request.Name = fmt.Sprintf("%s %s", method, request.Path)
+	}
+
+	return request
+}
+
+// applyBody fills the request body from the Insomnia body mime type
+func applyBody(request *models.Request, body Body) {
+	switch body.MimeType {
+	case "":
+		if body.FileName != "" {
+			request.BodyMode = models.BodyBinary
+			request.BodyFile = body.FileName
+		}
+		return
+
+	case "application/x-www-form-urlencoded":
+		request.BodyMode = models.BodyURLEncoded
+		for _, p := range body.Params {
+			if !p.Disabled && p.Name != "" {
+				request.FormFields = append(request.FormFields, models.FormField{Key: p.Name, Value: convertTemplate(p.Value), Type: models.FormFieldText})
+			}
+		}
+		return
+
+	case "multipart/form-data":
+		request.BodyMode = models.BodyFormData
+		for _, p := range body.Params {
+			if p.Disabled || p.Name == "" {
+				continue
+			}
+			if p.Type == "file" {
+				request.FormFields = append(request.FormFields, models.FormField{Key: p.Name, Value: p.FileName, Type: models.FormFieldFile})
+				continue
+			}
+			request.FormFields = append(request.FormFields, models.FormField{Key: p.Name, Value: convertTemplate(p.Value), Type: models.FormFieldText})
+		}
+		// The multipart boundary is generated when the request is sent
+		for key := range request.Headers {
+			if strings.EqualFold(key, "Content-Type") {
+				delete(request.Headers, key)
+			}
+		}
+		return
+
+	case "application/octet-stream":
+		if body.FileName != "" {
+			request.BodyMode = models.BodyBinary
+			request.BodyFile = body.FileName
+			break
+		}
+		request.Body = convertTemplate(body.Text)
+
+	default:
+		request.Body = convertTemplate(body.Text)
+		request.BodyLanguage = models.ContentTypeLanguage(body.MimeType)
+	}
+
+	if !request.HasHeader("Content-Type") {
+		request.Headers["Content-Type"] = body.MimeType
+	}
+}
+
+// convertAuth converts an Insomnia authentication; unsupported types fall back to inheriting
+func convertAuth(auth *Authentication) *models.Auth {
+	if auth == nil || auth.Type == "" {
+		return nil
+	}
+	if auth.Disabled || auth.Type == "none" {
+		return &models.Auth{Type: models.AuthNone}
+	}
+
+	switch auth.Type {
+	case "basic":
+		return &models.Auth{Type: models.AuthBasic, Username: convertTemplate(auth.Username), Password: convertTemplate(auth.Password)}
+	case "digest":
+		return &models.Auth{Type: models.AuthDigest, Username: convertTemplate(auth.Username), Password: convertTemplate(auth.Password)}
+	case "bearer":
+		return &models.Auth{Type: models.AuthBearer, Token: convertTemplate(auth.Token)}
+	case "apikey":
+		in := models.APIKeyInHeader
+		if auth.AddTo == "queryParams" {
+			in = models.APIKeyInQuery
+		}
+		return &models.Auth{Type: models.AuthAPIKey, Key: convertTemplate(auth.Key), Value: convertTemplate(auth.Value), In: in}
+	case "oauth2":
+		grant := auth.GrantType
+		switch grant {
+		case models.GrantClientCredentials, models.GrantPassword, models.GrantRefreshToken, models.GrantAuthorizationCode:
+		default:
+			// Implicit grants have no token endpoint to call
+			return nil
+		}
+		return &models.Auth{
+			Type:     models.AuthOAuth2,
+			Username: convertTemplate(auth.Username),
+			Password: convertTemplate(auth.Password),
+			OAuth2: &models.OAuth2{
+				Grant:        grant,
+				TokenURL:     convertTemplate(auth.AccessTokenURL),
+				AuthURL:      convertTemplate(auth.AuthorizationURL),
+				RedirectURL:  convertTemplate(auth.RedirectURL),
+				ClientID:     convertTemplate(auth.ClientID),
+				ClientSecret: convertTemplate(auth.ClientSecret),
+				Scope:        convertTemplate(auth.Scope),
+				RefreshToken: convertTemplate(auth.RefreshToken),
+			},
+		}
+	}
+	return nil
+}
+
+// convertTemplate rewrites Insomnia template tags into {{var}} placeholders
+func convertTemplate(text string) string {
+	return templateTag.ReplaceAllString(text, "{{$1}}")
+}
+
+// flatten copies environment data into variables, joining nested keys with dots
+func flatten(prefix string, data map[string]interface{}, variables map[string]string) {
+	for key, value := range data {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			flatten(name, v, variables)
+		case string:
+			variables[name] = convertTemplate(v)
+		case nil:
+			variables[name] = ""
+		default:
+			encoded, _ := json.Marshal(v)
+			variables[name] = string(encoded)
+		}
+	}
+}
+
+// splitURL separates the base URL (scheme and host) from the path
+func splitURL(raw string) (string, string) {
+	start := 0
+	if i := strings.Index(raw, "://"); i >= 0 {
+		start = i + 3
+	}
+	if i := strings.Index(raw[start:], "/"); i >= 0 {
+		return raw[:start+i], raw[start+i:]
+	}
+	return raw, ""
+}
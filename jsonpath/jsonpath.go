@@ -0,0 +1,44 @@
+package jsonpath
+
+import (
+	"fmt"
+)
+
+// Get evaluates an expression such as $.data.items[0].id against data decoded by Decode
+// The leading "$" is optional, so "data.items[0].id" is accepted as well
+// A definite path returns the value it selects, any other expression ($..id, filters, wildcards) the array of its matches
+func Get(data interface{}, path string) (interface{}, error) {
+	results, definite, err := evaluate(data, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no value matches '%s'", path)
+	}
+	if definite {
+		return results[0], nil
+	}
+	return results, nil
+}
+
+// GetFromJSON decodes a JSON document and evaluates path against it
+func GetFromJSON(body string, path string) (interface{}, error) {
+	data, err := Decode(body)
+	if err != nil {
+		return nil, err
+	}
+	return Get(data, path)
+}
+
+// ValueString converts a JSON value to its textual form
+// Strings are returned as-is, everything else is re-encoded as JSON
+func ValueString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
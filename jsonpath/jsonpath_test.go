@@ -0,0 +1,160 @@
+package jsonpath
+
+import (
+	"strings"
+	"testing"
+)
+
+const document = `{
+  "data": {
+    "id": 7,
+    "name": "Ann <admin>",
+    "big": 12345678901234567890,
+    "tags": ["a", "b"],
+    "items": [
+      {"id": 1, "name": "pen", "price": 2.5, "active": true},
+      {"id": 2, "name": "book", "price": 12, "active": false},
+      {"id": 3, "name": "ink", "price": 8, "active": true, "tags": ["blue"]}
+    ]
+  },
+  "first name": "Ann",
+  "empty": null
+}`
+
+func TestGetFromJSON(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"$.data.id", "7"},
+		{"data.id", "7"},
+		{".data.id", "7"},
+		{"$.data.name", "Ann <admin>"},
+		{"$.data.big", "12345678901234567890"},
+		{"$['first name']", "Ann"},
+		{"$.data.tags", `["a","b"]`},
+		{"$.data.items[0].name", "pen"},
+		{"$.data.items[-1].id", "3"},
+		{"$.data.items[1]", `{"id":2,"name":"book","price":12,"active":false}`},
+		{"$.empty", "null"},
+		{"", ""},
+		{"$..id", "[7,1,2,3]"},
+		{"$.data.items[*].name", `["pen","book","ink"]`},
+		{"$.data.items[?(@.price < 10)].name", `["pen","ink"]`},
+		{"$.data.items[?(@.id == 2)].name", `["book"]`},
+		{"$.data.items[?(@.active && @.tags)].id", "[3]"},
+		{"$.data.items[0,2].id", "[1,3]"},
+		{"$.data.items[1:].id", "[2,3]"},
+		{".data.items[] | select(.active == false) | .name", `["book"]`},
+		{".data.items | length", "[3]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			value, err := GetFromJSON(document, tt.path)
+			if err != nil {
+				t.Fatalf("GetFromJSON(%q): %v", tt.path, err)
+			}
+			got := ValueString(value)
+			if tt.path == "" {
+				if !strings.HasPrefix(got, `{"data":`) {
+					t.Errorf("GetFromJSON(\"\") = %s, want the whole document", got)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GetFromJSON(%q) = %s, want %s", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetFromJSONErrors(t *testing.T) {
+	tests := []struct {
+		body string
+		path string
+		want string
+	}{
+		{document, "$.missing", "no value matches"},
+		{document, "$.data.items[9]", "no value matches"},
+		{document, "$.data.id.x", "no value matches"},
+		{document, "$..nothing", "no value matches"},
+		{document, "$.data.items[?(@.price > 100)]", "no value matches"},
+		{document, "$.data.items[", "invalid expression"},
+		{`{"a": 1`, "$.a", "not valid JSON"},
+		{`{"a": 1} {}`, "$.a", "not valid JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, err := GetFromJSON(tt.body, tt.path)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("GetFromJSON(%q) error = %v, want %q", tt.path, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuery(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"$.data.items[?(@.name == 'ink')].price", "8"},
+		{"$.data.items[?(@.name != \"ink\")].id", "[\n  1,\n  2\n]"},
+		{"$.data.items[?(!@.active)].id", "2"},
+		{"$.data.items[?(@.price >= 8 || @.id == 1)].id", "[\n  1,\n  2,\n  3\n]"},
+		{"$.data.items[?(@.price > $.data.id)].name", "[\n  \"book\",\n  \"ink\"\n]"},
+		{"$.data.items[::-1].id", "[\n  3,\n  2,\n  1\n]"},
+		{"$.data.*", ""},
+		{"$..tags[0]", "[\n  \"a\",\n  \"blue\"\n]"},
+		{".data.items[] | .name", "[\n  \"pen\",\n  \"book\",\n  \"ink\"\n]"},
+		{".data | keys", "[\n  \"big\",\n  \"id\",\n  \"items\",\n  \"name\",\n  \"tags\"\n]"},
+		{".data.name | length", "11"},
+		{".data.items[] | select(.price < 10 and .active) | .id", "[\n  1,\n  3\n]"},
+		{"$.nothing", "[]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Filter(document, tt.expr)
+			if err != nil {
+				t.Fatalf("Filter(%q): %v", tt.expr, err)
+			}
+			if tt.want != "" && got != tt.want {
+				t.Errorf("Filter(%q) =\n%s\nwant\n%s", tt.expr, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	for _, expr := range []string{
+		"|",
+		".data |",
+		"| .data",
+		".data || .x",
+		"$.a[?(@ =~ /x/)]",
+		"$.data.items[?(@.name =~ 'p')]",
+		"$.data.items[?(@.id ==)]",
+		"$.data.items[?(@.id == 1]",
+		"$.data.items[?(@.id == 1)",
+		"$.data.items['id]",
+		"$.data.items[a]",
+		"$.data extra",
+		".data | length(1)",
+		"$.data.items[?(@.price < 1e)]",
+	} {
+		if results, err := Query(document, expr); err == nil {
+			t.Errorf("Query(%q) = %v, want an error", expr, results)
+		}
+	}
+}
+
+func TestObjectKeepsOrder(t *testing.T) {
+	got, err := Filter(`{"z": 1, "a": {"y": true, "b": null}}`, "$")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  \"z\": 1,\n  \"a\": {\n    \"y\": true,\n    \"b\": null\n  }\n}"
+	if got != want {
+		t.Errorf("Filter = %s, want %s", got, want)
+	}
+}
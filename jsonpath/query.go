@@ -0,0 +1,997 @@
+package jsonpath
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Object is a decoded JSON object keeping the order of its members
+type Object []Member
+
+// Member is a member of a JSON object
+type Member struct {
+	Key   string
+	Value interface{}
+}
+
+// Get returns the value of a member
+func (o Object) Get(key string) (interface{}, bool) {
+	for _, member := range o {
+		if member.Key == key {
+			return member.Value, true
+		}
+	}
+	return nil, false
+}
+
+// MarshalJSON encodes the object with its members in order
+func (o Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, member := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := marshal(member.Key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := marshal(member.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// marshal encodes a value as JSON without escaping HTML characters
+func marshal(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// Decode reads a JSON document into Object, []interface{}, string, json.Number, bool and nil values
+func Decode(body string) (interface{}, error) {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+	value, err := decodeValue(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("body is not valid JSON: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("body is not valid JSON: unexpected data after the document")
+	}
+	return value, nil
+}
+
+func decodeValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	switch delim {
+	case '{':
+		object := Object{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, Member{Key: key.(string), Value: value})
+		}
+		_, err = decoder.Token()
+		return object, err
+	case '[':
+		array := []interface{}{}
+		for decoder.More() {
+			value, err := decodeValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = decoder.Token()
+		return array, err
+	}
+	return nil, fmt.Errorf("unexpected %s", delim)
+}
+
+// Filter evaluates an expression against a JSON body and returns the matches as indented JSON
+func Filter(body, expr string) (string, error) {
+	results, err := Query(body, expr)
+	if err != nil {
+		return "", err
+	}
+	return Format(results)
+}
+
+// Format renders query results as indented JSON: the value itself for a single match, an array otherwise
+func Format(results []interface{}) (string, error) {
+	var output interface{} = results
+	if results == nil {
+		output = []interface{}{}
+	} else if len(results) == 1 {
+		output = results[0]
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(output); err != nil {
+		return "", fmt.Errorf("failed to encode filter result: %w", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// Query evaluates a JSONPath or jq-style expression against a JSON body and returns every match
+//
+// JSONPath: $.data.items[*].name, $..id, $.items[0,2], $.items[1:3], $.items[?(@.price < 10 && @.tags)]
+// jq-style, for expressions starting with a dot or using pipes:
+// .data.items[] | select(.active == true) | .name, and the length and keys functions
+func Query(body, expr string) ([]interface{}, error) {
+	data, err := Decode(body)
+	if err != nil {
+		return nil, err
+	}
+	return Evaluate(data, expr)
+}
+
+// Evaluate runs an expression against data decoded by Decode
+func Evaluate(data interface{}, expr string) ([]interface{}, error) {
+	results, _, err := evaluate(data, expr)
+	return results, err
+}
+
+// evaluate runs an expression, also reporting whether it is a definite path, one selecting a single value at most
+func evaluate(data interface{}, expr string) ([]interface{}, bool, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return []interface{}{data}, true, nil
+	}
+
+	if !isJQ(expr) {
+		if !strings.HasPrefix(expr, "$") {
+			// Bare paths like data.items are relative to the root
+			expr = "$." + strings.TrimPrefix(expr, ".")
+		}
+		p := &parser{text: expr}
+		p.pos++ // $
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, false, err
+		}
+		if err := p.end(); err != nil {
+			return nil, false, err
+		}
+		return path.eval(data, []interface{}{data}), path.definite(), nil
+	}
+
+	stages := splitPipes(expr)
+	results := []interface{}{data}
+	for _, stage := range stages {
+		var err error
+		results, err = evaluateStage(data, results, strings.TrimSpace(stage))
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	definite := false
+	if stage := strings.TrimSpace(stages[0]); len(stages) == 1 && strings.HasPrefix(stage, ".") {
+		p := &parser{text: stage}
+		path, _ := p.parsePath()
+		definite = path.definite()
+	}
+	return results, definite, nil
+}
+
+// isJQ reports whether an expression uses the jq-style syntax
+func isJQ(expr string) bool {
+	if strings.HasPrefix(expr, ".") || strings.HasPrefix(expr, "select(") || expr == "length" || expr == "keys" {
+		return true
+	}
+	return len(splitPipes(expr)) > 1
+}
+
+// splitPipes splits a jq-style expression on the pipes outside of strings, brackets and parentheses
+func splitPipes(expr string) []string {
+	var stages []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case c == '|' && depth == 0 && (i+1 >= len(expr) || expr[i+1] != '|') && (i == 0 || expr[i-1] != '|'):
+			stages = append(stages, expr[start:i])
+			start = i + 1
+		}
+	}
+	return append(stages, expr[start:])
+}
+
+// evaluateStage applies one stage of a jq-style pipeline to each input
+func evaluateStage(root interface{}, inputs []interface{}, stage string) ([]interface{}, error) {
+	var outputs []interface{}
+
+	switch {
+	case stage == ".":
+		return inputs, nil
+	case stage == "":
+		return nil, fmt.Errorf("empty filter stage")
+	case stage == "length":
+		for _, input := range inputs {
+			switch value := input.(type) {
+			case Object:
+				outputs = append(outputs, json.Number(strconv.Itoa(len(value))))
+			case []interface{}:
+				outputs = append(outputs, json.Number(strconv.Itoa(len(value))))
+			case string:
+				outputs = append(outputs, json.Number(strconv.Itoa(utf8.RuneCountInString(value))))
+			case nil:
+				outputs = append(outputs, json.Number("0"))
+			default:
+				return nil, fmt.Errorf("%s has no length", typeName(input))
+			}
+		}
+		return outputs, nil
+	case stage == "keys":
+		for _, input := range inputs {
+			switch value := input.(type) {
+			case Object:
+				keys := make([]string, 0, len(value))
+				for _, member := range value {
+					keys = append(keys, member.Key)
+				}
+				sort.Strings(keys)
+				list := make([]interface{}, len(keys))
+				for i, key := range keys {
+					list[i] = key
+				}
+				outputs = append(outputs, list)
+			case []interface{}:
+				list := make([]interface{}, len(value))
+				for i := range value {
+					list[i] = json.Number(strconv.Itoa(i))
+				}
+				outputs = append(outputs, list)
+			default:
+				return nil, fmt.Errorf("%s has no keys", typeName(input))
+			}
+		}
+		return outputs, nil
+	case strings.HasPrefix(stage, "select(") && strings.HasSuffix(stage, ")"):
+		p := &parser{text: stage[len("select(") : len(stage)-1]}
+		condition, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.end(); err != nil {
+			return nil, err
+		}
+		for _, input := range inputs {
+			if truthy(condition.eval(root, input)) {
+				outputs = append(outputs, input)
+			}
+		}
+		return outputs, nil
+	case strings.HasPrefix(stage, "."):
+		p := &parser{text: stage}
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.end(); err != nil {
+			return nil, err
+		}
+		return path.eval(root, inputs), nil
+	}
+
+	return nil, fmt.Errorf("unsupported filter: %s", stage)
+}
+
+// path is a sequence of steps selecting nodes
+type path []step
+
+// step selects children of each node, or of each node and its descendants when recursive
+type step struct {
+	recursive bool
+	selectors []selector
+}
+
+type selector struct {
+	wildcard bool
+	key      *string
+	index    *int
+	slice    *[3]*int // start, end, step
+	filter   expression
+}
+
+// definite reports whether the path only names members and single indexes
+func (p path) definite() bool {
+	for _, s := range p {
+		if s.recursive || len(s.selectors) != 1 || s.selectors[0].key == nil && s.selectors[0].index == nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (p path) eval(root interface{}, nodes []interface{}) []interface{} {
+	for _, s := range p {
+		if s.recursive {
+			nodes = descendants(nodes)
+		}
+		var next []interface{}
+		for _, node := range nodes {
+			for _, sel := range s.selectors {
+				next = append(next, sel.apply(root, node)...)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+// descendants returns the nodes and everything below them, depth first
+func descendants(nodes []interface{}) []interface{} {
+	var all []interface{}
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		all = append(all, node)
+		switch value := node.(type) {
+		case Object:
+			for _, member := range value {
+				walk(member.Value)
+			}
+		case []interface{}:
+			for _, element := range value {
+				walk(element)
+			}
+		}
+	}
+	for _, node := range nodes {
+		walk(node)
+	}
+	return all
+}
+
+func (s selector) apply(root, node interface{}) []interface{} {
+	var children []interface{}
+	switch value := node.(type) {
+	case Object:
+		for _, member := range value {
+			children = append(children, member.Value)
+		}
+	case []interface{}:
+		children = value
+	}
+
+	switch {
+	case s.wildcard:
+		return children
+	case s.key != nil:
+		if object, ok := node.(Object); ok {
+			if value, found := object.Get(*s.key); found {
+				return []interface{}{value}
+			}
+		}
+	case s.index != nil:
+		if array, ok := node.([]interface{}); ok {
+			index := *s.index
+			if index < 0 {
+				index += len(array)
+			}
+			if index >= 0 && index < len(array) {
+				return []interface{}{array[index]}
+			}
+		}
+	case s.slice != nil:
+		if array, ok := node.([]interface{}); ok {
+			return sliceArray(array, s.slice)
+		}
+	case s.filter != nil:
+		var matches []interface{}
+		for _, child := range children {
+			if truthy(s.filter.eval(root, child)) {
+				matches = append(matches, child)
+			}
+		}
+		return matches
+	}
+	return nil
+}
+
+func sliceArray(array []interface{}, bounds *[3]*int) []interface{} {
+	n := len(array)
+	step := 1
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	if step == 0 {
+		return nil
+	}
+
+	normalize := func(bound *int, fallback int) int {
+		if bound == nil {
+			return fallback
+		}
+		i := *bound
+		if i < 0 {
+			i += n
+		}
+		return i
+	}
+
+	var result []interface{}
+	if step > 0 {
+		start, end := max(normalize(bounds[0], 0), 0), min(normalize(bounds[1], n), n)
+		for i := start; i < end; i += step {
+			result = append(result, array[i])
+		}
+	} else {
+		start, end := min(normalize(bounds[0], n-1), n-1), max(normalize(bounds[1], -n-1), -1)
+		for i := start; i > end; i += step {
+			result = append(result, array[i])
+		}
+	}
+	return result
+}
+
+// expression is a filter expression; it evaluates to the nodes it selects or to a literal
+type expression interface {
+	eval(root, current interface{}) []interface{}
+}
+
+type pathExpr struct {
+	absolute bool // $ instead of @
+	path     path
+}
+
+func (e pathExpr) eval(root, current interface{}) []interface{} {
+	start := current
+	if e.absolute {
+		start = root
+	}
+	return e.path.eval(root, []interface{}{start})
+}
+
+type literal struct {
+	value interface{}
+}
+
+func (e literal) eval(root, current interface{}) []interface{} {
+	return []interface{}{e.value}
+}
+
+type notExpr struct {
+	operand expression
+}
+
+func (e notExpr) eval(root, current interface{}) []interface{} {
+	return []interface{}{!truthy(e.operand.eval(root, current))}
+}
+
+type logicalExpr struct {
+	and         bool
+	left, right expression
+}
+
+func (e logicalExpr) eval(root, current interface{}) []interface{} {
+	left := truthy(e.left.eval(root, current))
+	if e.and {
+		return []interface{}{left && truthy(e.right.eval(root, current))}
+	}
+	return []interface{}{left || truthy(e.right.eval(root, current))}
+}
+
+type comparison struct {
+	op          string
+	left, right expression
+}
+
+func (e comparison) eval(root, current interface{}) []interface{} {
+	left := e.left.eval(root, current)
+	if len(left) == 0 {
+		return []interface{}{false}
+	}
+
+	right := e.right.eval(root, current)
+	if len(right) == 0 {
+		return []interface{}{false}
+	}
+	return []interface{}{compareValues(left[0], right[0], e.op)}
+}
+
+// compareValues compares numbers numerically, strings lexically and other values for equality only
+func compareValues(a, b interface{}, op string) bool {
+	if x, ok := number(a); ok {
+		if y, ok := number(b); ok {
+			switch op {
+			case "==":
+				return x == y
+			case "!=":
+				return x != y
+			case "<":
+				return x < y
+			case "<=":
+				return x <= y
+			case ">":
+				return x > y
+			case ">=":
+				return x >= y
+			}
+		}
+	}
+
+	if x, ok := a.(string); ok {
+		if y, ok := b.(string); ok {
+			switch op {
+			case "==":
+				return x == y
+			case "!=":
+				return x != y
+			case "<":
+				return x < y
+			case "<=":
+				return x <= y
+			case ">":
+				return x > y
+			case ">=":
+				return x >= y
+			}
+		}
+	}
+
+	equal := ValueString(a) == ValueString(b) && typeName(a) == typeName(b)
+	switch op {
+	case "==":
+		return equal
+	case "!=":
+		return !equal
+	}
+	return false
+}
+
+func number(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// truthy reports whether a filter result holds: something was selected and it is not false or null
+func truthy(values []interface{}) bool {
+	if len(values) == 0 {
+		return false
+	}
+	switch value := values[0].(type) {
+	case nil:
+		return false
+	case bool:
+		return value
+	}
+	return true
+}
+
+func typeName(value interface{}) string {
+	switch value.(type) {
+	case Object, map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case json.Number, float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
+
+// parser reads paths and filter expressions
+type parser struct {
+	text string
+	pos  int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid expression at position %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipSpaces() {
+	for p.pos < len(p.text) && (p.text[p.pos] == ' ' || p.text[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *parser) peek(prefix string) bool {
+	return strings.HasPrefix(p.text[p.pos:], prefix)
+}
+
+func (p *parser) end() error {
+	p.skipSpaces()
+	if p.pos < len(p.text) {
+		return p.errorf("unexpected %q", p.text[p.pos:])
+	}
+	return nil
+}
+
+// parsePath reads steps like .name, ..name, .*, [0], ['key'], [1:3], [*], [] and [?(...)]
+func (p *parser) parsePath() (path, error) {
+	var steps path
+	for p.pos < len(p.text) {
+		switch {
+		case p.peek(".."):
+			p.pos += 2
+			s, err := p.parseDotStep()
+			if err != nil {
+				return nil, err
+			}
+			s.recursive = true
+			steps = append(steps, s)
+		case p.peek("."):
+			p.pos++
+			if p.pos >= len(p.text) || strings.ContainsRune(" \t|)&=!<>,", rune(p.text[p.pos])) {
+				// A lone dot is the current node
+				continue
+			}
+			s, err := p.parseDotStep()
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, s)
+		case p.peek("["):
+			s, err := p.parseBracket()
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, s)
+		default:
+			return steps, nil
+		}
+		// jq's optional marker
+		if p.peek("?") && !p.peek("?(") {
+			p.pos++
+		}
+	}
+	return steps, nil
+}
+
+// parseDotStep reads what follows a dot: a name, * or a bracket
+func (p *parser) parseDotStep() (step, error) {
+	if p.peek("*") {
+		p.pos++
+		return step{selectors: []selector{{wildcard: true}}}, nil
+	}
+	if p.peek("[") {
+		return p.parseBracket()
+	}
+	if p.peek(`"`) {
+		key, err := p.parseString()
+		if err != nil {
+			return step{}, err
+		}
+		return step{selectors: []selector{{key: &key}}}, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.text) && !strings.ContainsRune(".[]()=!<>&|,?~ \t", rune(p.text[p.pos])) {
+		p.pos++
+	}
+	if start == p.pos {
+		return step{}, p.errorf("expected a member name")
+	}
+	key := p.text[start:p.pos]
+	return step{selectors: []selector{{key: &key}}}, nil
+}
+
+// parseBracket reads [...]: wildcards, unions of names, indexes and slices, or a filter
+func (p *parser) parseBracket() (step, error) {
+	p.pos++ // [
+	p.skipSpaces()
+
+	if p.peek("]") {
+		// jq's .[] iterates like [*]
+		p.pos++
+		return step{selectors: []selector{{wildcard: true}}}, nil
+	}
+
+	if p.peek("?") {
+		p.pos++
+		p.skipSpaces()
+		filter, err := p.parseOr()
+		if err != nil {
+			return step{}, err
+		}
+		p.skipSpaces()
+		if !p.peek("]") {
+			return step{}, p.errorf("expected ]")
+		}
+		p.pos++
+		return step{selectors: []selector{{filter: filter}}}, nil
+	}
+
+	var selectors []selector
+	for {
+		p.skipSpaces()
+		switch {
+		case p.peek("*"):
+			p.pos++
+			selectors = append(selectors, selector{wildcard: true})
+		case p.peek("'") || p.peek(`"`):
+			key, err := p.parseString()
+			if err != nil {
+				return step{}, err
+			}
+			selectors = append(selectors, selector{key: &key})
+		default:
+			sel, err := p.parseIndexOrSlice()
+			if err != nil {
+				return step{}, err
+			}
+			selectors = append(selectors, sel)
+		}
+
+		p.skipSpaces()
+		if p.peek(",") {
+			p.pos++
+			continue
+		}
+		if p.peek("]") {
+			p.pos++
+			return step{selectors: selectors}, nil
+		}
+		return step{}, p.errorf("expected , or ]")
+	}
+}
+
+func (p *parser) parseIndexOrSlice() (selector, error) {
+	var bounds [3]*int
+	part := 0
+	isSlice := false
+	for {
+		p.skipSpaces()
+		start := p.pos
+		if p.peek("-") {
+			p.pos++
+		}
+		for p.pos < len(p.text) && p.text[p.pos] >= '0' && p.text[p.pos] <= '9' {
+			p.pos++
+		}
+		if p.pos > start {
+			n, err := strconv.Atoi(p.text[start:p.pos])
+			if err != nil {
+				return selector{}, p.errorf("invalid index %q", p.text[start:p.pos])
+			}
+			bounds[part] = &n
+		}
+		p.skipSpaces()
+		if !p.peek(":") || part == 2 {
+			break
+		}
+		p.pos++
+		part++
+		isSlice = true
+	}
+
+	if isSlice {
+		return selector{slice: &bounds}, nil
+	}
+	if bounds[0] == nil {
+		return selector{}, p.errorf("expected an index, a name or *")
+	}
+	return selector{index: bounds[0]}, nil
+}
+
+// parseString reads a single or double quoted string
+func (p *parser) parseString() (string, error) {
+	quote := p.text[p.pos]
+	var s strings.Builder
+	for i := p.pos + 1; i < len(p.text); i++ {
+		c := p.text[i]
+		if c == '\\' && i+1 < len(p.text) {
+			i++
+			s.WriteByte(p.text[i])
+			continue
+		}
+		if c == quote {
+			p.pos = i + 1
+			return s.String(), nil
+		}
+		s.WriteByte(c)
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) parseOr() (expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		switch {
+		case p.peek("||"):
+			p.pos += 2
+		case p.peek("or "):
+			p.pos += 3
+		default:
+			return left, nil
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		switch {
+		case p.peek("&&"):
+			p.pos += 2
+		case p.peek("and "):
+			p.pos += 4
+		default:
+			return left, nil
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr{and: true, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (expression, error) {
+	p.skipSpaces()
+	if p.peek("!") && !p.peek("!=") {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{operand: operand}, nil
+	}
+	if p.peek("(") {
+		p.pos++
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if !p.peek(")") {
+			return nil, p.errorf("expected )")
+		}
+		p.pos++
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (expression, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	if p.peek("=~") {
+		return nil, p.errorf("regular expression matching (=~) is not supported")
+	}
+	var op string
+	for _, candidate := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.peek(candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return left, nil
+	}
+	p.pos += len(op)
+	p.skipSpaces()
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return comparison{op: op, left: left, right: right}, nil
+}
+
+// parseOperand reads @path, $path, .path (jq), a string, a number, true, false or null
+func (p *parser) parseOperand() (expression, error) {
+	p.skipSpaces()
+	switch {
+	case p.peek("@") || p.peek("$"):
+		absolute := p.peek("$")
+		p.pos++
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		return pathExpr{absolute: absolute, path: path}, nil
+	case p.peek("."):
+		path, err := p.parsePath()
+		if err != nil {
+			return nil, err
+		}
+		return pathExpr{path: path}, nil
+	case p.peek("'") || p.peek(`"`):
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return literal{value: s}, nil
+	case p.peek("true"):
+		p.pos += 4
+		return literal{value: true}, nil
+	case p.peek("false"):
+		p.pos += 5
+		return literal{value: false}, nil
+	case p.peek("null"):
+		p.pos += 4
+		return literal{value: nil}, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.text) && strings.ContainsRune("-+.0123456789eE", rune(p.text[p.pos])) {
+		p.pos++
+	}
+	if start == p.pos {
+		if p.pos >= len(p.text) {
+			return nil, p.errorf("unexpected end of expression")
+		}
+		return nil, p.errorf("unexpected %q", p.text[p.pos:])
+	}
+	n := json.Number(p.text[start:p.pos])
+	if _, err := n.Float64(); err != nil {
+		return nil, p.errorf("invalid number %q", string(n))
+	}
+	return literal{value: n}, nil
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/leobrines/curlman/cli"
 	"github.com/leobrines/curlman/ui"
 	"fmt"
 	"os"
@@ -9,6 +10,11 @@ import (
 )
 
 func main() {
+	// Any arguments select a headless subcommand instead of the TUI
+	if len(os.Args) > 1 {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
 	p := tea.NewProgram(ui.NewModel(), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running program: %v\n", err)
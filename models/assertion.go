@@ -0,0 +1,93 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Assertion types
+const (
+	AssertStatus   = "status"
+	AssertHeader   = "header"
+	AssertJSONPath = "json"
+	AssertBody     = "body"
+	AssertDuration = "duration"
+)
+
+// Assertion operators
+const (
+	OpEquals   = "equals"
+	OpExists   = "exists"
+	OpContains = "contains"
+	OpMatches  = "matches"
+	OpMax      = "max"
+)
+
+// Assertion describes a check performed against the response of a request
+type Assertion struct {
+	Type     string `json:"type"`               // status, header, json, body or duration
+	Property string `json:"property,omitempty"` // Header name or JSON path
+	Operator string `json:"operator"`           // equals, exists, contains, matches or max
+	Expected string `json:"expected,omitempty"`
+}
+
+// String returns the assertion in the same form accepted by the assertion parser
+func (a Assertion) String() string {
+	parts := []string{a.Type}
+	if a.Property != "" {
+		parts = append(parts, a.Property)
+	}
+	parts = append(parts, a.Operator)
+	if a.Operator != OpExists {
+		parts = append(parts, quoteExpected(a.Expected))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteExpected quotes values the assertion parser would otherwise read differently:
+// empty ones, ones with surrounding spaces and ones already in quotes
+func quoteExpected(value string) string {
+	if value == "" || value != strings.TrimSpace(value) ||
+		(len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0]) {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+// Validate checks that the assertion type and operator are compatible
+func (a Assertion) Validate() error {
+	allowed := map[string][]string{
+		AssertStatus:   {OpEquals},
+		AssertHeader:   {OpExists, OpEquals, OpContains, OpMatches},
+		AssertJSONPath: {OpExists, OpEquals, OpContains, OpMatches},
+		AssertBody:     {OpEquals, OpContains, OpMatches},
+		AssertDuration: {OpMax},
+	}
+
+	operators, ok := allowed[a.Type]
+	if !ok {
+		return fmt.Errorf("unknown assertion type: %s", a.Type)
+	}
+
+	valid := false
+	for _, op := range operators {
+		if op == a.Operator {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("operator '%s' is not supported for %s assertions (use %s)", a.Operator, a.Type, strings.Join(operators, ", "))
+	}
+
+	if (a.Type == AssertHeader || a.Type == AssertJSONPath) && a.Property == "" {
+		return fmt.Errorf("%s assertions need a property", a.Type)
+	}
+
+	// Header, JSON and body values may be empty, written "" by the assertion parser
+	if a.Expected == "" && (a.Type == AssertStatus || a.Type == AssertDuration) {
+		return fmt.Errorf("%s assertions need an expected value", a.Type)
+	}
+
+	return nil
+}
@@ -0,0 +1,353 @@
+package models
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Auth types
+const (
+	AuthInherit = ""       // Use the auth of the enclosing folder or collection
+	AuthNone    = "none"   // Send no credentials, even when a parent defines some
+	AuthBasic   = "basic"  // Username and password in an Authorization header
+	AuthBearer  = "bearer" // Token in an Authorization header
+	AuthAPIKey  = "apikey" // Key/value pair in a header or the query string
+	AuthDigest  = "digest" // Username and password answering a Digest challenge
+	AuthOAuth2  = "oauth2" // Access token obtained from a token endpoint, sent as a Bearer token
+)
+
+// OAuth2 grant types
+const (
+	GrantClientCredentials = "client_credentials"
+	GrantPassword          = "password" // Uses Auth.Username and Auth.Password
+	GrantRefreshToken      = "refresh_token"
+	GrantAuthorizationCode = "authorization_code" // With PKCE, through a localhost callback
+)
+
+// Locations of an API key
+const (
+	APIKeyInHeader = "header"
+	APIKeyInQuery  = "query"
+)
+
+// Auth holds the credentials of a request, folder or collection
+// Every value may reference {{variables}}
+type Auth struct {
+	Type     string  `json:"type"`
+	Username string  `json:"username,omitempty"`
+	Password string  `json:"password,omitempty"`
+	Token    string  `json:"token,omitempty"`
+	Key      string  `json:"key,omitempty"`   // API key header or query parameter name
+	Value    string  `json:"value,omitempty"` // API key value
+	In       string  `json:"in,omitempty"`    // API key location: header (default) or query
+	OAuth2   *OAuth2 `json:"oauth2,omitempty"`
+}
+
+// OAuth2 configures how an OAuth2 access token is obtained
+type OAuth2 struct {
+	Grant        string `json:"grant"`
+	TokenURL     string `json:"token_url"`
+	AuthURL      string `json:"auth_url,omitempty"`     // Authorization endpoint of the authorization code grant
+	RedirectURL  string `json:"redirect_url,omitempty"` // Localhost callback of the authorization code grant
+	ClientID     string `json:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"` // Token exchanged by the refresh_token grant
+}
+
+// oauth2Params lists the settings of an OAuth2 auth in the order of its definition
+func (a *Auth) oauth2Params() [][2]string {
+	o := a.OAuth2
+	if o == nil {
+		o = &OAuth2{}
+	}
+	return [][2]string{
+		{"token_url", o.TokenURL},
+		{"auth_url", o.AuthURL},
+		{"redirect_url", o.RedirectURL},
+		{"client_id", o.ClientID},
+		{"client_secret", o.ClientSecret},
+		{"scope", o.Scope},
+		{"refresh_token", o.RefreshToken},
+		{"username", a.Username},
+		{"password", a.Password},
+	}
+}
+
+// IsInherited reports whether the auth defers to the enclosing folder or collection
+func (a *Auth) IsInherited() bool {
+	return a == nil || a.Type == AuthInherit
+}
+
+// IsSet reports whether the auth sends credentials
+func (a *Auth) IsSet() bool {
+	return a != nil && a.Type != AuthInherit && a.Type != AuthNone
+}
+
+// Clone returns a copy of the auth
+func (a *Auth) Clone() *Auth {
+	if a == nil {
+		return nil
+	}
+	clone := *a
+	if a.OAuth2 != nil {
+		oauth2 := *a.OAuth2
+		clone.OAuth2 = &oauth2
+	}
+	return &clone
+}
+
+// InjectVariables returns a copy of the auth with its {{ }} placeholders rendered
+func (a *Auth) InjectVariables(variables map[string]string) (*Auth, error) {
+	t := &transformer{fn: renderer(variables)}
+	injected := a.Clone()
+	injected.transform(t)
+	if t.err != nil {
+		return nil, t.err
+	}
+	return injected, nil
+}
+
+// transform applies a transformer to the credentials, in place
+func (a *Auth) transform(t *transformer) {
+	if a == nil {
+		return
+	}
+	t.apply("username", &a.Username)
+	t.apply("password", &a.Password)
+	t.apply("token", &a.Token)
+	t.apply("API key name", &a.Key)
+	t.apply("API key value", &a.Value)
+	if o := a.OAuth2; o != nil {
+		t.apply("OAuth2 token_url", &o.TokenURL)
+		t.apply("OAuth2 auth_url", &o.AuthURL)
+		t.apply("OAuth2 redirect_url", &o.RedirectURL)
+		t.apply("OAuth2 client_id", &o.ClientID)
+		t.apply("OAuth2 client_secret", &o.ClientSecret)
+		t.apply("OAuth2 scope", &o.Scope)
+		t.apply("OAuth2 refresh_token", &o.RefreshToken)
+	}
+}
+
+// Header returns the header carrying the credentials, if the auth type uses one
+// Digest auth has no header until the server sends its challenge
+func (a *Auth) Header() (name, value string, ok bool) {
+	if a == nil {
+		return "", "", false
+	}
+	switch a.Type {
+	case AuthBasic:
+		return "Authorization", "Basic " + base64.StdEncoding.EncodeToString([]byte(a.Username+":"+a.Password)), true
+	case AuthBearer:
+		return "Authorization", "Bearer " + a.Token, true
+	case AuthAPIKey:
+		if a.In != APIKeyInQuery {
+			return a.Key, a.Value, true
+		}
+	}
+	return "", "", false
+}
+
+// HeaderName returns the name of the header the auth controls, or ""
+// An explicit request header with this name is replaced by the auth
+func (a *Auth) HeaderName() string {
+	if a == nil {
+		return ""
+	}
+	switch a.Type {
+	case AuthBasic, AuthBearer, AuthDigest, AuthOAuth2:
+		return "Authorization"
+	case AuthAPIKey:
+		if a.In != APIKeyInQuery {
+			return a.Key
+		}
+	}
+	return ""
+}
+
+// ApplyToURL appends an API key to the query string of a URL when the auth sends it there
+func (a *Auth) ApplyToURL(rawURL string) string {
+	if a == nil || a.Type != AuthAPIKey || a.In != APIKeyInQuery {
+		return rawURL
+	}
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+	return rawURL + separator + url.QueryEscape(a.Key) + "=" + url.QueryEscape(a.Value)
+}
+
+// String renders the auth in the syntax read by ParseAuth
+func (a *Auth) String() string {
+	if a == nil {
+		return "inherit"
+	}
+	switch a.Type {
+	case AuthNone:
+		return "none"
+	case AuthBasic, AuthDigest:
+		return fmt.Sprintf("%s %s %s", a.Type, a.Username, a.Password)
+	case AuthBearer:
+		return "bearer " + a.Token
+	case AuthAPIKey:
+		in := a.In
+		if in == "" {
+			in = APIKeyInHeader
+		}
+		return fmt.Sprintf("apikey %s %s %s", in, a.Key, a.Value)
+	case AuthOAuth2:
+		parts := []string{"oauth2"}
+		if a.OAuth2 != nil {
+			parts = append(parts, a.OAuth2.Grant)
+		}
+		for _, param := range a.oauth2Params() {
+			if param[1] == "" {
+				continue
+			}
+			value := param[1]
+			if strings.ContainsAny(value, " \t\"") {
+				value = strconv.Quote(value)
+			}
+			parts = append(parts, param[0]+"="+value)
+		}
+		return strings.Join(parts, " ")
+	}
+	return "inherit"
+}
+
+// ParseAuth parses an auth definition:
+//
+//	inherit | none
+//	basic <username> <password>
+//	digest <username> <password>
+//	bearer <token>
+//	apikey header|query <name> <value>
+//	oauth2 <grant> token_url=<url> [client_id=...] [client_secret=...] [scope="a b"] ...
+func ParseAuth(text string) (*Auth, error) {
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return &Auth{Type: AuthInherit}, nil
+	}
+
+	// The last value keeps its spaces
+	rest := func(n int) string {
+		value := strings.TrimSpace(text)
+		for i := 0; i < n; i++ {
+			value = strings.TrimSpace(value[len(fields[i]):])
+		}
+		return value
+	}
+
+	switch kind := strings.ToLower(fields[0]); kind {
+	case "inherit":
+		return &Auth{Type: AuthInherit}, nil
+	case AuthNone:
+		return &Auth{Type: AuthNone}, nil
+	case AuthBasic, AuthDigest:
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s auth needs a username and a password", kind)
+		}
+		password := ""
+		if len(fields) > 2 {
+			password = rest(2)
+		}
+		return &Auth{Type: kind, Username: fields[1], Password: password}, nil
+	case AuthBearer:
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("bearer auth needs a token")
+		}
+		return &Auth{Type: AuthBearer, Token: rest(1)}, nil
+	case AuthAPIKey:
+		if len(fields) < 4 {
+			return nil, fmt.Errorf("apikey auth needs a location (header or query), a name and a value")
+		}
+		in := strings.ToLower(fields[1])
+		if in != APIKeyInHeader && in != APIKeyInQuery {
+			return nil, fmt.Errorf("invalid API key location: %s (use header or query)", fields[1])
+		}
+		return &Auth{Type: AuthAPIKey, In: in, Key: fields[2], Value: rest(3)}, nil
+	case AuthOAuth2:
+		return parseOAuth2(rest(1))
+	}
+
+	return nil, fmt.Errorf("unknown auth type: %s (use inherit, none, basic, bearer, apikey, digest or oauth2)", fields[0])
+}
+
+// parseOAuth2 reads "<grant> key=value ..." where values may be double quoted
+func parseOAuth2(text string) (*Auth, error) {
+	grant, params, _ := strings.Cut(strings.TrimSpace(text), " ")
+	switch grant {
+	case GrantClientCredentials, GrantPassword, GrantRefreshToken, GrantAuthorizationCode:
+	default:
+		return nil, fmt.Errorf("invalid OAuth2 grant: %s (use client_credentials, password, refresh_token or authorization_code)", grant)
+	}
+
+	auth := &Auth{Type: AuthOAuth2, OAuth2: &OAuth2{Grant: grant}}
+	settings := map[string]*string{
+		"token_url":     &auth.OAuth2.TokenURL,
+		"auth_url":      &auth.OAuth2.AuthURL,
+		"redirect_url":  &auth.OAuth2.RedirectURL,
+		"client_id":     &auth.OAuth2.ClientID,
+		"client_secret": &auth.OAuth2.ClientSecret,
+		"scope":         &auth.OAuth2.Scope,
+		"refresh_token": &auth.OAuth2.RefreshToken,
+		"username":      &auth.Username,
+		"password":      &auth.Password,
+	}
+
+	for params = strings.TrimSpace(params); params != ""; params = strings.TrimSpace(params) {
+		key, rest, ok := strings.Cut(params, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid OAuth2 setting: %s (use key=value)", params)
+		}
+		target, known := settings[key]
+		if !known {
+			return nil, fmt.Errorf("unknown OAuth2 setting: %s", key)
+		}
+
+		value := rest
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value for %s", key)
+			}
+			value, _ = strconv.Unquote(quoted)
+			rest = rest[len(quoted):]
+		} else {
+			value, rest, _ = strings.Cut(rest, " ")
+		}
+
+		*target = value
+		params = rest
+	}
+
+	if auth.OAuth2.TokenURL == "" {
+		return nil, fmt.Errorf("OAuth2 auth needs a token_url")
+	}
+	if grant == GrantAuthorizationCode && auth.OAuth2.AuthURL == "" {
+		return nil, fmt.Errorf("the authorization_code grant needs an auth_url")
+	}
+	return auth, nil
+}
+
+// EffectiveAuth returns the auth used by a request: its own, or the first one
+// defined by its folders (innermost first) or the collection
+// It returns nil when no credentials apply
+func (c *Collection) EffectiveAuth(request *Request) *Auth {
+	auth := request.Auth
+	for folder := request.Folder; auth.IsInherited() && folder != ""; folder = FolderParent(folder) {
+		if f := c.GetFolder(folder); f != nil {
+			auth = f.Auth
+		}
+	}
+	if auth.IsInherited() {
+		auth = c.Auth
+	}
+	if !auth.IsSet() {
+		return nil
+	}
+	return auth
+}
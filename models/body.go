@@ -0,0 +1,123 @@
+package models
+
+import (
+	"strings"
+)
+
+// Body modes of a request
+const (
+	BodyRaw        = "raw"        // Request.Body sent as-is, optionally tagged with a language
+	BodyURLEncoded = "urlencoded" // FormFields sent as application/x-www-form-urlencoded
+	BodyFormData   = "formdata"   // FormFields sent as multipart/form-data, with file parts read from disk
+	BodyBinary     = "binary"     // The contents of BodyFile
+)
+
+// Form field types
+const (
+	FormFieldText = "text"
+	FormFieldFile = "file"
+)
+
+// FormField is a key/value pair of a urlencoded or multipart body
+// For file fields Value is the path of the file to upload
+type FormField struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+	Type  string `json:"type,omitempty"`
+}
+
+// IsFile reports whether the field uploads a file
+func (f FormField) IsFile() bool {
+	return f.Type == FormFieldFile
+}
+
+// String renders the field like curl's -F syntax: key=value, or key=@path for files
+func (f FormField) String() string {
+	if f.IsFile() {
+		return f.Key + "=@" + f.Value
+	}
+	return f.Key + "=" + f.Value
+}
+
+// ParseFormField parses "key=value" or "key=@path" (a file field)
+func ParseFormField(text string) (FormField, bool) {
+	key, value, ok := strings.Cut(text, "=")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return FormField{}, false
+	}
+	if strings.HasPrefix(value, "@") {
+		return FormField{Key: key, Value: strings.TrimPrefix(value, "@"), Type: FormFieldFile}, true
+	}
+	return FormField{Key: key, Value: value, Type: FormFieldText}, true
+}
+
+// GetBodyMode returns the body mode, defaulting to raw
+func (r *Request) GetBodyMode() string {
+	if r.BodyMode == "" {
+		return BodyRaw
+	}
+	return r.BodyMode
+}
+
+// HasBody reports whether the request sends a body in its current mode
+func (r *Request) HasBody() bool {
+	switch r.GetBodyMode() {
+	case BodyURLEncoded, BodyFormData:
+		return len(r.FormFields) > 0
+	case BodyBinary:
+		return r.BodyFile != ""
+	}
+	return r.Body != ""
+}
+
+// LanguageContentType maps a raw body language to its Content-Type
+func LanguageContentType(language string) string {
+	switch strings.ToLower(language) {
+	case "json":
+		return "application/json"
+	case "xml":
+		return "application/xml"
+	case "html":
+		return "text/html"
+	case "javascript":
+		return "application/javascript"
+	case "text":
+		return "text/plain"
+	}
+	return ""
+}
+
+// ContentTypeLanguage maps a Content-Type to a raw body language
+func ContentTypeLanguage(contentType string) string {
+	contentType = strings.ToLower(contentType)
+	switch {
+	case strings.Contains(contentType, "json"):
+		return "json"
+	case strings.Contains(contentType, "xml"):
+		return "xml"
+	case strings.Contains(contentType, "html"):
+		return "html"
+	case strings.Contains(contentType, "javascript"):
+		return "javascript"
+	case strings.HasPrefix(contentType, "text/"):
+		return "text"
+	}
+	return ""
+}
+
+// GetHeader returns a header value regardless of the case of its name
+func (r *Request) GetHeader(name string) (string, bool) {
+	for key, value := range r.Headers {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// HasHeader reports whether a header is set regardless of the case of its name
+func (r *Request) HasHeader(name string) bool {
+	_, ok := r.GetHeader(name)
+	return ok
+}
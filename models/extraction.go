@@ -0,0 +1,63 @@
+package models
+
+import (
+	"fmt"
+)
+
+// Extraction sources
+const (
+	ExtractJSONPath = "json"
+	ExtractHeader   = "header"
+	ExtractRegex    = "regex"
+)
+
+// Extraction scopes
+const (
+	ScopeCollection  = "collection"
+	ScopeEnvironment = "environment"
+)
+
+// Extraction captures a value from a response into a variable
+type Extraction struct {
+	Source     string `json:"source"`          // json, header or regex
+	Expression string `json:"expression"`      // JSON path, header name or regular expression
+	Variable   string `json:"variable"`        // Variable receiving the extracted value
+	Scope      string `json:"scope,omitempty"` // collection (default) or environment
+}
+
+// TargetScope returns the scope the extracted value is written to
+func (e Extraction) TargetScope() string {
+	if e.Scope == "" {
+		return ScopeCollection
+	}
+	return e.Scope
+}
+
+// String returns the extraction in the same form accepted by the extraction parser
+func (e Extraction) String() string {
+	return fmt.Sprintf("%s %s as %s in %s", e.Source, e.Expression, e.Variable, e.TargetScope())
+}
+
+// Validate checks that the extraction is complete
+func (e Extraction) Validate() error {
+	switch e.Source {
+	case ExtractJSONPath, ExtractHeader, ExtractRegex:
+	default:
+		return fmt.Errorf("unknown extraction source: %s (use json, header or regex)", e.Source)
+	}
+
+	if e.Expression == "" {
+		return fmt.Errorf("extraction expression cannot be empty")
+	}
+	if e.Variable == "" {
+		return fmt.Errorf("extraction variable cannot be empty")
+	}
+
+	switch e.TargetScope() {
+	case ScopeCollection, ScopeEnvironment:
+	default:
+		return fmt.Errorf("unknown extraction scope: %s (use collection or environment)", e.Scope)
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package models
+
+import (
+	"strings"
+)
+
+// FolderSeparator separates the names of nested folders in a folder path
+const FolderSeparator = "/"
+
+// Folder groups requests within a collection
+// Folders are identified by their path, e.g. "Users/Admin"; requests point
+// at their folder through Request.Folder so Collection.Requests stays flat
+type Folder struct {
+	Path        string `json:"path"`
+	Description string `json:"description,omitempty"`
+	Auth        *Auth  `json:"auth,omitempty"` // nil inherits from the parent folder or collection
+}
+
+// Name returns the last element of the folder path
+func (f Folder) Name() string {
+	return FolderName(f.Path)
+}
+
+// FolderName returns the last element of a folder path
+func FolderName(path string) string {
+	if i := strings.LastIndex(path, FolderSeparator); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+// FolderParent returns the path of the enclosing folder, or "" at the top level
+func FolderParent(path string) string {
+	if i := strings.LastIndex(path, FolderSeparator); i >= 0 {
+		return path[:i]
+	}
+	return ""
+}
+
+// JoinFolderPath builds a folder path from folder names, dropping empty ones
+func JoinFolderPath(names ...string) string {
+	parts := []string{}
+	for _, name := range names {
+		name = strings.Trim(strings.TrimSpace(name), FolderSeparator)
+		if name != "" {
+			parts = append(parts, name)
+		}
+	}
+	return strings.Join(parts, FolderSeparator)
+}
+
+// InFolder reports whether path is folder itself or nested below it
+func InFolder(path, folder string) bool {
+	if folder == "" {
+		return true
+	}
+	return path == folder || strings.HasPrefix(path, folder+FolderSeparator)
+}
+
+// GetFolder returns a folder by path
+func (c *Collection) GetFolder(path string) *Folder {
+	for i := range c.Folders {
+		if c.Folders[i].Path == path {
+			return &c.Folders[i]
+		}
+	}
+	return nil
+}
+
+// AddFolder adds a folder and any missing parent folders
+// The top level ("") is not a folder and is never stored
+func (c *Collection) AddFolder(path string) *Folder {
+	if path == "" {
+		return &Folder{}
+	}
+
+	if parent := FolderParent(path); parent != "" {
+		c.AddFolder(parent)
+	}
+
+	if folder := c.GetFolder(path); folder != nil {
+		return folder
+	}
+
+	c.Folders = append(c.Folders, Folder{Path: path})
+	return &c.Folders[len(c.Folders)-1]
+}
+
+// FolderPaths returns every folder path, including folders only referenced by
+// requests, in the order they first appear
+func (c *Collection) FolderPaths() []string {
+	paths := []string{}
+	seen := make(map[string]bool)
+
+	var add func(path string)
+	add = func(path string) {
+		if path == "" || seen[path] {
+			return
+		}
+		add(FolderParent(path))
+		seen[path] = true
+		paths = append(paths, path)
+	}
+
+	for _, folder := range c.Folders {
+		add(folder.Path)
+	}
+	for _, request := range c.Requests {
+		add(request.Folder)
+	}
+
+	return paths
+}
+
+// SubFolders returns the paths of the folders directly inside parent ("" for the top level)
+func (c *Collection) SubFolders(parent string) []string {
+	children := []string{}
+	for _, path := range c.FolderPaths() {
+		if FolderParent(path) == parent {
+			children = append(children, path)
+		}
+	}
+	return children
+}
+
+// RequestsInFolder returns the requests of a folder and its subfolders in collection order
+func (c *Collection) RequestsInFolder(path string) []*Request {
+	requests := []*Request{}
+	for _, request := range c.Requests {
+		if InFolder(request.Folder, path) {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// RenameFolder moves a folder, its subfolders and their requests to a new path
+func (c *Collection) RenameFolder(oldPath, newPath string) {
+	rename := func(path string) string {
+		if InFolder(path, oldPath) && path != "" {
+			return newPath + strings.TrimPrefix(path, oldPath)
+		}
+		return path
+	}
+
+	// Make sure the folder itself is stored so its new parents get created
+	c.AddFolder(oldPath)
+	for i := range c.Folders {
+		c.Folders[i].Path = rename(c.Folders[i].Path)
+	}
+	if parent := FolderParent(newPath); parent != "" {
+		c.AddFolder(parent)
+	}
+	for _, request := range c.Requests {
+		request.Folder = rename(request.Folder)
+	}
+}
+
+// DeleteFolder removes a folder; its requests and subfolders move up to the parent folder
+func (c *Collection) DeleteFolder(path string) {
+	parent := FolderParent(path)
+	moveUp := func(p string) string {
+		if p == path {
+			return parent
+		}
+		if strings.HasPrefix(p, path+FolderSeparator) {
+			return JoinFolderPath(parent, strings.TrimPrefix(p, path+FolderSeparator))
+		}
+		return p
+	}
+
+	folders := []Folder{}
+	for _, folder := range c.Folders {
+		if folder.Path == path {
+			continue
+		}
+		folder.Path = moveUp(folder.Path)
+		if containsFolder(folders, folder.Path) {
+			continue
+		}
+		folders = append(folders, folder)
+	}
+	c.Folders = folders
+
+	for _, request := range c.Requests {
+		request.Folder = moveUp(request.Folder)
+	}
+}
+
+// containsFolder checks a folder list for a path
+func containsFolder(folders []Folder, path string) bool {
+	for _, folder := range folders {
+		if folder.Path == path {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,10 @@
 package models
 
 import (
+	"github.com/leobrines/curlman/template"
 	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -13,41 +16,60 @@ type CollectionEnvironment struct {
 
 // Collection represents a collection of HTTP requests
 type Collection struct {
-	Name                  string                   `json:"name"`
-	Requests              []*Request               `json:"requests"`
-	Variables             map[string]string        `json:"variables"`
-	ActiveEnvironment     string                   `json:"active_environment,omitempty"`
-	EnvironmentVars       map[string]string        `json:"-"` // Runtime environment variables, not persisted
-	Environments          []CollectionEnvironment  `json:"environments,omitempty"`
-	ActiveCollectionEnv   string                   `json:"active_collection_environment,omitempty"`
-	CollectionEnvVars     map[string]string        `json:"-"` // Runtime collection environment variables, not persisted
+	Name                string                  `json:"name"`
+	Requests            []*Request              `json:"requests"`
+	Folders             []Folder                `json:"folders,omitempty"`
+	Auth                *Auth                   `json:"auth,omitempty"`     // Default auth of the requests
+	Settings            *ClientSettings         `json:"settings,omitempty"` // Default client settings of the requests
+	Variables           map[string]string       `json:"variables"`
+	ActiveEnvironment   string                  `json:"active_environment,omitempty"`
+	EnvironmentVars     map[string]string       `json:"-"` // Runtime environment variables, not persisted
+	Environments        []CollectionEnvironment `json:"environments,omitempty"`
+	ActiveCollectionEnv string                  `json:"active_collection_environment,omitempty"`
+	CollectionEnvVars   map[string]string       `json:"-"` // Runtime collection environment variables, not persisted
+	File                string                  `json:"-"` // Storage file name, set when loaded or saved, not persisted
 }
 
 // Request represents an HTTP request
 type Request struct {
-	ID          string            `json:"id"`
-	Name        string            `json:"name"`
-	Method      string            `json:"method"`
-	URL         string            `json:"url"`
-	Path        string            `json:"path"`
-	Headers     map[string]string `json:"headers"`
-	QueryParams map[string]string `json:"query_params"`
-	Body        string            `json:"body,omitempty"`
-	Description string            `json:"description,omitempty"`
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Method       string            `json:"method"`
+	URL          string            `json:"url"`
+	Path         string            `json:"path"`
+	Headers      map[string]string `json:"headers"`
+	QueryParams  map[string]string `json:"query_params"`
+	Body         string            `json:"body,omitempty"`
+	BodyMode     string            `json:"body_mode,omitempty"`     // raw (default), urlencoded, formdata or binary
+	BodyLanguage string            `json:"body_language,omitempty"` // Language of raw bodies: json, xml, html, text, javascript
+	FormFields   []FormField       `json:"form_fields,omitempty"`   // Fields of urlencoded and formdata bodies
+	BodyFile     string            `json:"body_file,omitempty"`     // File sent as the body in binary mode
+	Description  string            `json:"description,omitempty"`
+	Folder       string            `json:"folder,omitempty"`   // Folder path, "" for the top level
+	Auth         *Auth             `json:"auth,omitempty"`     // nil inherits from the folder or collection
+	Settings     *ClientSettings   `json:"settings,omitempty"` // Overrides the collection client settings
+	Assertions   []Assertion       `json:"assertions,omitempty"`
+	Extractions  []Extraction      `json:"extractions,omitempty"`
 }
 
 // Clone creates a deep copy of the request
 func (r *Request) Clone() *Request {
 	clone := &Request{
-		ID:          r.ID + "_clone",
-		Name:        r.Name + " (Clone)",
-		Method:      r.Method,
-		URL:         r.URL,
-		Path:        r.Path,
-		Body:        r.Body,
-		Description: r.Description,
-		Headers:     make(map[string]string),
-		QueryParams: make(map[string]string),
+		ID:           r.ID + "_clone",
+		Name:         r.Name + " (Clone)",
+		Method:       r.Method,
+		URL:          r.URL,
+		Path:         r.Path,
+		Body:         r.Body,
+		BodyMode:     r.BodyMode,
+		BodyLanguage: r.BodyLanguage,
+		BodyFile:     r.BodyFile,
+		Description:  r.Description,
+		Folder:       r.Folder,
+		Auth:         r.Auth.Clone(),
+		Settings:     r.Settings.Clone(),
+		Headers:      make(map[string]string),
+		QueryParams:  make(map[string]string),
 	}
 
 	for k, v := range r.Headers {
@@ -58,42 +80,123 @@ func (r *Request) Clone() *Request {
 		clone.QueryParams[k] = v
 	}
 
+	if len(r.FormFields) > 0 {
+		clone.FormFields = append([]FormField{}, r.FormFields...)
+	}
+
+	if len(r.Assertions) > 0 {
+		clone.Assertions = append([]Assertion{}, r.Assertions...)
+	}
+
+	if len(r.Extractions) > 0 {
+		clone.Extractions = append([]Extraction{}, r.Extractions...)
+	}
+
 	return clone
 }
 
-// InjectVariables replaces variables in the request with their values
-func (r *Request) InjectVariables(variables map[string]string) *Request {
-	injected := r.Clone()
-	injected.ID = r.ID
-	injected.Name = r.Name
+// InjectVariables returns a copy of the request with its {{ }} placeholders rendered
+// Placeholders using undefined variables are left as written; other failures are returned
+func (r *Request) InjectVariables(variables map[string]string) (*Request, error) {
+	return r.Transform(renderer(variables))
+}
 
-	// Inject into URL
-	injected.URL = replaceVariables(r.URL, variables)
-	injected.Path = replaceVariables(r.Path, variables)
+// Texts returns the fields that may hold {{ }} placeholders, the ones InjectVariables renders, leaving out empty ones
+func (r *Request) Texts() []string {
+	var texts []string
+	r.Transform(func(text string) (string, error) {
+		if text != "" {
+			texts = append(texts, text)
+		}
+		return text, nil
+	})
+	return texts
+}
+
+// Transform returns a copy of the request with fn applied to every field that may hold {{ }} placeholders,
+// stopping at the first error
+func (r *Request) Transform(fn func(text string) (string, error)) (*Request, error) {
+	t := &transformer{fn: fn}
+	transformed := r.Clone()
+	transformed.ID = r.ID
+	transformed.Name = r.Name
+
+	t.apply("URL", &transformed.URL)
+	t.apply("path", &transformed.Path)
+	t.applyMap("header", transformed.Headers)
+	t.applyMap("query parameter", transformed.QueryParams)
+	t.apply("body", &transformed.Body)
+	t.apply("body file", &transformed.BodyFile)
+	for i := range transformed.FormFields {
+		t.apply("form field name", &transformed.FormFields[i].Key)
+		t.apply("form field "+transformed.FormFields[i].Key, &transformed.FormFields[i].Value)
+	}
+	transformed.Auth.transform(t)
+	transformed.Settings.transform(t)
+	for i := range transformed.Assertions {
+		t.apply("assertion property", &transformed.Assertions[i].Property)
+		t.apply("assertion value", &transformed.Assertions[i].Expected)
+	}
 
-	// Inject into headers
-	for k, v := range injected.Headers {
-		injected.Headers[k] = replaceVariables(v, variables)
+	if t.err != nil {
+		return nil, t.err
 	}
+	return transformed, nil
+}
+
+// transformer applies a function to the templated fields of a request, keeping the first error
+type transformer struct {
+	fn  func(text string) (string, error)
+	err error
+}
 
-	// Inject into query params
-	for k, v := range injected.QueryParams {
-		injected.QueryParams[k] = replaceVariables(v, variables)
+func (t *transformer) apply(field string, text *string) {
+	if t.err != nil {
+		return
+	}
+	value, err := t.fn(*text)
+	if err != nil {
+		t.err = fmt.Errorf("%s: %w", field, err)
+		return
 	}
+	*text = value
+}
 
-	// Inject into body
-	injected.Body = replaceVariables(r.Body, variables)
+// applyMap transforms the values of a map in key order, so the first error does not depend on map iteration
+func (t *transformer) applyMap(kind string, values map[string]string) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		value := values[k]
+		t.apply(kind+" "+k, &value)
+		values[k] = value
+	}
+}
 
-	return injected
+// renderer renders {{var}} placeholders with their values, and evaluates
+// dynamic values like {{$uuid}} and functions like {{token | base64}}
+func renderer(variables map[string]string) func(text string) (string, error) {
+	return func(text string) (string, error) {
+		return template.Render(text, variables)
+	}
 }
 
-// replaceVariables replaces {{var}} placeholders with their values
-func replaceVariables(text string, variables map[string]string) string {
-	result := text
-	for k, v := range variables {
-		result = strings.ReplaceAll(result, "{{"+k+"}}", v)
+// Resolve returns the request with the auth and client settings it inherits filled in
+// The request itself is returned when it already carries them
+func (c *Collection) Resolve(request *Request) *Request {
+	auth := c.EffectiveAuth(request)
+	if auth == request.Auth && c.Settings.IsEmpty() {
+		return request
 	}
-	return result
+	resolved := request.Clone()
+	resolved.ID = request.ID
+	resolved.Name = request.Name
+	resolved.Auth = auth.Clone()
+	resolved.Settings = c.Settings.Merge(request.Settings)
+	return resolved
 }
 
 // FullURL returns the complete URL including path and query parameters
@@ -0,0 +1,205 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// HTTP versions a request can be forced to
+const (
+	HTTPVersion11 = "1.1"
+	HTTPVersion2  = "2"
+)
+
+// ClientSettings configures the HTTP client sending a request
+// Unset values fall back to the collection settings, then to the defaults
+type ClientSettings struct {
+	Timeout         string `json:"timeout,omitempty"`          // Go duration, e.g. "10s"; defaults to 30s
+	FollowRedirects *bool  `json:"follow_redirects,omitempty"` // Defaults to true
+	MaxRedirects    int    `json:"max_redirects,omitempty"`    // Defaults to 10
+	Proxy           string `json:"proxy,omitempty"`            // HTTP(S) proxy URL; defaults to the HTTP(S)_PROXY environment
+	NoProxy         string `json:"no_proxy,omitempty"`         // Comma separated hosts bypassing the proxy, like NO_PROXY
+	Insecure        *bool  `json:"insecure,omitempty"`         // Skip TLS certificate verification
+	CACert          string `json:"ca_cert,omitempty"`          // PEM bundle trusted in addition to the system roots
+	ClientCert      string `json:"client_cert,omitempty"`      // PEM client certificate for mutual TLS
+	ClientKey       string `json:"client_key,omitempty"`       // PEM key of the client certificate
+	HTTPVersion     string `json:"http_version,omitempty"`     // "1.1" or "2"; empty negotiates
+	Cookies         *bool  `json:"cookies,omitempty"`          // Use the cookie jar; defaults to true
+}
+
+// ClientSettingFields lists the settings in display order
+var ClientSettingFields = []string{
+	"timeout",
+	"follow_redirects",
+	"max_redirects",
+	"proxy",
+	"no_proxy",
+	"insecure",
+	"ca_cert",
+	"client_cert",
+	"client_key",
+	"http_version",
+	"cookies",
+}
+
+// CookiesEnabled reports whether requests send and store cookies of the cookie jar
+func (s *ClientSettings) CookiesEnabled() bool {
+	return s == nil || s.Cookies == nil || *s.Cookies
+}
+
+// IsEmpty reports whether no setting is set
+func (s *ClientSettings) IsEmpty() bool {
+	return s == nil || *s == ClientSettings{}
+}
+
+// Get returns a setting as text, "" when unset
+func (s *ClientSettings) Get(field string) string {
+	if s == nil {
+		return ""
+	}
+	switch field {
+	case "timeout":
+		return s.Timeout
+	case "follow_redirects":
+		return formatBool(s.FollowRedirects)
+	case "max_redirects":
+		if s.MaxRedirects == 0 {
+			return ""
+		}
+		return strconv.Itoa(s.MaxRedirects)
+	case "proxy":
+		return s.Proxy
+	case "no_proxy":
+		return s.NoProxy
+	case "insecure":
+		return formatBool(s.Insecure)
+	case "ca_cert":
+		return s.CACert
+	case "client_cert":
+		return s.ClientCert
+	case "client_key":
+		return s.ClientKey
+	case "http_version":
+		return s.HTTPVersion
+	case "cookies":
+		return formatBool(s.Cookies)
+	}
+	return ""
+}
+
+// Clone returns a copy of the settings
+func (s *ClientSettings) Clone() *ClientSettings {
+	if s == nil {
+		return nil
+	}
+	clone := *s
+	if s.FollowRedirects != nil {
+		follow := *s.FollowRedirects
+		clone.FollowRedirects = &follow
+	}
+	if s.Insecure != nil {
+		insecure := *s.Insecure
+		clone.Insecure = &insecure
+	}
+	if s.Cookies != nil {
+		cookies := *s.Cookies
+		clone.Cookies = &cookies
+	}
+	return &clone
+}
+
+// Merge returns the settings with the values set in override replacing them
+func (s *ClientSettings) Merge(override *ClientSettings) *ClientSettings {
+	if s.IsEmpty() {
+		return override.Clone()
+	}
+	merged := s.Clone()
+	if override == nil {
+		return merged
+	}
+
+	if override.Timeout != "" {
+		merged.Timeout = override.Timeout
+	}
+	if override.FollowRedirects != nil {
+		follow := *override.FollowRedirects
+		merged.FollowRedirects = &follow
+	}
+	if override.MaxRedirects != 0 {
+		merged.MaxRedirects = override.MaxRedirects
+	}
+	if override.Proxy != "" {
+		merged.Proxy = override.Proxy
+	}
+	if override.NoProxy != "" {
+		merged.NoProxy = override.NoProxy
+	}
+	if override.Insecure != nil {
+		insecure := *override.Insecure
+		merged.Insecure = &insecure
+	}
+	if override.CACert != "" {
+		merged.CACert = override.CACert
+	}
+	if override.ClientCert != "" {
+		merged.ClientCert = override.ClientCert
+	}
+	if override.ClientKey != "" {
+		merged.ClientKey = override.ClientKey
+	}
+	if override.HTTPVersion != "" {
+		merged.HTTPVersion = override.HTTPVersion
+	}
+	if override.Cookies != nil {
+		cookies := *override.Cookies
+		merged.Cookies = &cookies
+	}
+	return merged
+}
+
+// InjectVariables returns a copy of the settings with their {{ }} placeholders rendered
+func (s *ClientSettings) InjectVariables(variables map[string]string) (*ClientSettings, error) {
+	t := &transformer{fn: renderer(variables)}
+	injected := s.Clone()
+	injected.transform(t)
+	if t.err != nil {
+		return nil, t.err
+	}
+	return injected, nil
+}
+
+// transform applies a transformer to the settings holding text, in place
+func (s *ClientSettings) transform(t *transformer) {
+	if s == nil {
+		return
+	}
+	t.apply("timeout", &s.Timeout)
+	t.apply("proxy", &s.Proxy)
+	t.apply("no_proxy", &s.NoProxy)
+	t.apply("CA certificate", &s.CACert)
+	t.apply("client certificate", &s.ClientCert)
+	t.apply("client key", &s.ClientKey)
+}
+
+// ParseBool reads yes/no style booleans, returning nil for an empty value
+func ParseBool(value string) (*bool, bool) {
+	var b bool
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "":
+		return nil, true
+	case "true", "yes", "on", "1":
+		b = true
+	case "false", "no", "off", "0":
+		b = false
+	default:
+		return nil, false
+	}
+	return &b, true
+}
+
+func formatBool(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
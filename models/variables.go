@@ -0,0 +1,62 @@
+package models
+
+import (
+	"fmt"
+)
+
+// VariableSource is a layer variables are defined in, from lowest to highest precedence
+type VariableSource int
+
+const (
+	SourceGlobal VariableSource = iota
+	SourceCollection
+	SourceGlobalEnvironment
+	SourceCollectionEnvironment
+)
+
+func (s VariableSource) String() string {
+	switch s {
+	case SourceGlobal:
+		return "global"
+	case SourceCollection:
+		return "collection"
+	case SourceGlobalEnvironment:
+		return "global environment"
+	case SourceCollectionEnvironment:
+		return "collection environment"
+	}
+	return "unknown"
+}
+
+// VariableValue is the value a variable has in one layer
+type VariableValue struct {
+	Source      VariableSource
+	Environment string // Name of the environment, for environment layers
+	Value       string
+}
+
+// Origin describes the layer of the value, naming its environment
+func (v VariableValue) Origin() string {
+	if v.Environment != "" {
+		return fmt.Sprintf("%s %q", v.Source, v.Environment)
+	}
+	return v.Source.String()
+}
+
+// VariableLayers returns the values every variable has in each layer, highest precedence first
+// The first value of a variable is the one GetAllVariables keeps
+func (c *Collection) VariableLayers(globalVars map[string]string) map[string][]VariableValue {
+	layers := make(map[string][]VariableValue)
+	add := func(source VariableSource, environment string, variables map[string]string) {
+		for k, v := range variables {
+			layers[k] = append([]VariableValue{{Source: source, Environment: environment, Value: v}}, layers[k]...)
+		}
+	}
+
+	add(SourceGlobal, "", globalVars)
+	add(SourceCollection, "", c.Variables)
+	add(SourceGlobalEnvironment, c.ActiveEnvironment, c.EnvironmentVars)
+	add(SourceCollectionEnvironment, c.ActiveCollectionEnv, c.CollectionEnvVars)
+
+	return layers
+}
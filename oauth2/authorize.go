@@ -0,0 +1,169 @@
+package oauth2
+
+import (
+	"github.com/leobrines/curlman/executor"
+	"github.com/leobrines/curlman/models"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultRedirectURL is the callback used when an auth sets no redirect_url
+const DefaultRedirectURL = "http://127.0.0.1:8765/callback"
+
+// AuthorizeTimeout is how long the callback listener waits for the browser
+const AuthorizeTimeout = 5 * time.Minute
+
+// callbackResult is what the authorization server sends back to the callback
+type callbackResult struct {
+	code string
+	err  error
+}
+
+// Authorize runs the authorization code grant with PKCE: it starts a listener on the
+// localhost redirect URL, hands the authorization URL to open, and exchanges the code
+// received on the callback for a token, which is cached
+func Authorize(ctx context.Context, auth *models.Auth, settings *models.ClientSettings, open func(authURL string)) (*Token, error) {
+	config, err := oauth2Config(auth)
+	if err != nil {
+		return nil, err
+	}
+	client, err := executor.NewClient(settings, nil)
+	if err != nil {
+		return nil, err
+	}
+	if config.Grant != models.GrantAuthorizationCode {
+		return fetchToken(ctx, client, auth, config)
+	}
+	if config.AuthURL == "" {
+		return nil, fmt.Errorf("the authorization_code grant needs an auth_url")
+	}
+
+	redirectURL := config.RedirectURL
+	if redirectURL == "" {
+		redirectURL = DefaultRedirectURL
+	}
+	redirect, err := url.Parse(redirectURL)
+	if err != nil || redirect.Host == "" {
+		return nil, fmt.Errorf("invalid redirect_url: %s", redirectURL)
+	}
+
+	verifier := randomString(32)
+	state := randomString(16)
+	challenge := sha256.Sum256([]byte(verifier))
+
+	listener, err := net.Listen("tcp", redirect.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", redirect.Host, err)
+	}
+
+	results := make(chan callbackResult, 1)
+	callbackPath := redirect.Path
+	if callbackPath == "" {
+		callbackPath = "/"
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(callbackPath, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		var result callbackResult
+		switch {
+		case query.Get("error") != "":
+			result.err = fmt.Errorf("authorization denied: %s %s", query.Get("error"), query.Get("error_description"))
+		case query.Get("state") != state:
+			// Not the redirect of this flow, which is still awaited
+			http.Error(w, "authorization callback has an unexpected state", http.StatusBadRequest)
+			return
+		case query.Get("code") == "":
+			result.err = fmt.Errorf("authorization callback has no code")
+		default:
+			result.code = query.Get("code")
+		}
+
+		if result.err != nil {
+			http.Error(w, result.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "Authorization complete, you can close this window and return to curlman.")
+		}
+
+		select {
+		case results <- result:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {config.ClientID},
+		"redirect_uri":          {redirectURL},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	if config.Scope != "" {
+		query.Set("scope", config.Scope)
+	}
+	separator := "?"
+	if strings.Contains(config.AuthURL, "?") {
+		separator = "&"
+	}
+	open(config.AuthURL + separator + query.Encode())
+
+	ctx, cancel := context.WithTimeout(ctx, AuthorizeTimeout)
+	defer cancel()
+
+	var result callbackResult
+	select {
+	case result = <-results:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("no authorization callback received: %w", ctx.Err())
+	}
+	if result.err != nil {
+		return nil, result.err
+	}
+
+	token, err := requestToken(ctx, client, config, url.Values{
+		"grant_type":    {models.GrantAuthorizationCode},
+		"code":          {result.code},
+		"redirect_uri":  {redirectURL},
+		"code_verifier": {verifier},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return token, saveToken(auth, token)
+}
+
+// OpenBrowser opens a URL in the default browser
+func OpenBrowser(target string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", target)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	default:
+		cmd = exec.Command("xdg-open", target)
+	}
+	return cmd.Start()
+}
+
+// randomString returns n random bytes encoded as unpadded base64url, as PKCE verifiers require
+func randomString(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,319 @@
+package oauth2
+
+import (
+	"github.com/leobrines/curlman/executor"
+	"github.com/leobrines/curlman/models"
+	"github.com/leobrines/curlman/storage"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExpiryMargin is how long before its expiry a token is refreshed
+const ExpiryMargin = 30 * time.Second
+
+// ErrAuthorizationRequired is returned when an authorization code grant has no usable token
+// and the user must authorize in a browser first
+var ErrAuthorizationRequired = errors.New("authorization required: authorize first with `curlman auth` or 'o' in the request view")
+
+// Token is an access token with its expiry
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Scope        string    `json:"scope,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at,omitempty"` // Zero when the server gave no expiry
+}
+
+// Valid reports whether the token can still be used for ExpiryMargin
+func (t *Token) Valid() bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.ExpiresAt.IsZero() || time.Now().Add(ExpiryMargin).Before(t.ExpiresAt)
+}
+
+// GetToken returns a valid access token for an OAuth2 auth with variables already injected
+// A cached token is used while valid, then refreshed with its refresh token, and
+// a new one is requested as a last resort (except for the authorization code grant)
+// The token endpoint is called with the client settings of the request, like the request itself
+func GetToken(ctx context.Context, auth *models.Auth, settings *models.ClientSettings) (*Token, error) {
+	config, err := oauth2Config(auth)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, _ := loadToken(auth)
+	if cached.Valid() {
+		return cached, nil
+	}
+
+	client, err := executor.NewClient(settings, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != nil && cached.RefreshToken != "" {
+		if token, err := refresh(ctx, client, config, cached.RefreshToken); err == nil {
+			return token, saveToken(auth, token)
+		}
+	}
+
+	if config.Grant == models.GrantAuthorizationCode {
+		return nil, ErrAuthorizationRequired
+	}
+	return fetchToken(ctx, client, auth, config)
+}
+
+// FetchToken requests a new token for the client credentials, password and refresh token grants
+func FetchToken(ctx context.Context, auth *models.Auth, settings *models.ClientSettings) (*Token, error) {
+	config, err := oauth2Config(auth)
+	if err != nil {
+		return nil, err
+	}
+	client, err := executor.NewClient(settings, nil)
+	if err != nil {
+		return nil, err
+	}
+	return fetchToken(ctx, client, auth, config)
+}
+
+func fetchToken(ctx context.Context, client *http.Client, auth *models.Auth, config *models.OAuth2) (*Token, error) {
+	var (
+		token *Token
+		err   error
+	)
+
+	switch config.Grant {
+	case models.GrantClientCredentials:
+		form := url.Values{"grant_type": {models.GrantClientCredentials}}
+		if config.Scope != "" {
+			form.Set("scope", config.Scope)
+		}
+		token, err = requestToken(ctx, client, config, form)
+	case models.GrantPassword:
+		form := url.Values{
+			"grant_type": {models.GrantPassword},
+			"username":   {auth.Username},
+			"password":   {auth.Password},
+		}
+		if config.Scope != "" {
+			form.Set("scope", config.Scope)
+		}
+		token, err = requestToken(ctx, client, config, form)
+	case models.GrantRefreshToken:
+		if config.RefreshToken == "" {
+			return nil, fmt.Errorf("the refresh_token grant needs a refresh_token")
+		}
+		token, err = refresh(ctx, client, config, config.RefreshToken)
+	case models.GrantAuthorizationCode:
+		return nil, ErrAuthorizationRequired
+	default:
+		return nil, fmt.Errorf("unsupported OAuth2 grant: %s", config.Grant)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return token, saveToken(auth, token)
+}
+
+// ClearToken removes the cached token of an auth
+func ClearToken(auth *models.Auth) error {
+	path, err := tokenPath(auth)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove token: %w", err)
+	}
+	return nil
+}
+
+// CachedToken returns the cached token of an auth, valid or not, or nil
+func CachedToken(auth *models.Auth) *Token {
+	token, _ := loadToken(auth)
+	return token
+}
+
+// refresh exchanges a refresh token for a new token
+func refresh(ctx context.Context, client *http.Client, config *models.OAuth2, refreshToken string) (*Token, error) {
+	form := url.Values{
+		"grant_type":    {models.GrantRefreshToken},
+		"refresh_token": {refreshToken},
+	}
+	if config.Scope != "" {
+		form.Set("scope", config.Scope)
+	}
+
+	token, err := requestToken(ctx, client, config, form)
+	if err != nil {
+		return nil, err
+	}
+	// Servers may keep the refresh token unchanged without sending it again
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	return token, nil
+}
+
+// requestToken posts a grant to the token endpoint
+// Clients with a secret authenticate with HTTP Basic auth, public clients send their id in the form
+func requestToken(ctx context.Context, client *http.Client, config *models.OAuth2, form url.Values) (*Token, error) {
+	if config.ClientSecret == "" && config.ClientID != "" {
+		form.Set("client_id", config.ClientID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(config.ClientID), url.QueryEscape(config.ClientSecret))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	var result struct {
+		AccessToken      string          `json:"access_token"`
+		TokenType        string          `json:"token_type"`
+		RefreshToken     string          `json:"refresh_token"`
+		Scope            string          `json:"scope"`
+		ExpiresIn        json.RawMessage `json:"expires_in"`
+		Error            string          `json:"error"`
+		ErrorDescription string          `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		// Some servers answer with a form-encoded body
+		values, formErr := url.ParseQuery(string(body))
+		if formErr != nil || values.Get("access_token") == "" && values.Get("error") == "" {
+			return nil, fmt.Errorf("invalid token response (%s): %w", resp.Status, err)
+		}
+		result.AccessToken = values.Get("access_token")
+		result.TokenType = values.Get("token_type")
+		result.RefreshToken = values.Get("refresh_token")
+		result.Scope = values.Get("scope")
+		result.ExpiresIn = json.RawMessage(values.Get("expires_in"))
+		result.Error = values.Get("error")
+		result.ErrorDescription = values.Get("error_description")
+	}
+
+	if result.Error != "" {
+		if result.ErrorDescription != "" {
+			return nil, fmt.Errorf("token endpoint returned %s: %s", result.Error, result.ErrorDescription)
+		}
+		return nil, fmt.Errorf("token endpoint returned %s", result.Error)
+	}
+	if resp.StatusCode >= 400 || result.AccessToken == "" {
+		return nil, fmt.Errorf("token endpoint returned %s without an access token", resp.Status)
+	}
+
+	token := &Token{
+		AccessToken:  result.AccessToken,
+		TokenType:    result.TokenType,
+		RefreshToken: result.RefreshToken,
+		Scope:        result.Scope,
+	}
+	// expires_in is a number, but some servers send it as a string
+	if seconds, err := strconv.Atoi(strings.Trim(string(result.ExpiresIn), `"`)); err == nil && seconds > 0 {
+		token.ExpiresAt = time.Now().Add(time.Duration(seconds) * time.Second)
+	}
+	return token, nil
+}
+
+// oauth2Config returns the OAuth2 settings of an auth
+func oauth2Config(auth *models.Auth) (*models.OAuth2, error) {
+	if auth == nil || auth.Type != models.AuthOAuth2 || auth.OAuth2 == nil {
+		return nil, fmt.Errorf("not an OAuth2 auth")
+	}
+	if auth.OAuth2.TokenURL == "" {
+		return nil, fmt.Errorf("OAuth2 auth needs a token_url")
+	}
+	return auth.OAuth2, nil
+}
+
+// GetTokensDir returns the directory caching OAuth2 tokens
+func GetTokensDir() (string, error) {
+	storageDir, err := storage.GetStorageDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get storage directory: %w", err)
+	}
+
+	dir := filepath.Join(storageDir, "tokens")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create tokens directory: %w", err)
+	}
+	return dir, nil
+}
+
+// tokenPath names the cache file of an auth after the settings that identify its tokens
+// Credentials are part of the key, hashed, so changing them requests a new token
+func tokenPath(auth *models.Auth) (string, error) {
+	dir, err := GetTokensDir()
+	if err != nil {
+		return "", err
+	}
+
+	o := auth.OAuth2
+	key := strings.Join([]string{o.Grant, o.TokenURL, o.ClientID, o.ClientSecret, o.Scope, o.RefreshToken, auth.Username, auth.Password}, "\n")
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:16])+".json"), nil
+}
+
+func loadToken(auth *models.Auth) (*Token, error) {
+	path, err := tokenPath(auth)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var token Token
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, fmt.Errorf("failed to parse cached token: %w", err)
+	}
+	return &token, nil
+}
+
+func saveToken(auth *models.Auth, token *Token) error {
+	path, err := tokenPath(auth)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(token, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize token: %w", err)
+	}
+
+	// Tokens are credentials, keep them private to the user
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write token: %w", err)
+	}
+	return nil
+}
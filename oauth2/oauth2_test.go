@@ -0,0 +1,479 @@
+package oauth2
+
+import (
+	"github.com/leobrines/curlman/models"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// tokenServer is a local token endpoint answering grants with handle
+type tokenServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []url.Values
+	handle   func(w http.ResponseWriter, r *http.Request, form url.Values)
+}
+
+func newTokenServer(t *testing.T, handle func(w http.ResponseWriter, r *http.Request, form url.Values)) *tokenServer {
+	t.Helper()
+	// Tokens are cached under the home directory
+	t.Setenv("HOME", t.TempDir())
+
+	s := &tokenServer{handle: handle}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *tokenServer) serve(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, r.PostForm)
+	s.mu.Unlock()
+	s.handle(w, r, r.PostForm)
+}
+
+func (s *tokenServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func clientCredentials(tokenURL string) *models.Auth {
+	return &models.Auth{Type: models.AuthOAuth2, OAuth2: &models.OAuth2{
+		Grant:        models.GrantClientCredentials,
+		TokenURL:     tokenURL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scope:        "read write",
+	}}
+}
+
+func TestClientCredentials(t *testing.T) {
+	server := newTokenServer(t, func(w http.ResponseWriter, r *http.Request, form url.Values) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client" || secret != "secret" {
+			writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "invalid_client"})
+			return
+		}
+		if form.Get("grant_type") != "client_credentials" || form.Get("scope") != "read write" || form.Has("client_id") {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid_request"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": "at-1", "token_type": "Bearer", "expires_in": 3600})
+	})
+
+	auth := clientCredentials(server.URL)
+	token, err := FetchToken(context.Background(), auth, nil)
+	if err != nil {
+		t.Fatalf("FetchToken: %v", err)
+	}
+	if token.AccessToken != "at-1" || token.TokenType != "Bearer" {
+		t.Errorf("token = %+v", token)
+	}
+	if d := time.Until(token.ExpiresAt); d < 59*time.Minute || d > time.Hour {
+		t.Errorf("token expires in %s, want an hour", d)
+	}
+
+	// A valid token is served from the cache
+	cached, err := GetToken(context.Background(), auth, nil)
+	if err != nil {
+		t.Fatalf("GetToken: %v", err)
+	}
+	if cached.AccessToken != "at-1" || server.count() != 1 {
+		t.Errorf("GetToken = %q after %d token requests, want the cached token", cached.AccessToken, server.count())
+	}
+}
+
+func TestPasswordPublicClient(t *testing.T) {
+	server := newTokenServer(t, func(w http.ResponseWriter, r *http.Request, form url.Values) {
+		if _, _, ok := r.BasicAuth(); ok {
+			t.Errorf("public client sent basic auth")
+		}
+		if form.Get("grant_type") != "password" || form.Get("username") != "ann" || form.Get("password") != "pw" || form.Get("client_id") != "app" {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid_grant", "error_description": "bad credentials"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": "at-pw", "refresh_token": "rt-pw", "expires_in": "120"})
+	})
+
+	auth := &models.Auth{Type: models.AuthOAuth2, Username: "ann", Password: "pw", OAuth2: &models.OAuth2{
+		Grant: models.GrantPassword, TokenURL: server.URL, ClientID: "app",
+	}}
+	token, err := GetToken(context.Background(), auth, nil)
+	if err != nil {
+		t.Fatalf("GetToken: %v", err)
+	}
+	if token.AccessToken != "at-pw" || token.RefreshToken != "rt-pw" || token.ExpiresAt.IsZero() {
+		t.Errorf("token = %+v", token)
+	}
+
+	auth.Password = "wrong"
+	_, err = FetchToken(context.Background(), auth, nil)
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant: bad credentials") {
+		t.Errorf("FetchToken with a wrong password: %v", err)
+	}
+}
+
+func TestRefreshTokenGrant(t *testing.T) {
+	server := newTokenServer(t, func(w http.ResponseWriter, r *http.Request, form url.Values) {
+		if form.Get("grant_type") != "refresh_token" || form.Get("refresh_token") != "rt-1" {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid_grant"})
+			return
+		}
+		// The refresh token is not sent again
+		writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": "at-r"})
+	})
+
+	auth := &models.Auth{Type: models.AuthOAuth2, OAuth2: &models.OAuth2{
+		Grant: models.GrantRefreshToken, TokenURL: server.URL, ClientID: "c", ClientSecret: "s", RefreshToken: "rt-1",
+	}}
+	token, err := FetchToken(context.Background(), auth, nil)
+	if err != nil {
+		t.Fatalf("FetchToken: %v", err)
+	}
+	if token.AccessToken != "at-r" || token.RefreshToken != "rt-1" || !token.ExpiresAt.IsZero() {
+		t.Errorf("token = %+v", token)
+	}
+
+	auth.OAuth2.RefreshToken = ""
+	if _, err := FetchToken(context.Background(), auth, nil); err == nil {
+		t.Errorf("FetchToken without a refresh token succeeded")
+	}
+}
+
+func TestExpiredTokenIsRefreshed(t *testing.T) {
+	refreshFails := false
+	server := newTokenServer(t, func(w http.ResponseWriter, r *http.Request, form url.Values) {
+		switch form.Get("grant_type") {
+		case "client_credentials":
+			// Expires within ExpiryMargin, so it is stale right away
+			writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": "at-old", "refresh_token": "rt", "expires_in": 10})
+		case "refresh_token":
+			if refreshFails {
+				writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid_grant"})
+				return
+			}
+			writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": "at-new", "expires_in": 3600})
+		}
+	})
+
+	auth := clientCredentials(server.URL)
+	if _, err := FetchToken(context.Background(), auth, nil); err != nil {
+		t.Fatalf("FetchToken: %v", err)
+	}
+	if CachedToken(auth).Valid() {
+		t.Fatalf("token expiring within the margin is valid")
+	}
+
+	token, err := GetToken(context.Background(), auth, nil)
+	if err != nil {
+		t.Fatalf("GetToken: %v", err)
+	}
+	if token.AccessToken != "at-new" || token.RefreshToken != "rt" {
+		t.Errorf("refreshed token = %+v", token)
+	}
+	if cached := CachedToken(auth); cached.AccessToken != "at-new" {
+		t.Errorf("cached token = %+v, want the refreshed one", cached)
+	}
+
+	// A failed refresh falls back to a new token
+	if err := ClearToken(auth); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FetchToken(context.Background(), auth, nil); err != nil {
+		t.Fatal(err)
+	}
+	refreshFails = true
+	token, err = GetToken(context.Background(), auth, nil)
+	if err != nil {
+		t.Fatalf("GetToken after a failed refresh: %v", err)
+	}
+	if token.AccessToken != "at-old" {
+		t.Errorf("token after a failed refresh = %q, want a new one", token.AccessToken)
+	}
+	grants := []string{}
+	for _, form := range server.requests {
+		grants = append(grants, form.Get("grant_type"))
+	}
+	want := "client_credentials refresh_token client_credentials refresh_token client_credentials"
+	if strings.Join(grants, " ") != want {
+		t.Errorf("grants = %v, want %s", grants, want)
+	}
+}
+
+func TestCacheKeyIncludesCredentials(t *testing.T) {
+	server := newTokenServer(t, func(w http.ResponseWriter, r *http.Request, form url.Values) {
+		_, secret, _ := r.BasicAuth()
+		writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": "at-" + secret, "expires_in": 3600})
+	})
+
+	auth := clientCredentials(server.URL)
+	if _, err := GetToken(context.Background(), auth, nil); err != nil {
+		t.Fatal(err)
+	}
+	auth.OAuth2.ClientSecret = "rotated"
+	token, err := GetToken(context.Background(), auth, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "at-rotated" || server.count() != 2 {
+		t.Errorf("token after changing the secret = %q, want a new one", token.AccessToken)
+	}
+}
+
+func TestTokenErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		handle func(w http.ResponseWriter)
+		want   string
+	}{
+		{"json error", func(w http.ResponseWriter) {
+			writeJSON(w, http.StatusUnauthorized, map[string]interface{}{"error": "invalid_client", "error_description": "unknown client"})
+		}, "invalid_client: unknown client"},
+		{"form error", func(w http.ResponseWriter) {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "error=unauthorized_client")
+		}, "returned unauthorized_client"},
+		{"no token", func(w http.ResponseWriter) {
+			writeJSON(w, http.StatusOK, map[string]interface{}{"token_type": "Bearer"})
+		}, "without an access token"},
+		{"server error", func(w http.ResponseWriter) {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}, "invalid token response (500 Internal Server Error)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTokenServer(t, func(w http.ResponseWriter, r *http.Request, form url.Values) {
+				tt.handle(w)
+			})
+			_, err := GetToken(context.Background(), clientCredentials(server.URL), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("GetToken error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormEncodedToken(t *testing.T) {
+	server := newTokenServer(t, func(w http.ResponseWriter, r *http.Request, form url.Values) {
+		fmt.Fprint(w, "access_token=at-form&token_type=bearer&expires_in=60")
+	})
+	token, err := FetchToken(context.Background(), clientCredentials(server.URL), nil)
+	if err != nil {
+		t.Fatalf("FetchToken: %v", err)
+	}
+	if token.AccessToken != "at-form" || token.ExpiresAt.IsZero() {
+		t.Errorf("token = %+v", token)
+	}
+}
+
+func TestTokenRequestUsesClientSettings(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": "at-tls"})
+	}))
+	defer server.Close()
+
+	auth := clientCredentials(server.URL)
+	if _, err := FetchToken(context.Background(), auth, nil); err == nil {
+		t.Fatalf("FetchToken trusted a self-signed certificate without settings")
+	}
+
+	insecure := true
+	token, err := FetchToken(context.Background(), auth, &models.ClientSettings{Insecure: &insecure})
+	if err != nil {
+		t.Fatalf("FetchToken with insecure settings: %v", err)
+	}
+	if token.AccessToken != "at-tls" {
+		t.Errorf("token = %+v", token)
+	}
+
+	if _, err := FetchToken(context.Background(), auth, &models.ClientSettings{Timeout: "soon"}); err == nil {
+		t.Errorf("FetchToken accepted invalid settings")
+	}
+}
+
+func TestTokenRequestIsCancelled(t *testing.T) {
+	server := newTokenServer(t, func(w http.ResponseWriter, r *http.Request, form url.Values) {
+		<-r.Context().Done()
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := GetToken(ctx, clientCredentials(server.URL), nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetToken with a cancelled context: %v", err)
+	}
+}
+
+func TestAuthorizationCodeWithPKCE(t *testing.T) {
+	var challenge string
+	server := newTokenServer(t, func(w http.ResponseWriter, r *http.Request, form url.Values) {
+		sum := sha256.Sum256([]byte(form.Get("code_verifier")))
+		if form.Get("grant_type") != "authorization_code" || form.Get("code") != "the-code" ||
+			base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			writeJSON(w, http.StatusBadRequest, map[string]interface{}{"error": "invalid_grant"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": "at-code", "refresh_token": "rt-code", "expires_in": 3600})
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	redirectURL := "http://" + listener.Addr().String() + "/callback"
+	listener.Close()
+
+	auth := &models.Auth{Type: models.AuthOAuth2, OAuth2: &models.OAuth2{
+		Grant: models.GrantAuthorizationCode, TokenURL: server.URL, AuthURL: "https://auth.example.com/authorize?audience=api",
+		RedirectURL: redirectURL, ClientID: "app", Scope: "openid",
+	}}
+
+	if _, err := GetToken(context.Background(), auth, nil); !errors.Is(err, ErrAuthorizationRequired) {
+		t.Fatalf("GetToken before authorizing: %v", err)
+	}
+
+	// The browser is played by a client following the authorization URL to the callback
+	open := func(authURL string) {
+		u, err := url.Parse(authURL)
+		if err != nil {
+			t.Errorf("invalid authorization URL: %v", err)
+			return
+		}
+		q := u.Query()
+		if q.Get("audience") != "api" || q.Get("response_type") != "code" || q.Get("client_id") != "app" ||
+			q.Get("redirect_uri") != redirectURL || q.Get("scope") != "openid" || q.Get("code_challenge_method") != "S256" {
+			t.Errorf("authorization URL = %s", authURL)
+		}
+		challenge = q.Get("code_challenge")
+		go func() {
+			resp, err := http.Get(redirectURL + "?" + url.Values{"code": {"the-code"}, "state": {q.Get("state")}}.Encode())
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}
+
+	token, err := Authorize(context.Background(), auth, nil, open)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if token.AccessToken != "at-code" {
+		t.Errorf("token = %+v", token)
+	}
+	if cached, err := GetToken(context.Background(), auth, nil); err != nil || cached.AccessToken != "at-code" {
+		t.Errorf("GetToken after authorizing = %v, %v", cached, err)
+	}
+}
+
+func TestAuthorizationCallbackErrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	redirectURL := "http://" + listener.Addr().String() + "/cb"
+	listener.Close()
+
+	auth := &models.Auth{Type: models.AuthOAuth2, OAuth2: &models.OAuth2{
+		Grant: models.GrantAuthorizationCode, TokenURL: "http://127.0.0.1:1/token", AuthURL: "https://auth.example.com/authorize",
+		RedirectURL: redirectURL, ClientID: "app",
+	}}
+
+	tests := []struct {
+		name  string
+		query func(state string) url.Values
+		want  string
+	}{
+		{"denied", func(string) url.Values {
+			return url.Values{"error": {"access_denied"}}
+		}, "authorization denied: access_denied"},
+		{"no code", func(state string) url.Values {
+			return url.Values{"state": {state}}
+		}, "has no code"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			open := func(authURL string) {
+				u, _ := url.Parse(authURL)
+				go func() {
+					resp, err := http.Get(redirectURL + "?" + tt.query(u.Query().Get("state")).Encode())
+					if err == nil {
+						resp.Body.Close()
+					}
+				}()
+			}
+			_, err := Authorize(context.Background(), auth, nil, open)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Authorize error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizationIgnoresUnexpectedState(t *testing.T) {
+	server := newTokenServer(t, func(w http.ResponseWriter, r *http.Request, form url.Values) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{"access_token": "at-" + form.Get("code")})
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	redirectURL := "http://" + listener.Addr().String() + "/cb"
+	listener.Close()
+
+	auth := &models.Auth{Type: models.AuthOAuth2, OAuth2: &models.OAuth2{
+		Grant: models.GrantAuthorizationCode, TokenURL: server.URL, AuthURL: "https://auth.example.com/authorize",
+		RedirectURL: redirectURL, ClientID: "app",
+	}}
+
+	// A callback with another state is turned away, and the flow goes on with the real redirect
+	open := func(authURL string) {
+		u, _ := url.Parse(authURL)
+		go func() {
+			resp, err := http.Get(redirectURL + "?" + url.Values{"code": {"forged"}, "state": {"forged"}}.Encode())
+			if err != nil {
+				t.Errorf("forged callback: %v", err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("forged callback status = %d, want 400", resp.StatusCode)
+			}
+
+			resp, err = http.Get(redirectURL + "?" + url.Values{"code": {"real"}, "state": {u.Query().Get("state")}}.Encode())
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+	}
+	token, err := Authorize(context.Background(), auth, nil, open)
+	if err != nil {
+		t.Fatalf("Authorize: %v", err)
+	}
+	if token.AccessToken != "at-real" {
+		t.Errorf("token = %q, want the one of the real redirect", token.AccessToken)
+	}
+}
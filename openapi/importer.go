@@ -69,6 +69,15 @@ func convertToCollection(doc *openapi3.T) *models.Collection {
 		}
 	}
 
+	// Tags become folders, keeping their descriptions
+	for _, tag := range doc.Tags {
+		if tag == nil || tag.Name == "" {
+			continue
+		}
+		folder := collection.AddFolder(models.JoinFolderPath(tag.Name))
+		folder.Description = tag.Description
+	}
+
 	// Iterate through all paths and operations
 	for path, pathItem := range doc.Paths.Map() {
 		if pathItem == nil {
@@ -92,6 +101,12 @@ func convertToCollection(doc *openapi3.T) *models.Collection {
 			}
 
 			request := convertOperation(baseURL, path, method, operation)
+
+			// Operations are grouped by their first tag
+			if len(operation.Tags) > 0 {
+				request.Folder = models.JoinFolderPath(operation.Tags[0])
+			}
+
 			collection.Requests = append(collection.Requests, request)
 		}
 	}
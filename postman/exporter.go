@@ -0,0 +1,259 @@
+package postman
+
+import (
+	"github.com/leobrines/curlman/models"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ExportToFile writes a collection as a Postman v2.1 collection file
+func ExportToFile(collection *models.Collection, filePath string) error {
+	data, err := Export(collection)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write Postman file: %w", err)
+	}
+	return nil
+}
+
+// Export converts a collection into Postman v2.1 collection JSON
+func Export(collection *models.Collection) ([]byte, error) {
+	pc := Collection{
+		Info: Info{
+			PostmanID: uuid.New().String(),
+			Name:      collection.Name,
+			Schema:    SchemaV21,
+		},
+		Item: []Item{},
+		Auth: exportAuth(collection.Auth),
+	}
+
+	for _, key := range sortedKeys(collection.Variables) {
+		pc.Variable = append(pc.Variable, Variable{Key: key, Value: collection.Variables[key], Type: "string"})
+	}
+
+	pc.Item = exportFolder(collection, "")
+
+	data, err := json.MarshalIndent(pc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize Postman collection: %w", err)
+	}
+	return data, nil
+}
+
+// exportFolder converts the subfolders and requests of a folder into items
+func exportFolder(collection *models.Collection, path string) []Item {
+	items := []Item{}
+
+	for _, sub := range collection.SubFolders(path) {
+		item := Item{
+			Name: models.FolderName(sub),
+			Item: exportFolder(collection, sub),
+		}
+		if folder := collection.GetFolder(sub); folder != nil {
+			item.Description = Description(folder.Description)
+			item.Auth = exportAuth(folder.Auth)
+		}
+		items = append(items, item)
+	}
+
+	for _, request := range collection.Requests {
+		if request.Folder == path {
+			items = append(items, exportRequest(request))
+		}
+	}
+
+	return items
+}
+
+// exportRequest converts a Request into a Postman request item
+func exportRequest(request *models.Request) Item {
+	pr := &Request{
+		Method: request.Method,
+		Header: []KeyValue{},
+		URL:    exportURL(request),
+	}
+
+	for _, key := range sortedKeys(request.Headers) {
+		pr.Header = append(pr.Header, KeyValue{Key: key, Value: request.Headers[key]})
+	}
+
+	if request.HasBody() {
+		pr.Body = exportBody(request)
+	}
+
+	pr.Auth = exportAuth(request.Auth)
+
+	return Item{
+		Name:        request.Name,
+		Description: Description(request.Description),
+		Request:     pr,
+	}
+}
+
+// exportAuth converts an auth to Postman's form; nil inherits
+func exportAuth(auth *models.Auth) *Auth {
+	if auth.IsInherited() {
+		return nil
+	}
+
+	switch auth.Type {
+	case models.AuthBasic:
+		return &Auth{Type: "basic", Basic: []KeyValue{{Key: "username", Value: auth.Username}, {Key: "password", Value: auth.Password}}}
+	case models.AuthDigest:
+		return &Auth{Type: "digest", Digest: []KeyValue{{Key: "username", Value: auth.Username}, {Key: "password", Value: auth.Password}}}
+	case models.AuthBearer:
+		return &Auth{Type: "bearer", Bearer: []KeyValue{{Key: "token", Value: auth.Token}}}
+	case models.AuthAPIKey:
+		in := auth.In
+		if in == "" {
+			in = models.APIKeyInHeader
+		}
+		return &Auth{Type: "apikey", APIKey: []KeyValue{{Key: "key", Value: auth.Key}, {Key: "value", Value: auth.Value}, {Key: "in", Value: in}}}
+	case models.AuthOAuth2:
+		if auth.OAuth2 == nil {
+			break
+		}
+		grant := "authorization_code_with_pkce"
+		switch auth.OAuth2.Grant {
+		case models.GrantClientCredentials:
+			grant = "client_credentials"
+		case models.GrantPassword:
+			grant = "password_credentials"
+		}
+		params := []KeyValue{
+			{Key: "grant_type", Value: grant},
+			{Key: "accessTokenUrl", Value: auth.OAuth2.TokenURL},
+			{Key: "authUrl", Value: auth.OAuth2.AuthURL},
+			{Key: "redirect_uri", Value: auth.OAuth2.RedirectURL},
+			{Key: "clientId", Value: auth.OAuth2.ClientID},
+			{Key: "clientSecret", Value: auth.OAuth2.ClientSecret},
+			{Key: "scope", Value: auth.OAuth2.Scope},
+			{Key: "username", Value: auth.Username},
+			{Key: "password", Value: auth.Password},
+		}
+		pa := &Auth{Type: "oauth2"}
+		for _, param := range params {
+			if param.Value != "" {
+				pa.OAuth2 = append(pa.OAuth2, param)
+			}
+		}
+		return pa
+	}
+	return &Auth{Type: "noauth"}
+}
+
+// exportURL builds the raw and structured forms of the request URL
+func exportURL(request *models.Request) URL {
+	base := request.URL
+	if request.Path != "" {
+		base = strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(request.Path, "/")
+	}
+
+	u := URL{Raw: base}
+
+	hostPart := base
+	if i := strings.Index(hostPart, "://"); i >= 0 {
+		u.Protocol = hostPart[:i]
+		hostPart = hostPart[i+3:]
+	}
+	host, path, _ := strings.Cut(hostPart, "/")
+	if i := strings.LastIndex(host, ":"); i >= 0 && !strings.Contains(host[i:], "}") {
+		host, u.Port = host[:i], host[i+1:]
+	}
+	if host != "" {
+		u.Host = strings.Split(host, ".")
+	}
+	if path != "" {
+		u.Path = strings.Split(path, "/")
+	}
+
+	keys := sortedKeys(request.QueryParams)
+	if len(keys) > 0 {
+		params := []string{}
+		for _, key := range keys {
+			u.Query = append(u.Query, KeyValue{Key: key, Value: request.QueryParams[key]})
+			params = append(params, key+"="+request.QueryParams[key])
+		}
+		u.Raw += "?" + strings.Join(params, "&")
+	}
+
+	return u
+}
+
+// exportBody maps the request body mode to a Postman body
+func exportBody(request *models.Request) *Body {
+	switch request.GetBodyMode() {
+	case models.BodyURLEncoded:
+		body := &Body{Mode: "urlencoded", URLEncoded: []KeyValue{}}
+		for _, field := range request.FormFields {
+			body.URLEncoded = append(body.URLEncoded, KeyValue{Key: field.Key, Value: field.Value})
+		}
+		return body
+
+	case models.BodyFormData:
+		body := &Body{Mode: "formdata", FormData: []KeyValue{}}
+		for _, field := range request.FormFields {
+			if field.IsFile() {
+				body.FormData = append(body.FormData, KeyValue{Key: field.Key, Type: "file", Src: field.Value})
+				continue
+			}
+			body.FormData = append(body.FormData, KeyValue{Key: field.Key, Value: field.Value, Type: "text"})
+		}
+		return body
+
+	case models.BodyBinary:
+		return &Body{Mode: "file", File: &BodyFile{Src: request.BodyFile}}
+	}
+
+	language := request.BodyLanguage
+	if language == "" {
+		contentType, _ := request.GetHeader("Content-Type")
+		language = models.ContentTypeLanguage(contentType)
+	}
+
+	body := &Body{Mode: "raw", Raw: request.Body}
+	if language != "" {
+		body.Options = &BodyOptions{}
+		body.Options.Raw.Language = language
+	}
+	return body
+}
+
+// ExportEnvironment converts a collection environment into Postman environment JSON
+func ExportEnvironment(env *models.CollectionEnvironment) ([]byte, error) {
+	pe := Environment{
+		ID:     uuid.New().String(),
+		Name:   env.Name,
+		Values: []EnvironmentValue{},
+		Scope:  "environment",
+	}
+
+	for _, key := range sortedKeys(env.Variables) {
+		pe.Values = append(pe.Values, EnvironmentValue{Key: key, Value: env.Variables[key], Type: "default", Enabled: true})
+	}
+
+	data, err := json.MarshalIndent(pe, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize Postman environment: %w", err)
+	}
+	return data, nil
+}
+
+// sortedKeys returns map keys in a stable order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,303 @@
+package postman
+
+import (
+	"github.com/leobrines/curlman/models"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ImportFromFile imports a Postman v2.1 collection file
+func ImportFromFile(filePath string) (*models.Collection, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Postman file: %w", err)
+	}
+	return Import(data)
+}
+
+// Import converts Postman v2.1 collection JSON into a collection
+func Import(data []byte) (*models.Collection, error) {
+	var pc Collection
+	if err := json.Unmarshal(data, &pc); err != nil {
+		return nil, fmt.Errorf("failed to parse Postman collection: %w", err)
+	}
+
+	if !strings.Contains(pc.Info.Schema, "v2.") {
+		return nil, fmt.Errorf("unsupported Postman schema: %s", pc.Info.Schema)
+	}
+
+	collection := &models.Collection{
+		Name:      pc.Info.Name,
+		Requests:  []*models.Request{},
+		Variables: make(map[string]string),
+	}
+
+	for _, v := range pc.Variable {
+		if v.Key != "" && !v.Disabled {
+			collection.Variables[v.Key] = v.Value
+		}
+	}
+
+	collection.Auth = convertAuth(pc.Auth)
+	addItems(collection, pc.Item, "")
+
+	return collection, nil
+}
+
+// addItems appends the requests of items, walking folders depth first
+func addItems(collection *models.Collection, items []Item, parent string) {
+	for _, item := range items {
+		if item.IsFolder() {
+			// Slashes would split the folder name into nested folders
+			path := models.JoinFolderPath(parent, strings.ReplaceAll(item.Name, models.FolderSeparator, "-"))
+			folder := collection.AddFolder(path)
+			folder.Description = string(item.Description)
+			folder.Auth = convertAuth(item.Auth)
+			addItems(collection, item.Item, path)
+			continue
+		}
+		collection.Requests = append(collection.Requests, convertItem(item, parent))
+	}
+}
+
+// convertItem converts a Postman request item into a Request
+func convertItem(item Item, folder string) *models.Request {
+	pr := item.Request
+
+	request := &models.Request{
+		ID:          uuid.New().String(),
+		Name:        item.Name,
+		Method:      strings.ToUpper(pr.Method),
+		Headers:     make(map[string]string),
+		QueryParams: make(map[string]string),
+		Description: string(item.Description),
+		Folder:      folder,
+	}
+
+	if request.Method == "" {
+		request.Method = "GET"
+	}
+	if request.Description == "" {
+		request.Description = string(pr.Description)
+	}
+
+	request.URL, request.Path = splitURL(pr.URL)
+
+	// Path variables (:id) become their value, or a {{id}} placeholder
+	for _, v := range pr.URL.Variable {
+		replacement := v.Value
+		if replacement == "" {
+			replacement = "{{" + v.Key + "}}"
+		}
+		request.Path = replacePathVariable(request.Path, v.Key, replacement)
+	}
+
+	if len(pr.URL.Query) > 0 {
+		for _, q := range pr.URL.Query {
+			if !q.Disabled && q.Key != "" {
+				request.QueryParams[q.Key] = q.Value
+			}
+		}
+	} else if i := strings.Index(pr.URL.Raw, "?"); i >= 0 {
+		for _, pair := range strings.Split(pr.URL.Raw[i+1:], "&") {
+			if pair == "" {
+				continue
+			}
+			key, value, _ := strings.Cut(pair, "=")
+			request.QueryParams[key] = value
+		}
+	}
+
+	for _, h := range pr.Header {
+		if !h.Disabled && h.Key != "" {
+			request.Headers[h.Key] = h.Value
+		}
+	}
+
+	if pr.Body != nil {
+		applyBody(request, pr.Body)
+	}
+
+	request.Auth = convertAuth(pr.Auth)
+
+	if request.Name == "" {
+		request.Name = fmt.Sprintf("%s %s", request.Method, request.Path)
+	}
+
+	return request
+}
+
+// splitURL separates the base URL (scheme and host) from the path
+func splitURL(u URL) (string, string) {
+	raw := u.Raw
+	if raw == "" {
+		raw = strings.Join(u.Host, ".")
+		if u.Port != "" {
+			raw += ":" + u.Port
+		}
+		if u.Protocol != "" {
+			raw = u.Protocol + "://" + raw
+		}
+		if len(u.Path) > 0 {
+			raw += "/" + strings.Join(u.Path, "/")
+		}
+	}
+
+	// Query and fragment are handled separately
+	if i := strings.IndexAny(raw, "?#"); i >= 0 {
+		raw = raw[:i]
+	}
+
+	start := 0
+	if i := strings.Index(raw, "://"); i >= 0 {
+		start = i + 3
+	}
+	if i := strings.Index(raw[start:], "/"); i >= 0 {
+		return raw[:start+i], raw[start+i:]
+	}
+	return raw, ""
+}
+
+// replacePathVariable replaces a :name path segment
+func replacePathVariable(path, name, value string) string {
+	parts := strings.Split(path, "/")
+	for i, part := range parts {
+		if part == ":"+name {
+			parts[i] = value
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// applyBody fills the request body and a matching Content-Type
+func applyBody(request *models.Request, body *Body) {
+	switch body.Mode {
+	case "raw":
+		request.Body = body.Raw
+		if body.Options != nil {
+			request.BodyLanguage = body.Options.Raw.Language
+			if contentType := models.LanguageContentType(request.BodyLanguage); contentType == "" {
+				request.BodyLanguage = ""
+			} else if !request.HasHeader("Content-Type") {
+				request.Headers["Content-Type"] = contentType
+			}
+		}
+
+	case "urlencoded":
+		request.BodyMode = models.BodyURLEncoded
+		for _, field := range body.URLEncoded {
+			if !field.Disabled && field.Key != "" {
+				request.FormFields = append(request.FormFields, models.FormField{Key: field.Key, Value: field.Value, Type: models.FormFieldText})
+			}
+		}
+
+	case "formdata":
+		request.BodyMode = models.BodyFormData
+		for _, field := range body.FormData {
+			if field.Disabled || field.Key == "" {
+				continue
+			}
+			if field.Type == "file" {
+				request.FormFields = append(request.FormFields, models.FormField{Key: field.Key, Value: field.Src, Type: models.FormFieldFile})
+				continue
+			}
+			request.FormFields = append(request.FormFields, models.FormField{Key: field.Key, Value: field.Value, Type: models.FormFieldText})
+		}
+		// The multipart boundary is generated when the request is sent
+		for key := range request.Headers {
+			if strings.EqualFold(key, "Content-Type") {
+				delete(request.Headers, key)
+			}
+		}
+
+	case "file":
+		request.BodyMode = models.BodyBinary
+		if body.File != nil {
+			request.BodyFile = body.File.Src
+		}
+	}
+}
+
+// convertAuth converts a Postman auth; unsupported types fall back to inheriting
+func convertAuth(auth *Auth) *models.Auth {
+	if auth == nil {
+		return nil
+	}
+
+	switch auth.Type {
+	case "noauth":
+		return &models.Auth{Type: models.AuthNone}
+	case "basic":
+		return &models.Auth{Type: models.AuthBasic, Username: authParam(auth.Basic, "username"), Password: authParam(auth.Basic, "password")}
+	case "digest":
+		return &models.Auth{Type: models.AuthDigest, Username: authParam(auth.Digest, "username"), Password: authParam(auth.Digest, "password")}
+	case "bearer":
+		return &models.Auth{Type: models.AuthBearer, Token: authParam(auth.Bearer, "token")}
+	case "apikey":
+		in := models.APIKeyInHeader
+		if authParam(auth.APIKey, "in") == "query" {
+			in = models.APIKeyInQuery
+		}
+		return &models.Auth{Type: models.AuthAPIKey, Key: authParam(auth.APIKey, "key"), Value: authParam(auth.APIKey, "value"), In: in}
+	case "oauth2":
+		grant := models.GrantAuthorizationCode
+		switch authParam(auth.OAuth2, "grant_type") {
+		case "client_credentials":
+			grant = models.GrantClientCredentials
+		case "password_credentials":
+			grant = models.GrantPassword
+		}
+		return &models.Auth{
+			Type:     models.AuthOAuth2,
+			Username: authParam(auth.OAuth2, "username"),
+			Password: authParam(auth.OAuth2, "password"),
+			OAuth2: &models.OAuth2{
+				Grant:        grant,
+				TokenURL:     authParam(auth.OAuth2, "accessTokenUrl"),
+				AuthURL:      authParam(auth.OAuth2, "authUrl"),
+				RedirectURL:  authParam(auth.OAuth2, "redirect_uri"),
+				ClientID:     authParam(auth.OAuth2, "clientId"),
+				ClientSecret: authParam(auth.OAuth2, "clientSecret"),
+				Scope:        authParam(auth.OAuth2, "scope"),
+			},
+		}
+	}
+	return nil
+}
+
+// ImportEnvironmentFromFile imports a Postman environment file
+func ImportEnvironmentFromFile(filePath string) (*models.CollectionEnvironment, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Postman environment: %w", err)
+	}
+	return ImportEnvironment(data)
+}
+
+// ImportEnvironment converts Postman environment JSON into a collection environment
+func ImportEnvironment(data []byte) (*models.CollectionEnvironment, error) {
+	var pe Environment
+	if err := json.Unmarshal(data, &pe); err != nil {
+		return nil, fmt.Errorf("failed to parse Postman environment: %w", err)
+	}
+
+	if pe.Name == "" {
+		return nil, fmt.Errorf("Postman environment has no name")
+	}
+
+	env := &models.CollectionEnvironment{
+		Name:      pe.Name,
+		Variables: make(map[string]string),
+	}
+	for _, v := range pe.Values {
+		if v.Enabled && v.Key != "" {
+			env.Variables[v.Key] = v.Value
+		}
+	}
+
+	return env, nil
+}
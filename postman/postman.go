@@ -0,0 +1,325 @@
+package postman
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// SchemaV21 is the schema URL written into exported collections
+const SchemaV21 = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// Collection is a Postman v2.1 collection file
+type Collection struct {
+	Info     Info       `json:"info"`
+	Item     []Item     `json:"item"`
+	Auth     *Auth      `json:"auth,omitempty"`
+	Variable []Variable `json:"variable,omitempty"`
+}
+
+// Info holds the collection metadata
+type Info struct {
+	PostmanID   string      `json:"_postman_id,omitempty"`
+	Name        string      `json:"name"`
+	Description Description `json:"description,omitempty"`
+	Schema      string      `json:"schema"`
+}
+
+// Item is either a request or a folder holding more items
+type Item struct {
+	Name        string      `json:"name"`
+	Description Description `json:"description,omitempty"`
+	Item        []Item      `json:"item,omitempty"`
+	Auth        *Auth       `json:"auth,omitempty"` // Folder auth
+	Request     *Request    `json:"request,omitempty"`
+}
+
+// IsFolder reports whether the item groups other items
+func (i Item) IsFolder() bool {
+	return i.Request == nil
+}
+
+// Request is the request part of an item
+type Request struct {
+	Method      string      `json:"method"`
+	Header      []KeyValue  `json:"header"`
+	URL         URL         `json:"url"`
+	Body        *Body       `json:"body,omitempty"`
+	Auth        *Auth       `json:"auth,omitempty"`
+	Description Description `json:"description,omitempty"`
+}
+
+// Auth is the auth of a collection, folder or request
+// Each type keeps its settings as key/value pairs under its own name; no auth means inherit
+type Auth struct {
+	Type   string     `json:"type"`
+	Basic  []KeyValue `json:"basic,omitempty"`
+	Bearer []KeyValue `json:"bearer,omitempty"`
+	APIKey []KeyValue `json:"apikey,omitempty"`
+	Digest []KeyValue `json:"digest,omitempty"`
+	OAuth2 []KeyValue `json:"oauth2,omitempty"`
+}
+
+// authParam returns the value of an auth setting
+func authParam(params []KeyValue, key string) string {
+	for _, p := range params {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// URL is a request URL, which Postman writes either as a string or as an object
+type URL struct {
+	Raw      string     `json:"raw"`
+	Protocol string     `json:"protocol,omitempty"`
+	Host     []string   `json:"host,omitempty"`
+	Port     string     `json:"port,omitempty"`
+	Path     []string   `json:"path,omitempty"`
+	Query    []KeyValue `json:"query,omitempty"`
+	Variable []KeyValue `json:"variable,omitempty"`
+}
+
+// UnmarshalJSON accepts both the string and the object form of a URL
+func (u *URL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		*u = URL{Raw: raw}
+		return nil
+	}
+
+	var obj struct {
+		Raw      string          `json:"raw"`
+		Protocol string          `json:"protocol"`
+		Host     json.RawMessage `json:"host"`
+		Port     string          `json:"port"`
+		Path     json.RawMessage `json:"path"`
+		Query    []KeyValue      `json:"query"`
+		Variable []KeyValue      `json:"variable"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+
+	*u = URL{
+		Raw:      obj.Raw,
+		Protocol: obj.Protocol,
+		Host:     segments(obj.Host, "."),
+		Port:     obj.Port,
+		Path:     segments(obj.Path, "/"),
+		Query:    obj.Query,
+		Variable: obj.Variable,
+	}
+	return nil
+}
+
+// segments decodes a host or path given as a string or a list of strings
+func segments(data json.RawMessage, sep string) []string {
+	if len(data) == 0 {
+		return nil
+	}
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err != nil {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil || s == "" {
+			return nil
+		}
+		return strings.Split(strings.Trim(s, sep), sep)
+	}
+
+	result := []string{}
+	for _, item := range list {
+		var s string
+		if err := json.Unmarshal(item, &s); err == nil {
+			result = append(result, s)
+			continue
+		}
+		// Path segments may also be {"type": "string", "value": "..."} objects
+		var obj struct {
+			Value string `json:"value"`
+		}
+		if err := json.Unmarshal(item, &obj); err == nil {
+			result = append(result, obj.Value)
+		}
+	}
+	return result
+}
+
+// KeyValue is a header, query parameter, path variable or body field
+type KeyValue struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Src      string `json:"src,omitempty"`
+}
+
+// UnmarshalJSON tolerates non-string values and list-valued file sources
+func (kv *KeyValue) UnmarshalJSON(data []byte) error {
+	var obj struct {
+		Key      string          `json:"key"`
+		Value    json.RawMessage `json:"value"`
+		Disabled bool            `json:"disabled"`
+		Type     string          `json:"type"`
+		Src      json.RawMessage `json:"src"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+
+	*kv = KeyValue{
+		Key:      obj.Key,
+		Value:    valueString(obj.Value),
+		Disabled: obj.Disabled,
+		Type:     obj.Type,
+	}
+
+	// File parts list their sources as a string or an array of paths
+	var srcs []string
+	if err := json.Unmarshal(obj.Src, &srcs); err == nil {
+		if len(srcs) > 0 {
+			kv.Src = srcs[0]
+		}
+	} else {
+		kv.Src = valueString(obj.Src)
+	}
+	return nil
+}
+
+// Body is a request body in one of Postman's modes
+type Body struct {
+	Mode       string       `json:"mode"`
+	Raw        string       `json:"raw,omitempty"`
+	URLEncoded []KeyValue   `json:"urlencoded,omitempty"`
+	FormData   []KeyValue   `json:"formdata,omitempty"`
+	File       *BodyFile    `json:"file,omitempty"`
+	Options    *BodyOptions `json:"options,omitempty"`
+}
+
+// BodyFile is the file sent by the "file" body mode
+type BodyFile struct {
+	Src string `json:"src,omitempty"`
+}
+
+// BodyOptions carries the language of raw bodies
+type BodyOptions struct {
+	Raw struct {
+		Language string `json:"language,omitempty"`
+	} `json:"raw"`
+}
+
+// Variable is a collection variable
+type Variable struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Type     string `json:"type,omitempty"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+// UnmarshalJSON tolerates numbers and booleans as variable values
+func (v *Variable) UnmarshalJSON(data []byte) error {
+	var obj struct {
+		Key      string          `json:"key"`
+		Value    json.RawMessage `json:"value"`
+		Type     string          `json:"type"`
+		Disabled bool            `json:"disabled"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*v = Variable{Key: obj.Key, Value: valueString(obj.Value), Type: obj.Type, Disabled: obj.Disabled}
+	return nil
+}
+
+// Description is written by Postman either as a string or as {"content": "..."}
+type Description string
+
+// UnmarshalJSON accepts both description forms
+func (d *Description) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*d = Description(s)
+		return nil
+	}
+
+	var obj struct {
+		Content string `json:"content"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*d = Description(obj.Content)
+	return nil
+}
+
+// Environment is a Postman environment file
+type Environment struct {
+	ID     string             `json:"id,omitempty"`
+	Name   string             `json:"name"`
+	Values []EnvironmentValue `json:"values"`
+	Scope  string             `json:"_postman_variable_scope,omitempty"`
+}
+
+// EnvironmentValue is a single environment variable
+type EnvironmentValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Enabled bool   `json:"enabled"`
+}
+
+// UnmarshalJSON tolerates non-string values and treats a missing enabled flag as enabled
+func (v *EnvironmentValue) UnmarshalJSON(data []byte) error {
+	var obj struct {
+		Key     string          `json:"key"`
+		Value   json.RawMessage `json:"value"`
+		Type    string          `json:"type"`
+		Enabled *bool           `json:"enabled"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*v = EnvironmentValue{Key: obj.Key, Value: valueString(obj.Value), Type: obj.Type, Enabled: true}
+	if obj.Enabled != nil {
+		v.Enabled = *obj.Enabled
+	}
+	return nil
+}
+
+// IsCollection reports whether data looks like a Postman v2 collection
+func IsCollection(data []byte) bool {
+	var probe struct {
+		Info struct {
+			Schema string `json:"schema"`
+		} `json:"info"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return strings.Contains(probe.Info.Schema, "schema.getpostman.com")
+}
+
+// IsEnvironment reports whether data looks like a Postman environment
+func IsEnvironment(data []byte) bool {
+	var probe struct {
+		Scope  string            `json:"_postman_variable_scope"`
+		Values []json.RawMessage `json:"values"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return false
+	}
+	return probe.Scope == "environment" || (probe.Scope == "" && probe.Values != nil)
+}
+
+// valueString renders a JSON scalar as plain text
+func valueString(data json.RawMessage) string {
+	if len(data) == 0 || string(data) == "null" {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		return s
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,45 @@
+package pretty
+
+import (
+	"fmt"
+	"strings"
+)
+
+// hexWidth is the number of bytes per hex dump line
+const hexWidth = 16
+
+// HexLines renders up to limit bytes of data as a hex dump: offset, bytes and their printable characters
+func HexLines(data string, limit int) []Line {
+	data = data[:min(len(data), limit)]
+
+	var lines []Line
+	for offset := 0; offset < len(data); offset += hexWidth {
+		chunk := data[offset:min(offset+hexWidth, len(data))]
+
+		var hex, text strings.Builder
+		for i := 0; i < hexWidth; i++ {
+			if i == hexWidth/2 {
+				hex.WriteByte(' ')
+			}
+			if i < len(chunk) {
+				fmt.Fprintf(&hex, "%02x ", chunk[i])
+			} else {
+				hex.WriteString("   ")
+			}
+		}
+		for i := 0; i < len(chunk); i++ {
+			if c := chunk[i]; c >= 0x20 && c < 0x7f {
+				text.WriteByte(c)
+			} else {
+				text.WriteByte('.')
+			}
+		}
+
+		lines = append(lines, Line{Segments: []Segment{
+			{Text: fmt.Sprintf("%08x  ", offset), Kind: Comment},
+			{Text: hex.String(), Kind: Number},
+			{Text: " |" + text.String() + "|", Kind: String},
+		}})
+	}
+	return lines
+}
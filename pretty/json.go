@@ -0,0 +1,212 @@
+package pretty
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// JSON node types
+const (
+	NodeObject = "object"
+	NodeArray  = "array"
+	NodeString = "string"
+	NodeNumber = "number"
+	NodeBool   = "bool"
+	NodeNull   = "null"
+)
+
+// Node is a JSON value keeping the order of object members
+type Node struct {
+	Type     string
+	Key      string  // Member name when the parent is an object
+	Path     string  // Location like $.data[0].name, "$" for the root
+	Value    string  // JSON text of scalar values
+	Children []*Node // Members of objects, elements of arrays
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+// ParseJSON reads a JSON document into a tree of nodes
+func ParseJSON(body string) (*Node, error) {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	decoder.UseNumber()
+
+	root, err := parseNode(decoder, "$")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after the document")
+	}
+	return root, nil
+}
+
+func parseNode(decoder *json.Decoder, path string) (*Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	node := &Node{Path: path}
+	switch value := token.(type) {
+	case json.Delim:
+		switch value {
+		case '{':
+			node.Type = NodeObject
+			for decoder.More() {
+				keyToken, err := decoder.Token()
+				if err != nil {
+					return nil, fmt.Errorf("invalid JSON: %w", err)
+				}
+				key, _ := keyToken.(string)
+				child, err := parseNode(decoder, MemberPath(path, key))
+				if err != nil {
+					return nil, err
+				}
+				child.Key = key
+				node.Children = append(node.Children, child)
+			}
+		case '[':
+			node.Type = NodeArray
+			for i := 0; decoder.More(); i++ {
+				child, err := parseNode(decoder, fmt.Sprintf("%s[%d]", path, i))
+				if err != nil {
+					return nil, err
+				}
+				node.Children = append(node.Children, child)
+			}
+		default:
+			return nil, fmt.Errorf("invalid JSON: unexpected %s", value)
+		}
+		// Closing delimiter
+		if _, err := decoder.Token(); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	case string:
+		node.Type = NodeString
+		node.Value = quote(value)
+	case json.Number:
+		node.Type = NodeNumber
+		node.Value = value.String()
+	case bool:
+		node.Type = NodeBool
+		node.Value = strconv.FormatBool(value)
+	case nil:
+		node.Type = NodeNull
+		node.Value = "null"
+	}
+	return node, nil
+}
+
+// MemberPath returns the path of an object member
+func MemberPath(parent, key string) string {
+	if identifierPattern.MatchString(key) {
+		return parent + "." + key
+	}
+	return parent + "[" + strconv.Quote(key) + "]"
+}
+
+// quote encodes a string as JSON without escaping HTML characters
+func quote(value string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// RenderJSON pretty-prints a JSON tree; nodes whose path is in collapsed are folded
+func RenderJSON(root *Node, collapsed map[string]bool) []Line {
+	var lines []Line
+	var render func(node *Node, depth int, key bool, last bool)
+	render = func(node *Node, depth int, key bool, last bool) {
+		var head []Segment
+		if depth > 0 {
+			head = append(head, Segment{Text: strings.Repeat("  ", depth), Kind: Plain})
+		}
+		if key {
+			head = append(head, Segment{Text: quote(node.Key), Kind: Key}, Segment{Text: ": ", Kind: Punct})
+		}
+		var tail []Segment
+		if !last {
+			tail = append(tail, Segment{Text: ",", Kind: Punct})
+		}
+
+		open, close := "{", "}"
+		if node.Type == NodeArray {
+			open, close = "[", "]"
+		}
+
+		switch {
+		case node.Type != NodeObject && node.Type != NodeArray:
+			segments := append(head, Segment{Text: node.Value, Kind: scalarKind(node.Type)})
+			lines = append(lines, Line{Segments: append(segments, tail...)})
+		case len(node.Children) == 0:
+			segments := append(head, Segment{Text: open + close, Kind: Punct})
+			lines = append(lines, Line{Segments: append(segments, tail...)})
+		case collapsed[node.Path]:
+			segments := append(head, Segment{Text: open + "…" + close, Kind: Punct})
+			segments = append(segments, tail...)
+			segments = append(segments, Segment{Text: " " + summary(node), Kind: Comment})
+			lines = append(lines, Line{Segments: segments, Path: node.Path, Collapsed: true})
+		default:
+			lines = append(lines, Line{Segments: append(head, Segment{Text: open, Kind: Punct}), Path: node.Path})
+			for i, child := range node.Children {
+				render(child, depth+1, node.Type == NodeObject, i == len(node.Children)-1)
+			}
+			closing := []Segment{{Text: close, Kind: Punct}}
+			if depth > 0 {
+				closing = append([]Segment{{Text: strings.Repeat("  ", depth), Kind: Plain}}, closing...)
+			}
+			lines = append(lines, Line{Segments: append(closing, tail...)})
+		}
+	}
+	render(root, 0, false, true)
+	return lines
+}
+
+// ContainerPaths lists the paths of the non-empty objects and arrays of a tree
+func ContainerPaths(root *Node) []string {
+	var paths []string
+	var walk func(node *Node)
+	walk = func(node *Node) {
+		if len(node.Children) == 0 {
+			return
+		}
+		paths = append(paths, node.Path)
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	walk(root)
+	return paths
+}
+
+func scalarKind(nodeType string) Kind {
+	switch nodeType {
+	case NodeString:
+		return String
+	case NodeNumber:
+		return Number
+	case NodeBool:
+		return Bool
+	}
+	return Null
+}
+
+// summary describes the size of a folded node
+func summary(node *Node) string {
+	unit := "items"
+	if node.Type == NodeObject {
+		unit = "keys"
+	}
+	if len(node.Children) == 1 {
+		unit = strings.TrimSuffix(unit, "s")
+	}
+	return fmt.Sprintf("%d %s", len(node.Children), unit)
+}
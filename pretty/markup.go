@@ -0,0 +1,140 @@
+package pretty
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// voidElements are HTML elements without a closing tag
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// RenderMarkup pretty-prints an XML or HTML document, one element per line
+// Elements holding only text stay on a single line
+func RenderMarkup(body string, html bool) ([]Line, error) {
+	decoder := xml.NewDecoder(strings.NewReader(body))
+	if html {
+		decoder.Strict = false
+		decoder.AutoClose = xml.HTMLAutoClose
+		decoder.Entity = xml.HTMLEntity
+	}
+
+	var tokens []xml.Token
+	for {
+		var token xml.Token
+		var err error
+		if html {
+			token, err = decoder.Token()
+		} else {
+			// RawToken keeps namespace prefixes as written
+			token, err = decoder.RawToken()
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid markup: %w", err)
+		}
+		tokens = append(tokens, xml.CopyToken(token))
+	}
+
+	var lines []Line
+	depth := 0
+	add := func(segments ...Segment) {
+		if depth > 0 {
+			segments = append([]Segment{{Text: strings.Repeat("  ", depth), Kind: Plain}}, segments...)
+		}
+		lines = append(lines, Line{Segments: segments})
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		switch token := tokens[i].(type) {
+		case xml.StartElement:
+			open := startTag(token)
+			name := tagName(token.Name)
+
+			if html && voidElements[strings.ToLower(name)] {
+				add(open...)
+				if i+1 < len(tokens) {
+					if end, ok := tokens[i+1].(xml.EndElement); ok && end.Name == token.Name {
+						i++
+					}
+				}
+				continue
+			}
+
+			// <a/>, and <a>text</a> on a single line
+			if i+1 < len(tokens) {
+				if _, ok := tokens[i+1].(xml.EndElement); ok {
+					if html {
+						add(append(open, Segment{Text: "</" + name + ">", Kind: Tag})...)
+					} else {
+						open[len(open)-1].Text = "/>"
+						add(open...)
+					}
+					i++
+					continue
+				}
+			}
+			if i+2 < len(tokens) {
+				text, isText := tokens[i+1].(xml.CharData)
+				_, isEnd := tokens[i+2].(xml.EndElement)
+				if isText && isEnd && !strings.Contains(strings.TrimSpace(string(text)), "\n") {
+					segments := append(open, Segment{Text: strings.TrimSpace(string(text)), Kind: Plain})
+					add(append(segments, Segment{Text: "</" + name + ">", Kind: Tag})...)
+					i += 2
+					continue
+				}
+			}
+
+			add(open...)
+			depth++
+		case xml.EndElement:
+			if depth > 0 {
+				depth--
+			}
+			add(Segment{Text: "</" + tagName(token.Name) + ">", Kind: Tag})
+		case xml.CharData:
+			for _, text := range strings.Split(string(token), "\n") {
+				if text = strings.TrimSpace(text); text != "" {
+					add(Segment{Text: text, Kind: Plain})
+				}
+			}
+		case xml.Comment:
+			add(Segment{Text: "<!--" + string(token) + "-->", Kind: Comment})
+		case xml.ProcInst:
+			add(Segment{Text: "<?" + token.Target + " " + string(token.Inst) + "?>", Kind: Comment})
+		case xml.Directive:
+			add(Segment{Text: "<!" + string(token) + ">", Kind: Comment})
+		}
+	}
+
+	return lines, nil
+}
+
+// startTag renders an opening tag; its last segment is the closing ">"
+func startTag(element xml.StartElement) []Segment {
+	segments := []Segment{{Text: "<" + tagName(element.Name), Kind: Tag}}
+	for _, attr := range element.Attr {
+		segments = append(segments,
+			Segment{Text: " " + tagName(attr.Name), Kind: Attr},
+			Segment{Text: "=", Kind: Punct},
+			Segment{Text: `"` + escapeAttr(attr.Value) + `"`, Kind: String})
+	}
+	return append(segments, Segment{Text: ">", Kind: Tag})
+}
+
+func tagName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+func escapeAttr(value string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;").Replace(value)
+}
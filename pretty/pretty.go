@@ -0,0 +1,108 @@
+package pretty
+
+import (
+	"encoding/json"
+	"mime"
+	"strings"
+)
+
+// Body formats detected by Detect
+const (
+	FormatJSON = "json"
+	FormatXML  = "xml"
+	FormatHTML = "html"
+	FormatText = "text"
+)
+
+// Kind classifies a piece of a formatted line for highlighting
+type Kind int
+
+const (
+	Plain   Kind = iota
+	Key          // JSON member name
+	String       // JSON string, markup attribute value
+	Number       // JSON number
+	Bool         // JSON true/false
+	Null         // JSON null
+	Punct        // Brackets, braces, commas and colons
+	Tag          // Markup tag, with its angle brackets
+	Attr         // Markup attribute name
+	Comment      // Markup comment, folded node summary
+)
+
+// Segment is a run of text of a single kind
+type Segment struct {
+	Text string
+	Kind Kind
+}
+
+// Line is a formatted line of a body
+type Line struct {
+	Segments  []Segment
+	Path      string // JSON node opening on this line, which can be folded; "" otherwise
+	Collapsed bool   // Whether the node of Path is folded
+}
+
+// Text returns the line without highlighting
+func (l Line) Text() string {
+	var s strings.Builder
+	for _, segment := range l.Segments {
+		s.WriteString(segment.Text)
+	}
+	return s.String()
+}
+
+// Detect guesses the format of a body from its content type, then from its content
+func Detect(contentType, body string) string {
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch {
+		case strings.Contains(mediaType, "json"):
+			return FormatJSON
+		case strings.Contains(mediaType, "html"):
+			return FormatHTML
+		case strings.Contains(mediaType, "xml"):
+			return FormatXML
+		}
+	}
+
+	trimmed := strings.TrimSpace(body)
+	switch {
+	case strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "["):
+		if json.Valid([]byte(trimmed)) {
+			return FormatJSON
+		}
+	case strings.HasPrefix(trimmed, "<"):
+		lower := strings.ToLower(trimmed[:min(len(trimmed), 512)])
+		if strings.Contains(lower, "<!doctype html") || strings.Contains(lower, "<html") {
+			return FormatHTML
+		}
+		return FormatXML
+	}
+	return FormatText
+}
+
+// Format pretty-prints a body of the given format
+// Bodies that fail to parse are returned as plain text
+func Format(format, body string, collapsed map[string]bool) []Line {
+	switch format {
+	case FormatJSON:
+		if root, err := ParseJSON(body); err == nil {
+			return RenderJSON(root, collapsed)
+		}
+	case FormatXML, FormatHTML:
+		if lines, err := RenderMarkup(body, format == FormatHTML); err == nil {
+			return lines
+		}
+	}
+	return PlainLines(body)
+}
+
+// PlainLines splits a body into unhighlighted lines
+func PlainLines(body string) []Line {
+	body = strings.ReplaceAll(body, "\r\n", "\n")
+	var lines []Line
+	for _, text := range strings.Split(body, "\n") {
+		lines = append(lines, Line{Segments: []Segment{{Text: strings.ReplaceAll(text, "\t", "    "), Kind: Plain}}})
+	}
+	return lines
+}
@@ -0,0 +1,441 @@
+package secrets
+
+import (
+	"github.com/leobrines/curlman/storage"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Marker stands for the value of a secret variable in collection, environment and global files,
+// and replaces secret values in masked output
+const Marker = "[secret]"
+
+// PassphraseEnv names the environment variable holding the passphrase secrets are encrypted with
+// Without it when the first secret is stored, a random key is kept in a local keyfile instead
+const PassphraseEnv = "CURLMAN_PASSPHRASE"
+
+// MinMaskLength is the length under which secret values are not masked, as they would mask unrelated text
+const MinMaskLength = 4
+
+const (
+	storeFileName = "store.json"
+	keyFileName   = "secret.key"
+	iterations    = 600000
+	checkText     = "curlman"
+)
+
+// Key sources of a store
+const (
+	kdfPassphrase = "pbkdf2-sha256"
+	kdfKeyFile    = "keyfile"
+)
+
+// store is the content of the store file: values sealed with AES-256-GCM, by scope then variable name
+type store struct {
+	KDF        string                       `json:"kdf"`
+	Salt       string                       `json:"salt,omitempty"`
+	Iterations int                          `json:"iterations,omitempty"`
+	Check      string                       `json:"check"` // Sealed checkText, to tell a wrong passphrase
+	Values     map[string]map[string]string `json:"values"`
+}
+
+var (
+	mu   sync.Mutex
+	keys = make(map[string][]byte) // Derived keys by passphrase and salt, as derivation is slow on purpose
+)
+
+// GlobalScope holds the secrets of global variables
+const GlobalScope = "global"
+
+// CollectionScope holds the secrets of the variables of a collection, keyed by its file
+func CollectionScope(collectionFile string) string {
+	return "collection/" + strings.TrimSuffix(collectionFile, ".json")
+}
+
+// EnvironmentScope holds the secrets of a global environment
+func EnvironmentScope(environment string) string {
+	return "environment/" + environment
+}
+
+// CollectionEnvironmentScope holds the secrets of an environment of a collection, keyed by the collection file
+func CollectionEnvironmentScope(collectionFile, environment string) string {
+	return CollectionScope(collectionFile) + "/environment/" + environment
+}
+
+// Values returns the secrets of a scope
+func Values(scope string) (map[string]string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s, key, err := open(false)
+	if err != nil || s == nil {
+		return map[string]string{}, err
+	}
+
+	values := make(map[string]string, len(s.Values[scope]))
+	for name, sealed := range s.Values[scope] {
+		value, err := unseal(key, sealed, scope+"\x00"+name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt secret %s: %w", name, err)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// Set stores a secret, creating the store and its key on first use
+func Set(scope, name, value string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s, key, err := open(true)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := seal(key, value, scope+"\x00"+name)
+	if err != nil {
+		return err
+	}
+	if s.Values[scope] == nil {
+		s.Values[scope] = make(map[string]string)
+	}
+	s.Values[scope][name] = sealed
+	return save(s)
+}
+
+// Delete removes a secret
+func Delete(scope, name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s, _, err := open(false)
+	if err != nil || s == nil || s.Values[scope][name] == "" {
+		return err
+	}
+
+	delete(s.Values[scope], name)
+	if len(s.Values[scope]) == 0 {
+		delete(s.Values, scope)
+	}
+	return save(s)
+}
+
+// Rename moves the secrets of a scope, and of the scopes nested in it, to another scope
+// Values are sealed with their scope, so they are decrypted and sealed again
+func Rename(from, to string) error {
+	return transfer(from, to, true)
+}
+
+// Copy copies the secrets of a scope, and of the scopes nested in it, to another scope
+func Copy(from, to string) error {
+	return transfer(from, to, false)
+}
+
+// Clear removes the secrets of a scope and of the scopes nested in it
+func Clear(scope string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s, _, err := open(false)
+	if err != nil || s == nil {
+		return err
+	}
+
+	scopes := nested(s, scope)
+	if len(scopes) == 0 {
+		return nil
+	}
+	for _, scope := range scopes {
+		delete(s.Values, scope)
+	}
+	return save(s)
+}
+
+// nested returns a scope and the scopes nested in it that hold secrets, sorted
+func nested(s *store, scope string) []string {
+	var scopes []string
+	for name := range s.Values {
+		if name == scope || strings.HasPrefix(name, scope+"/") {
+			scopes = append(scopes, name)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+func transfer(from, to string, move bool) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s, key, err := open(false)
+	if err != nil || s == nil {
+		return err
+	}
+
+	scopes := nested(s, from)
+	if len(scopes) == 0 {
+		return nil
+	}
+
+	for _, scope := range scopes {
+		moved := to + strings.TrimPrefix(scope, from)
+		for name, sealed := range s.Values[scope] {
+			value, err := unseal(key, sealed, scope+"\x00"+name)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt secret %s: %w", name, err)
+			}
+			if sealed, err = seal(key, value, moved+"\x00"+name); err != nil {
+				return err
+			}
+			if s.Values[moved] == nil {
+				s.Values[moved] = make(map[string]string)
+			}
+			s.Values[moved][name] = sealed
+		}
+		if move {
+			delete(s.Values, scope)
+		}
+	}
+	return save(s)
+}
+
+// Mask replaces the secret values found in a text with Marker, as written, URL-encoded or base64-encoded
+// like in a Basic auth header; values shorter than MinMaskLength are left alone
+func Mask(text string, values []string) string {
+	var forms []string
+	for _, value := range values {
+		if len(value) >= MinMaskLength {
+			forms = append(forms, value, url.QueryEscape(value), url.PathEscape(value))
+			forms = append(forms, base64Forms(value)...)
+		}
+	}
+	// Longer forms first, so a secret containing another one is masked whole
+	sort.Slice(forms, func(a, b int) bool { return len(forms[a]) > len(forms[b]) })
+	for _, form := range forms {
+		text = strings.ReplaceAll(text, form, Marker)
+	}
+	return text
+}
+
+// base64Forms returns the parts of the base64 encoding of a value that do not depend on the text
+// around it, like a username before a password, for each of the three positions it can take
+func base64Forms(value string) []string {
+	var forms []string
+	for offset := 0; offset < 3; offset++ {
+		encoded := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("\x00", offset) + value))
+		// Characters holding bits of the text around the value are left out
+		start := (8*offset + 5) / 6
+		end := 8 * (offset + len(value)) / 6
+		form := encoded[start:end]
+		forms = append(forms, form)
+		if urlSafe := strings.NewReplacer("+", "-", "/", "_").Replace(form); urlSafe != form {
+			forms = append(forms, urlSafe)
+		}
+	}
+	return forms
+}
+
+// GetSecretsDir returns the directory holding the secret store and its keyfile, creating it if needed
+// It is kept apart from the collection files, so the store is never listed or deleted as a collection
+func GetSecretsDir() (string, error) {
+	storageDir, err := storage.GetStorageDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get storage directory: %w", err)
+	}
+
+	dir := filepath.Join(storageDir, "secrets")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create secrets directory: %w", err)
+	}
+	return dir, nil
+}
+
+// open reads the store and derives its key; without create, a missing store is nil
+func open(create bool) (*store, []byte, error) {
+	dir, err := GetSecretsDir()
+	if err != nil {
+		return nil, nil, err
+	}
+	path := filepath.Join(dir, storeFileName)
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		if !create {
+			return nil, nil, nil
+		}
+		return newStore()
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+
+	var s store
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse secrets: %w", err)
+	}
+	if s.Values == nil {
+		s.Values = make(map[string]map[string]string)
+	}
+
+	key, err := s.key()
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := unseal(key, s.Check, "check"); err != nil {
+		if s.KDF == kdfPassphrase {
+			return nil, nil, fmt.Errorf("wrong passphrase in %s", PassphraseEnv)
+		}
+		return nil, nil, fmt.Errorf("keyfile does not match the stored secrets")
+	}
+	return &s, key, nil
+}
+
+// newStore starts a store keyed by the passphrase when one is set, else by a new keyfile
+func newStore() (*store, []byte, error) {
+	s := &store{KDF: kdfKeyFile, Values: make(map[string]map[string]string)}
+	if os.Getenv(PassphraseEnv) != "" {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate salt: %w", err)
+		}
+		s.KDF = kdfPassphrase
+		s.Salt = base64.StdEncoding.EncodeToString(salt)
+		s.Iterations = iterations
+	}
+
+	key, err := s.key()
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.Check, err = seal(key, checkText, "check"); err != nil {
+		return nil, nil, err
+	}
+	return s, key, nil
+}
+
+// key derives the key from the passphrase, or reads the keyfile, creating it when missing
+func (s *store) key() ([]byte, error) {
+	switch s.KDF {
+	case kdfPassphrase:
+		passphrase := os.Getenv(PassphraseEnv)
+		if passphrase == "" {
+			return nil, fmt.Errorf("secrets are encrypted with a passphrase: set %s", PassphraseEnv)
+		}
+		cacheKey := passphrase + "\x00" + s.Salt
+		if key, ok := keys[cacheKey]; ok {
+			return key, nil
+		}
+		salt, err := base64.StdEncoding.DecodeString(s.Salt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid salt in secrets: %w", err)
+		}
+		key, err := pbkdf2.Key(sha256.New, passphrase, salt, s.Iterations, 32)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive key: %w", err)
+		}
+		keys[cacheKey] = key
+		return key, nil
+
+	case kdfKeyFile:
+		return keyFile()
+	}
+	return nil, fmt.Errorf("unknown key source %q in secrets", s.KDF)
+}
+
+// keyFile reads the local key, generating it on first use
+func keyFile() ([]byte, error) {
+	dir, err := GetSecretsDir()
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, keyFileName)
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("invalid keyfile %s", path)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read keyfile: %w", err)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write keyfile: %w", err)
+	}
+	return key, nil
+}
+
+func save(s *store) error {
+	dir, err := GetSecretsDir()
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, storeFileName)
+
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode secrets: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write secrets: %w", err)
+	}
+	return nil
+}
+
+// seal encrypts a value, bound to its scope and name so sealed values cannot be swapped
+func seal(key []byte, value, label string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(value), []byte(label))), nil
+}
+
+func unseal(key []byte, sealed, label string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", errors.New("malformed value")
+	}
+	value, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(label))
+	if err != nil {
+		return "", errors.New("cannot decrypt value")
+	}
+	return string(value), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
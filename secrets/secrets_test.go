@@ -0,0 +1,93 @@
+package secrets
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestMask(t *testing.T) {
+	values := []string{"s3cr3t+pass/word", "abc"}
+	basic := base64.StdEncoding.EncodeToString([]byte("ann:s3cr3t+pass/word"))
+
+	tests := []struct {
+		name string
+		text string
+	}{
+		{"plain", `{"password":"s3cr3t+pass/word"}`},
+		{"query escaped", "password=s3cr3t%2Bpass%2Fword"},
+		{"path escaped", "/login/s3cr3t+pass%2Fword"},
+		{"basic auth", "Authorization: Basic " + basic},
+		{"base64", base64.StdEncoding.EncodeToString([]byte("s3cr3t+pass/word"))},
+		{"base64 after two bytes", base64.StdEncoding.EncodeToString([]byte("ab" + "s3cr3t+pass/word" + "cd"))},
+		{"base64url", base64.URLEncoding.EncodeToString([]byte("x:s3cr3t+pass/word"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			masked := Mask(tt.text, values)
+			if !strings.Contains(masked, Marker) {
+				t.Errorf("Mask(%q) = %q, want the secret masked", tt.text, masked)
+			}
+		})
+	}
+
+	// Short values would mask unrelated text
+	if got := Mask("abcdef", values); got != "abcdef" {
+		t.Errorf("Mask with a short value = %q, want the text unchanged", got)
+	}
+	if got := Mask("Basic "+base64.StdEncoding.EncodeToString([]byte("ann:other")), values); strings.Contains(got, Marker) {
+		t.Errorf("Mask without the secret = %q, want the text unchanged", got)
+	}
+}
+
+func TestScopes(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(PassphraseEnv, "")
+
+	if CollectionScope("shop.json") != CollectionScope("shop") {
+		t.Errorf("collection scopes differ with and without the .json extension")
+	}
+
+	from := CollectionScope("shop.json")
+	env := CollectionEnvironmentScope("shop.json", "dev")
+	if err := Set(from, "token", "t0ken"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := Set(env, "key", "k3y"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	to := CollectionScope("copy.json")
+	if err := Copy(from, to); err != nil {
+		t.Fatalf("Copy: %v", err)
+	}
+	for _, scope := range []string{from, to} {
+		if values, err := Values(scope); err != nil || values["token"] != "t0ken" {
+			t.Errorf("Values(%s) = %v, %v after Copy", scope, values, err)
+		}
+	}
+	if values, err := Values(CollectionEnvironmentScope("copy.json", "dev")); err != nil || values["key"] != "k3y" {
+		t.Errorf("nested scope after Copy = %v, %v", values, err)
+	}
+
+	moved := CollectionScope("store.json")
+	if err := Rename(from, moved); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	if values, _ := Values(from); len(values) != 0 {
+		t.Errorf("old scope after Rename = %v, want no secrets", values)
+	}
+	if values, err := Values(moved); err != nil || values["token"] != "t0ken" {
+		t.Errorf("new scope after Rename = %v, %v", values, err)
+	}
+
+	if err := Clear(to); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if values, _ := Values(CollectionEnvironmentScope("copy.json", "dev")); len(values) != 0 {
+		t.Errorf("nested scope after Clear = %v, want no secrets", values)
+	}
+	if values, _ := Values(moved); values["token"] != "t0ken" {
+		t.Errorf("Clear removed the secrets of another scope")
+	}
+}
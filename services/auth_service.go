@@ -0,0 +1,176 @@
+package services
+
+import (
+	"github.com/leobrines/curlman/models"
+	"github.com/leobrines/curlman/oauth2"
+	"context"
+	"fmt"
+)
+
+// AuthService handles auth-related business logic
+type AuthService struct{}
+
+// NewAuthService creates a new auth service
+func NewAuthService() *AuthService {
+	return &AuthService{}
+}
+
+// parse reads an auth definition, returning nil for "inherit"
+func (s *AuthService) parse(text string) (*models.Auth, error) {
+	auth, err := models.ParseAuth(text)
+	if err != nil {
+		return nil, err
+	}
+	if auth.IsInherited() {
+		return nil, nil
+	}
+	return auth, nil
+}
+
+// SetRequestAuth sets the auth of a request from a definition like "bearer {{token}}"
+func (s *AuthService) SetRequestAuth(request *models.Request, text string) error {
+	if request == nil {
+		return fmt.Errorf("request cannot be nil")
+	}
+
+	auth, err := s.parse(text)
+	if err != nil {
+		return err
+	}
+
+	request.Auth = auth
+	return nil
+}
+
+// SetFolderAuth sets the auth inherited by the requests of a folder
+func (s *AuthService) SetFolderAuth(collection *models.Collection, path, text string) error {
+	if collection == nil {
+		return fmt.Errorf("collection cannot be nil")
+	}
+
+	path = models.JoinFolderPath(path)
+	if path == "" {
+		return fmt.Errorf("folder name cannot be empty")
+	}
+
+	auth, err := s.parse(text)
+	if err != nil {
+		return err
+	}
+
+	collection.AddFolder(path).Auth = auth
+	return nil
+}
+
+// SetCollectionAuth sets the auth inherited by every request of the collection
+func (s *AuthService) SetCollectionAuth(collection *models.Collection, text string) error {
+	if collection == nil {
+		return fmt.Errorf("collection cannot be nil")
+	}
+
+	auth, err := s.parse(text)
+	if err != nil {
+		return err
+	}
+
+	collection.Auth = auth
+	return nil
+}
+
+// DescribeAuth tells which auth a request uses and where it comes from
+func (s *AuthService) DescribeAuth(collection *models.Collection, request *models.Request) string {
+	if !request.Auth.IsInherited() {
+		return request.Auth.String()
+	}
+
+	for folder := request.Folder; folder != ""; folder = models.FolderParent(folder) {
+		if f := collection.GetFolder(folder); f != nil && !f.Auth.IsInherited() {
+			return fmt.Sprintf("%s (inherited from folder %s)", f.Auth, folder)
+		}
+	}
+	if collection.Auth.IsSet() {
+		return fmt.Sprintf("%s (inherited from collection)", collection.Auth)
+	}
+	return "none"
+}
+
+// oauth2Auth returns the OAuth2 auth of a request with its variables injected
+func (s *AuthService) oauth2Auth(collection *models.Collection, request *models.Request, variables map[string]string) (*models.Auth, error) {
+	auth := collection.EffectiveAuth(request)
+	if auth == nil || auth.Type != models.AuthOAuth2 {
+		return nil, fmt.Errorf("request '%s' does not use OAuth2 auth", request.Name)
+	}
+	return auth.InjectVariables(variables)
+}
+
+// AuthorizeOAuth2 obtains a new token for the OAuth2 auth of a request, through its client settings
+// The authorization code grant hands its authorization URL to open and waits for the callback
+func (s *AuthService) AuthorizeOAuth2(ctx context.Context, collection *models.Collection, request *models.Request, variables map[string]string, open func(authURL string)) (*oauth2.Token, error) {
+	auth, err := s.oauth2Auth(collection, request, variables)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := collection.Resolve(request).Settings.InjectVariables(variables)
+	if err != nil {
+		return nil, err
+	}
+	return oauth2.Authorize(ctx, auth, settings, open)
+}
+
+// ClearOAuth2Token forgets the cached token of the OAuth2 auth of a request
+func (s *AuthService) ClearOAuth2Token(collection *models.Collection, request *models.Request, variables map[string]string) error {
+	auth, err := s.oauth2Auth(collection, request, variables)
+	if err != nil {
+		return err
+	}
+	return oauth2.ClearToken(auth)
+}
+
+// OAuth2TokenPlaceholder stands for the access token in exported requests when no valid token is cached
+const OAuth2TokenPlaceholder = "{{oauth2 token}}"
+
+// withCachedOAuth2Token swaps the OAuth2 auth of a request with variables already injected for a Bearer auth
+// carrying the cached access token, or OAuth2TokenPlaceholder when none is valid; it never requests a token
+func withCachedOAuth2Token(request *models.Request) *models.Request {
+	if request.Auth == nil || request.Auth.Type != models.AuthOAuth2 {
+		return request
+	}
+
+	token := OAuth2TokenPlaceholder
+	if cached := oauth2.CachedToken(request.Auth); cached.Valid() {
+		token = cached.AccessToken
+	}
+	withToken := request.Clone()
+	withToken.ID = request.ID
+	withToken.Name = request.Name
+	withToken.Auth = &models.Auth{Type: models.AuthBearer, Token: token}
+	return withToken
+}
+
+// withOAuth2Token swaps an OAuth2 auth for a Bearer auth carrying a valid access token,
+// using the cached token, refreshing it or requesting a new one as needed
+// The token endpoint is called with the client settings of the request
+func withOAuth2Token(ctx context.Context, request *models.Request, variables map[string]string) (*models.Request, error) {
+	if request.Auth == nil || request.Auth.Type != models.AuthOAuth2 {
+		return request, nil
+	}
+
+	auth, err := request.Auth.InjectVariables(variables)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := request.Settings.InjectVariables(variables)
+	if err != nil {
+		return nil, err
+	}
+	token, err := oauth2.GetToken(ctx, auth, settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OAuth2 token: %w", err)
+	}
+
+	withToken := request.Clone()
+	withToken.ID = request.ID
+	withToken.Name = request.Name
+	withToken.Auth = &models.Auth{Type: models.AuthBearer, Token: token.AccessToken}
+	return withToken, nil
+}
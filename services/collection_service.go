@@ -1,8 +1,13 @@
 package services
 
 import (
+	"github.com/leobrines/curlman/cookies"
+	"github.com/leobrines/curlman/har"
+	"github.com/leobrines/curlman/insomnia"
 	"github.com/leobrines/curlman/models"
 	"github.com/leobrines/curlman/openapi"
+	"github.com/leobrines/curlman/postman"
+	"github.com/leobrines/curlman/secrets"
 	"github.com/leobrines/curlman/storage"
 	"fmt"
 	"os"
@@ -33,6 +38,61 @@ func (s *CollectionService) CreateEmptyCollection() *models.Collection {
 	}
 }
 
+// Import formats recognized by DetectImportFormat
+const (
+	ImportFormatOpenAPI            = "openapi"
+	ImportFormatPostman            = "postman"
+	ImportFormatPostmanEnvironment = "postman-env"
+	ImportFormatInsomnia           = "insomnia"
+	ImportFormatHAR                = "har"
+)
+
+// DetectImportFormat inspects a file and reports which importer understands it
+func (s *CollectionService) DetectImportFormat(filePath string) (string, error) {
+	if filePath == "" {
+		return "", fmt.Errorf("file path cannot be empty")
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	switch {
+	case postman.IsCollection(data):
+		return ImportFormatPostman, nil
+	case postman.IsEnvironment(data):
+		return ImportFormatPostmanEnvironment, nil
+	case insomnia.IsExport(data):
+		return ImportFormatInsomnia, nil
+	case har.IsHAR(data):
+		return ImportFormatHAR, nil
+	}
+
+	// Anything else is handed to the OpenAPI loader, which reads YAML and JSON
+	return ImportFormatOpenAPI, nil
+}
+
+// ImportFromFile imports a collection file of any supported format and auto-saves it
+func (s *CollectionService) ImportFromFile(filePath string) (*models.Collection, string, error) {
+	format, err := s.DetectImportFormat(filePath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch format {
+	case ImportFormatPostman:
+		return s.ImportFromPostman(filePath)
+	case ImportFormatInsomnia:
+		return s.ImportFromInsomnia(filePath)
+	case ImportFormatHAR:
+		return s.ImportFromHAR(filePath)
+	case ImportFormatOpenAPI:
+		return s.ImportFromOpenAPI(filePath)
+	}
+	return nil, "", fmt.Errorf("%s files are imported into an existing collection", format)
+}
+
 // ImportFromOpenAPI imports a collection from an OpenAPI file and auto-saves it
 func (s *CollectionService) ImportFromOpenAPI(filePath string) (*models.Collection, string, error) {
 	if filePath == "" {
@@ -44,11 +104,83 @@ func (s *CollectionService) ImportFromOpenAPI(filePath string) (*models.Collecti
 		return nil, "", fmt.Errorf("failed to import OpenAPI file: %w", err)
 	}
 
+	return s.saveImported(collection)
+}
+
+// ImportFromPostman imports a Postman v2.1 collection file and auto-saves it
+func (s *CollectionService) ImportFromPostman(filePath string) (*models.Collection, string, error) {
+	if filePath == "" {
+		return nil, "", fmt.Errorf("file path cannot be empty")
+	}
+
+	collection, err := postman.ImportFromFile(filePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to import Postman collection: %w", err)
+	}
+
+	return s.saveImported(collection)
+}
+
+// ImportFromInsomnia imports an Insomnia v4 export file and auto-saves it
+func (s *CollectionService) ImportFromInsomnia(filePath string) (*models.Collection, string, error) {
+	if filePath == "" {
+		return nil, "", fmt.Errorf("file path cannot be empty")
+	}
+
+	collection, err := insomnia.ImportFromFile(filePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to import Insomnia export: %w", err)
+	}
+
+	return s.saveImported(collection)
+}
+
+// ImportFromHAR imports the requests recorded in a HAR file and auto-saves them
+func (s *CollectionService) ImportFromHAR(filePath string) (*models.Collection, string, error) {
+	if filePath == "" {
+		return nil, "", fmt.Errorf("file path cannot be empty")
+	}
+
+	collection, err := har.ImportFromFile(filePath)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to import HAR file: %w", err)
+	}
+
+	return s.saveImported(collection)
+}
+
+// ExportToPostman converts a collection into Postman v2.1 collection JSON
+func (s *CollectionService) ExportToPostman(collection *models.Collection) (string, error) {
+	if collection == nil {
+		return "", fmt.Errorf("collection cannot be nil")
+	}
+
+	data, err := postman.Export(collection)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// ExportToPostmanFile writes a collection as a Postman v2.1 collection file
+func (s *CollectionService) ExportToPostmanFile(collection *models.Collection, filePath string) error {
+	if collection == nil {
+		return fmt.Errorf("collection cannot be nil")
+	}
+	if filePath == "" {
+		return fmt.Errorf("file path cannot be empty")
+	}
+
+	return postman.ExportToFile(collection, filePath)
+}
+
+// saveImported saves a freshly imported collection, named after its title
+func (s *CollectionService) saveImported(collection *models.Collection) (*models.Collection, string, error) {
 	if len(collection.Requests) == 0 {
 		return nil, "", fmt.Errorf("imported collection contains no requests")
 	}
 
-	// Auto-save the collection with the OpenAPI title as filename
+	// Auto-save the collection with its title as filename
 	fileName := sanitizeFileName(collection.Name)
 	if fileName == "" {
 		fileName = "imported-collection"
@@ -113,6 +245,7 @@ func (s *CollectionService) SaveCollection(collection *models.Collection, fileNa
 	if err != nil {
 		return "", fmt.Errorf("failed to save collection: %w", err)
 	}
+	collection.File = fileName
 
 	return fullPath, nil
 }
@@ -147,6 +280,7 @@ func (s *CollectionService) LoadCollection(fileName string) (*models.Collection,
 	if err != nil {
 		return nil, fmt.Errorf("failed to load collection: %w", err)
 	}
+	collection.File = fileName
 
 	return collection, nil
 }
@@ -165,6 +299,10 @@ func (s *CollectionService) ListCollections() ([]string, error) {
 
 	var collections []string
 	for _, entry := range entries {
+		// global.json holds the global config, not a collection
+		if entry.Name() == "global.json" {
+			continue
+		}
 		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
 			collections = append(collections, entry.Name())
 		}
@@ -217,3 +355,151 @@ func (s *CollectionService) GetCollectionStats(collection *models.Collection) ma
 		"active_collection_env":   collection.ActiveCollectionEnv,
 	}
 }
+
+// CollectionFileName returns the storage file name used for a collection name
+func (s *CollectionService) CollectionFileName(name string) string {
+	fileName := sanitizeFileName(name)
+	if fileName == "" {
+		fileName = "collection"
+	}
+	return fileName + ".json"
+}
+
+// CollectionExists checks if a collection file exists in the storage directory
+func (s *CollectionService) CollectionExists(fileName string) bool {
+	if !strings.HasSuffix(fileName, ".json") {
+		fileName += ".json"
+	}
+
+	storageDir, err := storage.GetStorageDir()
+	if err != nil {
+		return false
+	}
+
+	_, err = os.Stat(filepath.Join(storageDir, fileName))
+	return err == nil
+}
+
+// CreateCollection creates and saves a new empty collection, returning it with its file name
+func (s *CollectionService) CreateCollection(name string) (*models.Collection, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, "", fmt.Errorf("collection name cannot be empty")
+	}
+
+	fileName := s.CollectionFileName(name)
+	if s.CollectionExists(fileName) {
+		return nil, "", fmt.Errorf("collection file '%s' already exists", fileName)
+	}
+
+	collection := s.CreateEmptyCollection()
+	collection.Name = name
+
+	if _, err := s.SaveCollection(collection, fileName); err != nil {
+		return nil, "", err
+	}
+
+	return collection, fileName, nil
+}
+
+// RenameCollection renames a collection and moves it to a matching file name
+func (s *CollectionService) RenameCollection(fileName, newName string) (string, error) {
+	if strings.TrimSpace(newName) == "" {
+		return "", fmt.Errorf("collection name cannot be empty")
+	}
+
+	collection, err := s.LoadCollection(fileName)
+	if err != nil {
+		return "", err
+	}
+
+	newFileName := s.CollectionFileName(newName)
+	if newFileName != fileName && s.CollectionExists(newFileName) {
+		return "", fmt.Errorf("collection file '%s' already exists", newFileName)
+	}
+
+	collection.Name = newName
+	if _, err := s.SaveCollection(collection, newFileName); err != nil {
+		return "", err
+	}
+
+	// Secrets and cookie jars are keyed by the collection file
+	if newFileName != fileName {
+		if err := secrets.Rename(secrets.CollectionScope(fileName), secrets.CollectionScope(newFileName)); err != nil {
+			// Keep the collection under its old file only
+			s.removeCollectionFile(newFileName)
+			return "", fmt.Errorf("failed to move collection secrets: %w", err)
+		}
+		if err := cookies.Rename(fileName, newFileName); err != nil {
+			secrets.Rename(secrets.CollectionScope(newFileName), secrets.CollectionScope(fileName))
+			s.removeCollectionFile(newFileName)
+			return "", err
+		}
+		if err := s.removeCollectionFile(fileName); err != nil {
+			return "", fmt.Errorf("failed to remove old collection file: %w", err)
+		}
+	}
+
+	return newFileName, nil
+}
+
+// DuplicateCollection saves a copy of a collection under a new name
+func (s *CollectionService) DuplicateCollection(fileName, newName string) (string, error) {
+	if strings.TrimSpace(newName) == "" {
+		return "", fmt.Errorf("collection name cannot be empty")
+	}
+
+	collection, err := s.LoadCollection(fileName)
+	if err != nil {
+		return "", err
+	}
+
+	newFileName := s.CollectionFileName(newName)
+	if s.CollectionExists(newFileName) {
+		return "", fmt.Errorf("collection file '%s' already exists", newFileName)
+	}
+
+	collection.Name = newName
+	if _, err := s.SaveCollection(collection, newFileName); err != nil {
+		return "", err
+	}
+
+	// The copy keeps the secret values of its variables
+	if err := secrets.Copy(secrets.CollectionScope(fileName), secrets.CollectionScope(newFileName)); err != nil {
+		s.removeCollectionFile(newFileName)
+		return "", fmt.Errorf("failed to copy collection secrets: %w", err)
+	}
+
+	return newFileName, nil
+}
+
+// DeleteCollection removes a collection file from the storage directory, along with its secrets and cookie jars
+func (s *CollectionService) DeleteCollection(fileName string) error {
+	if err := s.removeCollectionFile(fileName); err != nil {
+		return err
+	}
+	if err := secrets.Clear(secrets.CollectionScope(fileName)); err != nil {
+		return fmt.Errorf("failed to delete collection secrets: %w", err)
+	}
+	return cookies.Delete(fileName)
+}
+
+// removeCollectionFile removes a collection file only
+func (s *CollectionService) removeCollectionFile(fileName string) error {
+	if fileName == "" {
+		return fmt.Errorf("file name cannot be empty")
+	}
+	if !strings.HasSuffix(fileName, ".json") {
+		fileName += ".json"
+	}
+
+	storageDir, err := storage.GetStorageDir()
+	if err != nil {
+		return fmt.Errorf("failed to get storage directory: %w", err)
+	}
+
+	if err := os.Remove(filepath.Join(storageDir, fileName)); err != nil {
+		return fmt.Errorf("failed to delete collection file: %w", err)
+	}
+
+	return nil
+}
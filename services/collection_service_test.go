@@ -0,0 +1,40 @@
+package services
+
+import (
+	"github.com/leobrines/curlman/secrets"
+	"reflect"
+	"testing"
+)
+
+func TestListCollectionsWithSecrets(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(secrets.PassphraseEnv, "")
+
+	s := NewCollectionService()
+	collection := s.CreateEmptyCollection()
+	if _, err := s.SaveCollection(collection, "shop.json"); err != nil {
+		t.Fatalf("SaveCollection: %v", err)
+	}
+	if err := secrets.Set(secrets.CollectionScope("shop.json"), "token", "t0ken"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := secrets.Set(secrets.GlobalScope, "key", "k3y!"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	got, err := s.ListCollections()
+	if err != nil {
+		t.Fatalf("ListCollections: %v", err)
+	}
+	if want := []string{"shop.json"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ListCollections = %q, want %q", got, want)
+	}
+
+	// Deleting a collection only clears its own secrets
+	if err := s.DeleteCollection("shop.json"); err != nil {
+		t.Fatalf("DeleteCollection: %v", err)
+	}
+	if values, err := secrets.Values(secrets.GlobalScope); err != nil || values["key"] != "k3y!" {
+		t.Errorf("global secrets after DeleteCollection = %v, %v", values, err)
+	}
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"github.com/leobrines/curlman/cookies"
+	"github.com/leobrines/curlman/models"
+	"fmt"
+)
+
+// CookieService handles the cookie jars of collections
+type CookieService struct{}
+
+// NewCookieService creates a new cookie service
+func NewCookieService() *CookieService {
+	return &CookieService{}
+}
+
+// Jar opens the cookie jar of a collection and its active environments
+func (s *CookieService) Jar(collection *models.Collection) (*cookies.Jar, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("collection cannot be nil")
+	}
+	return cookies.Load(collection)
+}
+
+// SetCookie adds a cookie to a jar, replacing old (which may be nil) when editing one
+func (s *CookieService) SetCookie(jar *cookies.Jar, old *cookies.Cookie, text string) (*cookies.Cookie, error) {
+	cookie, err := cookies.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	if old != nil {
+		jar.Delete(old)
+	}
+	jar.Set(cookie)
+	return cookie, jar.Save()
+}
+
+// DeleteCookie removes a cookie from a jar
+func (s *CookieService) DeleteCookie(jar *cookies.Jar, cookie *cookies.Cookie) error {
+	jar.Delete(cookie)
+	return jar.Save()
+}
+
+// ClearCookies removes every cookie of a jar
+func (s *CookieService) ClearCookies(jar *cookies.Jar) error {
+	jar.Clear()
+	return jar.Save()
+}
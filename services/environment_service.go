@@ -3,6 +3,8 @@ package services
 import (
 	"github.com/leobrines/curlman/environment"
 	"github.com/leobrines/curlman/models"
+	"github.com/leobrines/curlman/postman"
+	"github.com/leobrines/curlman/secrets"
 	"fmt"
 )
 
@@ -104,6 +106,10 @@ func (s *EnvironmentService) RenameGlobalEnvironment(oldName, newName string) er
 		return fmt.Errorf("failed to delete old environment: %w", err)
 	}
 
+	if err := secrets.Rename(secrets.EnvironmentScope(oldName), secrets.EnvironmentScope(newName)); err != nil {
+		return fmt.Errorf("failed to move environment secrets: %w", err)
+	}
+
 	return nil
 }
 
@@ -124,7 +130,9 @@ func (s *EnvironmentService) SetGlobalEnvironmentVariable(envName, key, value st
 	if env.Variables == nil {
 		env.Variables = make(map[string]string)
 	}
-	env.Variables[key] = value
+	if err := setVariable(env.Variables, secrets.EnvironmentScope(envName), key, value); err != nil {
+		return err
+	}
 
 	if err := env.Save(); err != nil {
 		return fmt.Errorf("failed to save environment: %w", err)
@@ -133,6 +141,34 @@ func (s *EnvironmentService) SetGlobalEnvironmentVariable(envName, key, value st
 	return nil
 }
 
+// SetActiveEnvironmentVariable sets a variable in the active collection environment,
+// or in the active global environment when no collection environment is active
+func (s *EnvironmentService) SetActiveEnvironmentVariable(collection *models.Collection, key, value string) error {
+	if collection == nil {
+		return fmt.Errorf("collection cannot be nil")
+	}
+
+	if collection.ActiveCollectionEnv != "" {
+		return s.SetCollectionEnvironmentVariable(collection, collection.ActiveCollectionEnv, key, value)
+	}
+
+	if collection.ActiveEnvironment != "" {
+		if err := s.SetGlobalEnvironmentVariable(collection.ActiveEnvironment, key, value); err != nil {
+			return err
+		}
+		// Keep the runtime copy of the global environment in sync; secrets keep their marker there
+		if collection.EnvironmentVars == nil {
+			collection.EnvironmentVars = make(map[string]string)
+		}
+		if collection.EnvironmentVars[key] != secrets.Marker {
+			collection.EnvironmentVars[key] = value
+		}
+		return nil
+	}
+
+	return fmt.Errorf("no active environment to store '%s' in", key)
+}
+
 // DeleteGlobalEnvironmentVariable deletes a variable from a global environment
 func (s *EnvironmentService) DeleteGlobalEnvironmentVariable(envName, key string) error {
 	if envName == "" {
@@ -147,7 +183,27 @@ func (s *EnvironmentService) DeleteGlobalEnvironmentVariable(envName, key string
 		return fmt.Errorf("failed to get environment: %w", err)
 	}
 
-	delete(env.Variables, key)
+	if err := deleteVariable(env.Variables, secrets.EnvironmentScope(envName), key); err != nil {
+		return err
+	}
+
+	if err := env.Save(); err != nil {
+		return fmt.Errorf("failed to save environment: %w", err)
+	}
+
+	return nil
+}
+
+// SetGlobalEnvironmentVariableSecret moves the value of a global environment variable to the secret store, or back
+func (s *EnvironmentService) SetGlobalEnvironmentVariableSecret(envName, key string, secret bool) error {
+	env, err := environment.Load(envName)
+	if err != nil {
+		return fmt.Errorf("failed to get environment: %w", err)
+	}
+
+	if err := setSecret(env.Variables, secrets.EnvironmentScope(envName), key, secret); err != nil {
+		return err
+	}
 
 	if err := env.Save(); err != nil {
 		return fmt.Errorf("failed to save environment: %w", err)
@@ -273,6 +329,10 @@ func (s *EnvironmentService) RenameCollectionEnvironment(collection *models.Coll
 		return fmt.Errorf("collection environment '%s' not found", oldName)
 	}
 
+	if err := secrets.Rename(secrets.CollectionEnvironmentScope(collection.File, oldName), secrets.CollectionEnvironmentScope(collection.File, newName)); err != nil {
+		return fmt.Errorf("failed to move environment secrets: %w", err)
+	}
+
 	return nil
 }
 
@@ -296,9 +356,7 @@ func (s *EnvironmentService) SetCollectionEnvironmentVariable(collection *models
 	if env.Variables == nil {
 		env.Variables = make(map[string]string)
 	}
-	env.Variables[key] = value
-
-	return nil
+	return setVariable(env.Variables, secrets.CollectionEnvironmentScope(collection.File, envName), key, value)
 }
 
 // DeleteCollectionEnvironmentVariable deletes a variable from a collection environment
@@ -318,8 +376,24 @@ func (s *EnvironmentService) DeleteCollectionEnvironmentVariable(collection *mod
 		return fmt.Errorf("collection environment '%s' not found", envName)
 	}
 
-	delete(env.Variables, key)
-	return nil
+	return deleteVariable(env.Variables, secrets.CollectionEnvironmentScope(collection.File, envName), key)
+}
+
+// SetCollectionEnvironmentVariableSecret moves the value of a collection environment variable to the secret store, or back
+func (s *EnvironmentService) SetCollectionEnvironmentVariableSecret(collection *models.Collection, envName, key string, secret bool) error {
+	if collection == nil {
+		return fmt.Errorf("collection cannot be nil")
+	}
+
+	if err := checkSecretScope(collection); err != nil {
+		return err
+	}
+
+	env := collection.GetCollectionEnvironment(envName)
+	if env == nil {
+		return fmt.Errorf("collection environment '%s' not found", envName)
+	}
+	return setSecret(env.Variables, secrets.CollectionEnvironmentScope(collection.File, envName), key, secret)
 }
 
 // ActivateCollectionEnvironment activates a collection environment
@@ -347,3 +421,75 @@ func (s *EnvironmentService) DeactivateCollectionEnvironment(collection *models.
 	collection.ClearCollectionEnvironmentVariables()
 	return nil
 }
+
+// ImportPostmanEnvironment adds a Postman environment file to a collection,
+// replacing the variables of an existing environment with the same name
+func (s *EnvironmentService) ImportPostmanEnvironment(collection *models.Collection, filePath string) (*models.CollectionEnvironment, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("collection cannot be nil")
+	}
+	if filePath == "" {
+		return nil, fmt.Errorf("file path cannot be empty")
+	}
+
+	imported, err := postman.ImportEnvironmentFromFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	env := collection.GetCollectionEnvironment(imported.Name)
+	if env == nil {
+		env = collection.AddCollectionEnvironment(imported.Name)
+	}
+	env.Variables = imported.Variables
+
+	// Refresh runtime variables when the replaced environment is active
+	if collection.ActiveCollectionEnv == env.Name {
+		collection.ActivateCollectionEnvironment(env.Name)
+	}
+
+	return env, nil
+}
+
+// ExportPostmanEnvironment converts a collection environment into Postman environment JSON
+func (s *EnvironmentService) ExportPostmanEnvironment(collection *models.Collection, name string) (string, error) {
+	env, err := s.GetCollectionEnvironment(collection, name)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := postman.ExportEnvironment(env)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// RestoreActiveEnvironments reloads the variables of the environments a collection was saved with
+func (s *EnvironmentService) RestoreActiveEnvironments(collection *models.Collection) error {
+	if collection == nil {
+		return fmt.Errorf("collection cannot be nil")
+	}
+
+	// Initialize runtime maps if needed
+	if collection.EnvironmentVars == nil {
+		collection.EnvironmentVars = make(map[string]string)
+	}
+	if collection.CollectionEnvVars == nil {
+		collection.CollectionEnvVars = make(map[string]string)
+	}
+
+	if collection.ActiveEnvironment != "" {
+		if err := s.ActivateGlobalEnvironment(collection, collection.ActiveEnvironment); err != nil {
+			return fmt.Errorf("failed to restore global environment '%s': %w", collection.ActiveEnvironment, err)
+		}
+	}
+
+	if collection.ActiveCollectionEnv != "" {
+		if err := s.ActivateCollectionEnvironment(collection, collection.ActiveCollectionEnv); err != nil {
+			return fmt.Errorf("failed to restore collection environment '%s': %w", collection.ActiveCollectionEnv, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"github.com/leobrines/curlman/executor"
+	"github.com/leobrines/curlman/extract"
+	"github.com/leobrines/curlman/models"
+	"fmt"
+)
+
+// ExtractionService copies values from responses into variables (request chaining)
+type ExtractionService struct {
+	variableService    *VariableService
+	environmentService *EnvironmentService
+}
+
+// NewExtractionService creates a new extraction service
+func NewExtractionService(variableService *VariableService, environmentService *EnvironmentService) *ExtractionService {
+	return &ExtractionService{
+		variableService:    variableService,
+		environmentService: environmentService,
+	}
+}
+
+// AddExtraction parses and appends an extraction rule to a request
+func (s *ExtractionService) AddExtraction(request *models.Request, text string) (*models.Extraction, error) {
+	if request == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	e, err := extract.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid extraction: %w", err)
+	}
+	if err := s.variableService.ValidateVariableName(e.Variable); err != nil {
+		return nil, err
+	}
+
+	request.Extractions = append(request.Extractions, e)
+	return &request.Extractions[len(request.Extractions)-1], nil
+}
+
+// DeleteExtraction removes an extraction rule from a request by index
+func (s *ExtractionService) DeleteExtraction(request *models.Request, index int) error {
+	if request == nil {
+		return fmt.Errorf("request cannot be nil")
+	}
+	if index < 0 || index >= len(request.Extractions) {
+		return fmt.Errorf("invalid extraction index: %d", index)
+	}
+
+	request.Extractions = append(request.Extractions[:index], request.Extractions[index+1:]...)
+	return nil
+}
+
+// ApplyExtractions runs the extraction rules of a request and stores the extracted values
+// Environment-scoped values go to the active collection environment, or the active global
+// environment when no collection environment is active
+func (s *ExtractionService) ApplyExtractions(collection *models.Collection, request *models.Request, response *executor.Response) []extract.Result {
+	if collection == nil || request == nil || len(request.Extractions) == 0 {
+		return []extract.Result{}
+	}
+
+	results := extract.Apply(request.Extractions, response)
+	for i, r := range results {
+		if r.Error != nil {
+			continue
+		}
+		if err := s.store(collection, r.Extraction, r.Value); err != nil {
+			results[i].Error = err
+		}
+	}
+
+	return results
+}
+
+// store writes an extracted value to the scope selected by the extraction
+func (s *ExtractionService) store(collection *models.Collection, e models.Extraction, value string) error {
+	if e.TargetScope() == models.ScopeCollection {
+		return s.variableService.SetCollectionVariable(collection, e.Variable, value)
+	}
+
+	return s.environmentService.SetActiveEnvironmentVariable(collection, e.Variable, value)
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"github.com/leobrines/curlman/models"
+	"fmt"
+)
+
+// FolderService handles folder-related business logic
+type FolderService struct{}
+
+// NewFolderService creates a new folder service
+func NewFolderService() *FolderService {
+	return &FolderService{}
+}
+
+// folderExists reports whether a folder is stored or referenced by a request
+func (s *FolderService) folderExists(collection *models.Collection, path string) bool {
+	for _, existing := range collection.FolderPaths() {
+		if existing == path {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateFolder adds a folder, given as a path like "Users/Admin"
+func (s *FolderService) CreateFolder(collection *models.Collection, path string) (string, error) {
+	if collection == nil {
+		return "", fmt.Errorf("collection cannot be nil")
+	}
+
+	path = models.JoinFolderPath(path)
+	if path == "" {
+		return "", fmt.Errorf("folder name cannot be empty")
+	}
+
+	if s.folderExists(collection, path) {
+		return "", fmt.Errorf("folder '%s' already exists", path)
+	}
+
+	collection.AddFolder(path)
+	return path, nil
+}
+
+// RenameFolder moves a folder with everything in it to a new path
+func (s *FolderService) RenameFolder(collection *models.Collection, oldPath, newPath string) (string, error) {
+	if collection == nil {
+		return "", fmt.Errorf("collection cannot be nil")
+	}
+
+	newPath = models.JoinFolderPath(newPath)
+	if newPath == "" {
+		return "", fmt.Errorf("folder name cannot be empty")
+	}
+
+	if !s.folderExists(collection, oldPath) {
+		return "", fmt.Errorf("folder '%s' not found", oldPath)
+	}
+
+	if newPath == oldPath {
+		return newPath, nil
+	}
+
+	if s.folderExists(collection, newPath) {
+		return "", fmt.Errorf("folder '%s' already exists", newPath)
+	}
+
+	if models.InFolder(newPath, oldPath) {
+		return "", fmt.Errorf("cannot move folder '%s' into itself", oldPath)
+	}
+
+	collection.RenameFolder(oldPath, newPath)
+	return newPath, nil
+}
+
+// DeleteFolder removes a folder, moving its requests and subfolders to the parent folder
+func (s *FolderService) DeleteFolder(collection *models.Collection, path string) error {
+	if collection == nil {
+		return fmt.Errorf("collection cannot be nil")
+	}
+
+	if !s.folderExists(collection, path) {
+		return fmt.Errorf("folder '%s' not found", path)
+	}
+
+	collection.DeleteFolder(path)
+	return nil
+}
+
+// MoveRequest puts a request into a folder, creating the folder if needed ("" for the top level)
+func (s *FolderService) MoveRequest(collection *models.Collection, request *models.Request, path string) (string, error) {
+	if collection == nil {
+		return "", fmt.Errorf("collection cannot be nil")
+	}
+	if request == nil {
+		return "", fmt.Errorf("request cannot be nil")
+	}
+
+	path = models.JoinFolderPath(path)
+	if path != "" {
+		collection.AddFolder(path)
+	}
+	request.Folder = path
+
+	return path, nil
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"github.com/leobrines/curlman/executor"
+	"github.com/leobrines/curlman/history"
+	"github.com/leobrines/curlman/models"
+	"github.com/leobrines/curlman/secrets"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// HistoryService records executed requests and replays them
+type HistoryService struct {
+	requestService     *RequestService
+	variableService    *VariableService
+	collectionService  *CollectionService
+	environmentService *EnvironmentService
+}
+
+// NewHistoryService creates a new history service
+func NewHistoryService(requestService *RequestService, variableService *VariableService, collectionService *CollectionService, environmentService *EnvironmentService) *HistoryService {
+	return &HistoryService{
+		requestService:     requestService,
+		variableService:    variableService,
+		collectionService:  collectionService,
+		environmentService: environmentService,
+	}
+}
+
+// Record stores an execution in the history with the request as it was sent, so dynamic values like
+// {{$uuid}} keep the value the server got; a request that could not be rendered is recorded as written
+// Secret values are put back as {{placeholders}} in the request and masked in the response, so the history holds none
+func (s *HistoryService) Record(collection *models.Collection, request *models.Request, response *executor.Response) error {
+	if request == nil || response == nil {
+		return fmt.Errorf("request and response cannot be nil")
+	}
+
+	collectionName, collectionFile := "", ""
+	if collection != nil {
+		collectionName, collectionFile = collection.Name, collection.File
+	}
+
+	// Secrets that cannot be read were not sent, so they have nothing to hide
+	secretVars, _ := s.variableService.SecretVariables(collection)
+	entry := newEntry(collectionName, collectionFile, sentRequest(request, response), response, secretVars)
+	if err := history.Append(entry); err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+
+	return nil
+}
+
+// sentRequest returns the request as the executor sent it, or as written when its variables could not be injected
+func sentRequest(request *models.Request, response *executor.Response) *models.Request {
+	if response.Request != nil {
+		return response.Request
+	}
+	return request
+}
+
+// List returns history entries, newest first, optionally filtered
+// The filter matches request names (case-insensitive), exact status codes ("404"),
+// status classes ("5xx") or "error" for failed executions
+func (s *HistoryService) List(filter string) ([]*history.Entry, error) {
+	entries, err := history.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load history: %w", err)
+	}
+
+	filter = strings.ToLower(strings.TrimSpace(filter))
+	if filter == "" {
+		return entries, nil
+	}
+
+	filtered := []*history.Entry{}
+	for _, entry := range entries {
+		if matchesHistoryFilter(entry, filter) {
+			filtered = append(filtered, entry)
+		}
+	}
+
+	return filtered, nil
+}
+
+// Replay executes the resolved request of a history entry again and records the result
+// The recorded request is sent as is, without the cookie jar, with the secret variables of the collection
+// it was sent from filled in, as saved with its active environments
+func (s *HistoryService) Replay(ctx context.Context, entry *history.Entry) (*executor.Response, error) {
+	if entry == nil || entry.Request == nil {
+		return nil, fmt.Errorf("history entry has no request to replay")
+	}
+	if entry.RequestBodyTruncated {
+		return nil, fmt.Errorf("the request body was too large to keep in history, send the request from its collection instead")
+	}
+	for _, text := range entry.Request.Texts() {
+		if strings.Contains(text, secrets.Marker) {
+			return nil, fmt.Errorf("the request holds masked secret values, send the request from its collection instead")
+		}
+	}
+
+	collection, err := s.entryCollection(entry)
+	if err != nil {
+		return nil, fmt.Errorf("cannot replay request: %w", err)
+	}
+
+	// Only secret variables are left as placeholders; one that cannot be filled in would go out as written
+	secretVars, secretErr := s.variableService.SecretVariables(collection)
+	if _, err := s.variableService.FindUnresolvedVariables(entry.Request, secretVars, secretErr); err != nil {
+		return nil, fmt.Errorf("cannot replay request: %w", err)
+	}
+	response, err := s.requestService.ExecuteRequest(ctx, entry.Request, secretVars, nil, false)
+	if err != nil {
+		return nil, fmt.Errorf("cannot replay request: %w", err)
+	}
+
+	replayed := newEntry(entry.Collection, entry.CollectionFile, sentRequest(entry.Request, response), response, secretVars)
+	if err := history.Append(replayed); err != nil {
+		return response, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	return response, nil
+}
+
+// entryCollection loads the collection a history entry was sent from, with its active environments
+// Entries of unsaved collections only have global secrets to fill in
+func (s *HistoryService) entryCollection(entry *history.Entry) (*models.Collection, error) {
+	if entry.CollectionFile == "" {
+		return &models.Collection{}, nil
+	}
+
+	collection, err := s.collectionService.LoadCollection(entry.CollectionFile)
+	if err != nil {
+		return nil, fmt.Errorf("collection '%s' of the entry: %w", entry.Collection, err)
+	}
+	if err := s.environmentService.RestoreActiveEnvironments(collection); err != nil {
+		return nil, err
+	}
+	return collection, nil
+}
+
+// newEntry builds a history entry with the values of secret variables masked in the request and the response
+func newEntry(collectionName, collectionFile string, request *models.Request, response *executor.Response, secretVars map[string]string) *history.Entry {
+	entry := history.NewEntry(collectionName, maskRequest(request, secretVars), response)
+	entry.CollectionFile = collectionFile
+	if len(secretVars) == 0 {
+		return entry
+	}
+
+	values := secretValues(secretVars)
+	entry.Body = secrets.Mask(entry.Body, values)
+	entry.Error = secrets.Mask(entry.Error, values)
+	headers := make(http.Header, len(entry.Headers))
+	for k, vs := range entry.Headers {
+		for _, v := range vs {
+			headers[k] = append(headers[k], secrets.Mask(v, values))
+		}
+	}
+	entry.Headers = headers
+	return entry
+}
+
+// maskRequest puts the {{placeholders}} of secret variables back in place of their values in a sent request,
+// so a replay fills them in again, and masks the values left in other forms, like URL-encoded
+func maskRequest(request *models.Request, secretVars map[string]string) *models.Request {
+	if len(secretVars) == 0 {
+		return request
+	}
+
+	// Longer values first, so a secret containing another one is replaced whole
+	names := make([]string, 0, len(secretVars))
+	for name, value := range secretVars {
+		if len(value) >= secrets.MinMaskLength {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(a, b int) bool {
+		if len(secretVars[names[a]]) != len(secretVars[names[b]]) {
+			return len(secretVars[names[a]]) > len(secretVars[names[b]])
+		}
+		return names[a] < names[b]
+	})
+
+	values := secretValues(secretVars)
+	masked, _ := request.Transform(func(text string) (string, error) {
+		for _, name := range names {
+			text = strings.ReplaceAll(text, secretVars[name], "{{"+name+"}}")
+		}
+		return secrets.Mask(text, values), nil
+	})
+	return masked
+}
+
+// Clear removes all history entries
+func (s *HistoryService) Clear() error {
+	return history.Clear()
+}
+
+func matchesHistoryFilter(entry *history.Entry, filter string) bool {
+	if filter == "error" {
+		return entry.Error != ""
+	}
+
+	if code, err := strconv.Atoi(filter); err == nil {
+		return entry.StatusCode == code
+	}
+
+	if len(filter) == 3 && strings.HasSuffix(filter, "xx") && entry.StatusCode > 0 {
+		return strconv.Itoa(entry.StatusCode)[:1] == filter[:1]
+	}
+
+	return strings.Contains(strings.ToLower(entry.RequestName), filter) ||
+		strings.Contains(strings.ToLower(entry.RequestID), filter)
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"github.com/leobrines/curlman/config"
+	"github.com/leobrines/curlman/history"
+	"github.com/leobrines/curlman/models"
+	"github.com/leobrines/curlman/secrets"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(secrets.PassphraseEnv, "")
+
+	var sent http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent = r.Header.Clone()
+	}))
+	defer server.Close()
+
+	collection := &models.Collection{
+		Name:      "Shop",
+		File:      "shop.json",
+		Variables: map[string]string{"token": secrets.Marker},
+	}
+	if err := secrets.Set(secrets.CollectionScope(collection.File), "token", "t0ken-value"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	request := &models.Request{
+		Name:   "Order",
+		Method: "GET",
+		URL:    server.URL,
+		Headers: map[string]string{
+			"X-Request-Id":  "{{$uuid}}",
+			"Authorization": "Bearer {{token}}",
+		},
+	}
+
+	collectionService := NewCollectionService()
+	variableService := NewVariableService(config.NewGlobalConfig())
+	requestService := NewRequestService()
+	historyService := NewHistoryService(requestService, variableService, collectionService, NewEnvironmentService())
+
+	if _, err := collectionService.SaveCollection(collection, collection.File); err != nil {
+		t.Fatalf("SaveCollection: %v", err)
+	}
+	// Another collection with a secret of the same name, which a replay must not send
+	if err := secrets.Set(secrets.CollectionScope("other.json"), "token", "other-value"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+
+	variables, err := variableService.GetAllVariables(collection)
+	if err != nil {
+		t.Fatalf("GetAllVariables: %v", err)
+	}
+	response, err := requestService.ExecuteRequest(context.Background(), request, variables, nil, false)
+	if err != nil {
+		t.Fatalf("ExecuteRequest: %v", err)
+	}
+	if sent.Get("Authorization") != "Bearer t0ken-value" {
+		t.Fatalf("sent Authorization = %q, want the secret filled in", sent.Get("Authorization"))
+	}
+	if err := historyService.Record(collection, request, response); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	entries, err := history.Load()
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("history.Load = %d entries, %v", len(entries), err)
+	}
+	recorded := entries[0].Request
+	if got, want := recorded.Headers["X-Request-Id"], sent.Get("X-Request-Id"); got != want {
+		t.Errorf("recorded X-Request-Id = %q, want the sent %q", got, want)
+	}
+	if got := recorded.Headers["Authorization"]; got != "Bearer {{token}}" {
+		t.Errorf("recorded Authorization = %q, want the secret as a placeholder", got)
+	}
+
+	// A replay fills in the secrets of the collection the entry was sent from
+	sent = nil
+	if _, err := historyService.Replay(context.Background(), entries[0]); err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if sent.Get("Authorization") != "Bearer t0ken-value" {
+		t.Errorf("replayed Authorization = %q, want the secret of the entry collection", sent.Get("Authorization"))
+	}
+
+	if err := collectionService.DeleteCollection(collection.File); err != nil {
+		t.Fatalf("DeleteCollection: %v", err)
+	}
+	sent = nil
+	if _, err := historyService.Replay(context.Background(), entries[0]); err == nil {
+		t.Errorf("Replay of a deleted collection succeeded, want an error")
+	}
+	if sent != nil {
+		t.Errorf("Replay of a deleted collection sent the request")
+	}
+}
@@ -1,10 +1,15 @@
 package services
 
 import (
+	"github.com/leobrines/curlman/assertion"
+	"github.com/leobrines/curlman/cookies"
+	"github.com/leobrines/curlman/curl"
 	"github.com/leobrines/curlman/executor"
 	"github.com/leobrines/curlman/exporter"
 	"github.com/leobrines/curlman/models"
+	"context"
 	"fmt"
+	"net/http"
 	"strings"
 )
 
@@ -110,38 +115,109 @@ func (s *RequestService) GetRequest(collection *models.Collection, index int) (*
 }
 
 // ExecuteRequest executes a request with the given variables
-func (s *RequestService) ExecuteRequest(request *models.Request, variables map[string]string) (*executor.Response, error) {
+// Cookies are sent from and saved to jar unless it is nil; cancelling ctx aborts the request
+// A request using undefined variables is not sent and fails with an UnresolvedVariablesError naming them,
+// unless anyway is set to send their {{placeholders}} as written
+// A jar that cannot be saved is reported with the response, which is returned too as the request was sent
+func (s *RequestService) ExecuteRequest(ctx context.Context, request *models.Request, variables map[string]string, jar *cookies.Jar, anyway bool) (*executor.Response, error) {
+	return s.execute(ctx, request, variables, jar, "", nil, anyway)
+}
+
+// DownloadRequest executes a request like ExecuteRequest, streaming the whole response body to path
+// Progress, when given, tracks the bytes received
+func (s *RequestService) DownloadRequest(ctx context.Context, request *models.Request, variables map[string]string, jar *cookies.Jar, path string, progress *executor.Progress, anyway bool) (*executor.Response, error) {
+	if path == "" {
+		return nil, fmt.Errorf("download path cannot be empty")
+	}
+	return s.execute(ctx, request, variables, jar, path, progress, anyway)
+}
+
+func (s *RequestService) execute(ctx context.Context, request *models.Request, variables map[string]string, jar *cookies.Jar, path string, progress *executor.Progress, anyway bool) (*executor.Response, error) {
 	if request == nil {
 		return nil, fmt.Errorf("request cannot be nil")
 	}
 
-	// Validate request before execution
-	if err := s.ValidateRequest(request); err != nil {
+	// Validate request before execution, once variables like {{baseUrl}} are resolved
+	injected, err := request.InjectVariables(variables)
+	if err != nil {
+		return nil, fmt.Errorf("cannot execute request: %w", err)
+	}
+	if err := s.ValidateRequest(injected); err != nil {
 		return nil, fmt.Errorf("cannot execute invalid request: %w", err)
 	}
+	if err := checkUnresolved(request, variables, anyway); err != nil {
+		return nil, err
+	}
+
+	// OAuth2 tokens are fetched or refreshed before the request goes out
+	withToken, err := withOAuth2Token(ctx, request, variables)
+	if err != nil {
+		return nil, err
+	}
 
 	// Execute the request
-	response := executor.Execute(request, variables)
+	var cookieJar http.CookieJar
+	if jar != nil {
+		cookieJar = jar
+	}
+	var response *executor.Response
+	if path != "" {
+		response = executor.Download(ctx, withToken, variables, cookieJar, path, progress)
+	} else {
+		response = executor.Execute(ctx, withToken, variables, cookieJar)
+	}
+	// The sent request keeps its OAuth2 settings rather than the access token, so a replay gets a fresh one
+	if withToken != request && response.Request != nil {
+		response.Request.Auth = injected.Auth
+	}
+
+	if jar != nil {
+		if err := jar.Save(); err != nil {
+			return response, err
+		}
+	}
 	return response, nil
 }
 
 // ExportToCurl generates a curl command for the request
-func (s *RequestService) ExportToCurl(request *models.Request, variables map[string]string) (string, error) {
+// A request using undefined variables fails with an UnresolvedVariablesError naming them,
+// unless anyway is set to export their {{placeholders}} as written
+func (s *RequestService) ExportToCurl(request *models.Request, variables map[string]string, anyway bool) (string, error) {
 	if request == nil {
 		return "", fmt.Errorf("request cannot be nil")
 	}
 
-	// Validate request before export
-	if err := s.ValidateRequest(request); err != nil {
+	// Inject variables and validate the resolved request before export
+	injected, err := request.InjectVariables(variables)
+	if err != nil {
+		return "", fmt.Errorf("cannot export request: %w", err)
+	}
+	if err := s.ValidateRequest(injected); err != nil {
 		return "", fmt.Errorf("cannot export invalid request: %w", err)
 	}
+	if err := checkUnresolved(request, variables, anyway); err != nil {
+		return "", err
+	}
 
-	// Inject variables and export
-	injected := request.InjectVariables(variables)
-	curlCmd := exporter.ToCurl(injected)
+	curlCmd := exporter.ToCurl(withCachedOAuth2Token(injected))
 	return curlCmd, nil
 }
 
+// checkUnresolved fails with an UnresolvedVariablesError when a request uses undefined variables, unless anyway is set
+func checkUnresolved(request *models.Request, variables map[string]string, anyway bool) error {
+	if anyway {
+		return nil
+	}
+	unresolved, err := unresolvedVariables(request, variables)
+	if err != nil {
+		return err
+	}
+	if len(unresolved) > 0 {
+		return &UnresolvedVariablesError{Names: unresolved}
+	}
+	return nil
+}
+
 // ValidateRequest validates a request's data
 func (s *RequestService) ValidateRequest(request *models.Request) error {
 	if request == nil {
@@ -214,6 +290,24 @@ func (s *RequestService) UpdateRequestField(request *models.Request, field strin
 		request.Path = value
 	case "body":
 		request.Body = value
+	case "body_mode":
+		mode := strings.ToLower(strings.TrimSpace(value))
+		switch mode {
+		case "", models.BodyRaw:
+			request.BodyMode = ""
+		case models.BodyURLEncoded, models.BodyFormData, models.BodyBinary:
+			request.BodyMode = mode
+		default:
+			return fmt.Errorf("invalid body mode: %s (use raw, urlencoded, formdata or binary)", value)
+		}
+	case "body_language":
+		language := strings.ToLower(strings.TrimSpace(value))
+		if language != "" && models.LanguageContentType(language) == "" {
+			return fmt.Errorf("invalid body language: %s (use json, xml, html, text or javascript)", value)
+		}
+		request.BodyLanguage = language
+	case "body_file":
+		request.BodyFile = strings.TrimSpace(value)
 	default:
 		return fmt.Errorf("unknown field: %s", field)
 	}
@@ -278,3 +372,125 @@ func (s *RequestService) DeleteQueryParam(request *models.Request, key string) e
 	delete(request.QueryParams, key)
 	return nil
 }
+
+// FindRequest looks up a request in a collection by ID or by name (case-insensitive)
+func (s *RequestService) FindRequest(collection *models.Collection, nameOrID string) (int, *models.Request, error) {
+	if collection == nil {
+		return -1, nil, fmt.Errorf("collection cannot be nil")
+	}
+	if nameOrID == "" {
+		return -1, nil, fmt.Errorf("request name cannot be empty")
+	}
+
+	for i, req := range collection.Requests {
+		if req.ID != "" && req.ID == nameOrID {
+			return i, req, nil
+		}
+	}
+	for i, req := range collection.Requests {
+		if strings.EqualFold(req.Name, nameOrID) {
+			return i, req, nil
+		}
+	}
+
+	return -1, nil, fmt.Errorf("request '%s' not found in collection '%s'", nameOrID, collection.Name)
+}
+
+// AddAssertion parses and appends an assertion to a request
+func (s *RequestService) AddAssertion(request *models.Request, text string) (*models.Assertion, error) {
+	if request == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	a, err := assertion.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid assertion: %w", err)
+	}
+
+	request.Assertions = append(request.Assertions, a)
+	return &request.Assertions[len(request.Assertions)-1], nil
+}
+
+// AddFormField parses "key=value" (or "key=@path" for a file) and appends it to the body fields
+func (s *RequestService) AddFormField(request *models.Request, text string) (*models.FormField, error) {
+	if request == nil {
+		return nil, fmt.Errorf("request cannot be nil")
+	}
+
+	field, ok := models.ParseFormField(text)
+	if !ok {
+		return nil, fmt.Errorf("invalid form field: use key=value, or key=@path for a file")
+	}
+	if field.IsFile() && request.GetBodyMode() == models.BodyURLEncoded {
+		return nil, fmt.Errorf("file fields need the formdata body mode")
+	}
+
+	request.FormFields = append(request.FormFields, field)
+	return &request.FormFields[len(request.FormFields)-1], nil
+}
+
+// DeleteFormField removes a body field by index
+func (s *RequestService) DeleteFormField(request *models.Request, index int) error {
+	if request == nil {
+		return fmt.Errorf("request cannot be nil")
+	}
+	if index < 0 || index >= len(request.FormFields) {
+		return fmt.Errorf("invalid form field index: %d", index)
+	}
+
+	request.FormFields = append(request.FormFields[:index], request.FormFields[index+1:]...)
+	return nil
+}
+
+// DeleteAssertion removes an assertion from a request by index
+func (s *RequestService) DeleteAssertion(request *models.Request, index int) error {
+	if request == nil {
+		return fmt.Errorf("request cannot be nil")
+	}
+	if index < 0 || index >= len(request.Assertions) {
+		return fmt.Errorf("invalid assertion index: %d", index)
+	}
+
+	request.Assertions = append(request.Assertions[:index], request.Assertions[index+1:]...)
+	return nil
+}
+
+// CheckAssertions evaluates the assertions of a request against its response
+func (s *RequestService) CheckAssertions(request *models.Request, response *executor.Response, variables map[string]string) []assertion.Result {
+	if request == nil || len(request.Assertions) == 0 {
+		return []assertion.Result{}
+	}
+
+	// An assertion whose placeholders cannot be rendered fails on its own
+	results := make([]assertion.Result, 0, len(request.Assertions))
+	for _, a := range request.Assertions {
+		injected, err := (&models.Request{Assertions: []models.Assertion{a}}).InjectVariables(variables)
+		if err != nil {
+			results = append(results, assertion.Result{Assertion: a, Message: err.Error()})
+			continue
+		}
+		results = append(results, assertion.Evaluate(injected.Assertions, response)...)
+	}
+	return results
+}
+
+// ImportFromCurl parses a curl command and adds the resulting request to a collection
+func (s *RequestService) ImportFromCurl(collection *models.Collection, command string) (*models.Request, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("collection cannot be nil")
+	}
+	if strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("curl command cannot be empty")
+	}
+
+	request, err := curl.Parse(command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse curl command: %w", err)
+	}
+
+	if err := s.AddRequest(collection, request); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
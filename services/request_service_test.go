@@ -0,0 +1,39 @@
+package services
+
+import (
+	"github.com/leobrines/curlman/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExportOAuth2WithoutFetching(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	fetched := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched = true
+	}))
+	defer server.Close()
+
+	request := &models.Request{
+		Name:   "Orders",
+		Method: "GET",
+		URL:    "https://api.example.com/orders",
+		Auth: &models.Auth{Type: models.AuthOAuth2, OAuth2: &models.OAuth2{
+			Grant: models.GrantClientCredentials, TokenURL: server.URL, ClientID: "app", ClientSecret: "s3cret",
+		}},
+	}
+
+	curlCmd, err := NewRequestService().ExportToCurl(request, map[string]string{}, false)
+	if err != nil {
+		t.Fatalf("ExportToCurl: %v", err)
+	}
+	if fetched {
+		t.Errorf("ExportToCurl requested a token")
+	}
+	if !strings.Contains(curlCmd, "Bearer "+OAuth2TokenPlaceholder) {
+		t.Errorf("ExportToCurl = %s, want the token placeholder", curlCmd)
+	}
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"github.com/leobrines/curlman/assertion"
+	"github.com/leobrines/curlman/executor"
+	"github.com/leobrines/curlman/extract"
+	"github.com/leobrines/curlman/models"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RunResult holds the outcome of a single request within a collection run
+type RunResult struct {
+	Request     *models.Request
+	Response    *executor.Response
+	Assertions  []assertion.Result
+	Extractions []extract.Result
+	Unresolved  []string // Undefined variables the request was sent with, as written
+	Warnings    []string // Problems that did not fail the request, like failing to record it in history
+	Error       error    // Set when the request could not be executed at all
+	Passed      bool     // See PassRule for requests without assertions
+}
+
+// PassRule is how a request without assertions passes, noted in run reports
+const PassRule = "requests without assertions pass with a status below 400"
+
+// RunReport summarizes a collection run
+type RunReport struct {
+	CollectionName string
+	Results        []*RunResult
+	StartedAt      time.Time
+	Duration       time.Duration
+	Cancelled      bool // The run stopped before its last request
+}
+
+// PassedCount returns the number of passed requests
+func (r *RunReport) PassedCount() int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Passed {
+			count++
+		}
+	}
+	return count
+}
+
+// WithoutAssertions reports whether a request of the run has no assertions, and so passes by PassRule
+func (r *RunReport) WithoutAssertions() bool {
+	for _, result := range r.Results {
+		if len(result.Request.Assertions) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// FailedCount returns the number of failed requests
+func (r *RunReport) FailedCount() int {
+	return len(r.Results) - r.PassedCount()
+}
+
+// RunnerService executes several requests of a collection in order
+type RunnerService struct {
+	requestService    *RequestService
+	variableService   *VariableService
+	extractionService *ExtractionService
+	historyService    *HistoryService
+	cookieService     *CookieService
+}
+
+// NewRunnerService creates a new runner service
+func NewRunnerService(requestService *RequestService, variableService *VariableService, extractionService *ExtractionService, historyService *HistoryService, cookieService *CookieService) *RunnerService {
+	return &RunnerService{
+		requestService:    requestService,
+		variableService:   variableService,
+		extractionService: extractionService,
+		historyService:    historyService,
+		cookieService:     cookieService,
+	}
+}
+
+// RunCollection executes the requests of a collection in order
+// When names is empty every request runs, otherwise only the named ones (by name or ID)
+// Requests using undefined variables are sent with a warning, or fail without being sent when strict
+func (s *RunnerService) RunCollection(ctx context.Context, collection *models.Collection, names []string, strict bool) (*RunReport, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("collection cannot be nil")
+	}
+
+	requests := collection.Requests
+	if len(names) > 0 {
+		selected := make(map[*models.Request]bool)
+		for _, name := range names {
+			_, request, err := s.requestService.FindRequest(collection, name)
+			if err != nil {
+				return nil, err
+			}
+			selected[request] = true
+		}
+
+		// Keep collection order regardless of the order names were given in
+		requests = []*models.Request{}
+		for _, request := range collection.Requests {
+			if selected[request] {
+				requests = append(requests, request)
+			}
+		}
+	}
+
+	return s.RunRequests(ctx, collection, requests, strict), nil
+}
+
+// RunFolder executes the requests of a folder and its subfolders in collection order
+func (s *RunnerService) RunFolder(ctx context.Context, collection *models.Collection, path string, strict bool) (*RunReport, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("collection cannot be nil")
+	}
+
+	requests := collection.RequestsInFolder(path)
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("folder '%s' has no requests", path)
+	}
+
+	report := s.RunRequests(ctx, collection, requests, strict)
+	report.CollectionName = fmt.Sprintf("%s / %s", collection.Name, path)
+	return report, nil
+}
+
+// RunRequests executes the given requests in order and collects their results
+// Cancelling ctx aborts the running request and skips the remaining ones
+func (s *RunnerService) RunRequests(ctx context.Context, collection *models.Collection, requests []*models.Request, strict bool) *RunReport {
+	report := &RunReport{
+		CollectionName: collection.Name,
+		Results:        []*RunResult{},
+		StartedAt:      time.Now(),
+	}
+
+	// Cookies set by a response are sent by the following requests
+	jar, jarErr := s.cookieService.Jar(collection)
+
+	for _, request := range requests {
+		if ctx.Err() != nil {
+			report.Cancelled = true
+			break
+		}
+
+		// Variables are fetched per request so earlier requests can affect later ones
+		allVars, secretErr := s.variableService.GetAllVariables(collection)
+
+		result := &RunResult{Request: request}
+		resolved := collection.Resolve(request)
+		var response *executor.Response
+		unresolved, err := s.variableService.FindUnresolvedVariables(resolved, allVars, secretErr)
+		if err == nil && len(unresolved) > 0 {
+			if strict {
+				err = &UnresolvedVariablesError{Names: unresolved}
+			} else {
+				result.Unresolved = unresolved
+			}
+		}
+		if err == nil {
+			err = jarErr
+		}
+		if err == nil {
+			response, err = s.requestService.ExecuteRequest(ctx, resolved, allVars, jar, !strict)
+			// The request went out even when its cookies could not be saved
+			if err != nil && response != nil {
+				result.Warnings = append(result.Warnings, err.Error())
+				err = nil
+			}
+		}
+		if err != nil {
+			result.Error = err
+		} else {
+			result.Response = response
+			if err := s.historyService.Record(collection, resolved, response); err != nil {
+				result.Warnings = append(result.Warnings, err.Error())
+			}
+			result.Assertions = s.requestService.CheckAssertions(request, response, allVars)
+
+			// Extracted values feed the variables of the following requests
+			result.Extractions = s.extractionService.ApplyExtractions(collection, request, response)
+
+			// Requests without assertions pass by PassRule
+			if len(request.Assertions) > 0 {
+				result.Passed = response.Error == nil && assertion.AllPassed(result.Assertions)
+			} else {
+				result.Passed = response.Error == nil && response.StatusCode < 400
+			}
+		}
+
+		report.Results = append(report.Results, result)
+	}
+
+	report.Duration = time.Since(report.StartedAt)
+	return report
+}
+
+// FormatRunReport formats a run report as a summary table
+func FormatRunReport(report *RunReport) string {
+	var result strings.Builder
+
+	result.WriteString(fmt.Sprintf("Run: %s\n\n", report.CollectionName))
+
+	for _, r := range report.Results {
+		mark := "PASS"
+		if !r.Passed {
+			mark = "FAIL"
+		}
+
+		detail := ""
+		duration := time.Duration(0)
+		switch {
+		case r.Error != nil:
+			detail = r.Error.Error()
+		case r.Response.Error != nil:
+			detail = r.Response.Error.Error()
+			duration = r.Response.Duration
+		default:
+			detail = r.Response.Status
+			duration = r.Response.Duration
+		}
+
+		result.WriteString(fmt.Sprintf("%s  %-7s %-40s %-10s %s\n",
+			mark, r.Request.Method, r.Request.Name, duration.Round(time.Millisecond), detail))
+
+		for _, a := range r.Assertions {
+			if !a.Passed {
+				result.WriteString(fmt.Sprintf("        x %s (%s)\n", a.Assertion, a.Message))
+			}
+		}
+		if len(r.Unresolved) > 0 {
+			result.WriteString(fmt.Sprintf("        ! undefined variables kept as written: %s\n", strings.Join(r.Unresolved, ", ")))
+		}
+		for _, e := range r.Extractions {
+			if e.Error != nil {
+				result.WriteString(fmt.Sprintf("        ! could not extract %s (%s)\n", e.Extraction.Variable, e.Error))
+			}
+		}
+		for _, warning := range r.Warnings {
+			result.WriteString(fmt.Sprintf("        ! %s\n", warning))
+		}
+	}
+
+	result.WriteString(fmt.Sprintf("\n%d requests, %d passed, %d failed in %s\n",
+		len(report.Results), report.PassedCount(), report.FailedCount(), report.Duration.Round(time.Millisecond)))
+	if report.WithoutAssertions() {
+		result.WriteString("Note: " + PassRule + "\n")
+	}
+	if report.Cancelled {
+		result.WriteString("Run cancelled, the remaining requests were skipped\n")
+	}
+
+	return result.String()
+}
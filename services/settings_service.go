@@ -0,0 +1,134 @@
+package services
+
+import (
+	"github.com/leobrines/curlman/models"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SettingsService handles HTTP client settings of requests and collections
+type SettingsService struct{}
+
+// NewSettingsService creates a new settings service
+func NewSettingsService() *SettingsService {
+	return &SettingsService{}
+}
+
+// SetRequestSetting sets a client setting of a request; an empty value inherits the collection setting
+func (s *SettingsService) SetRequestSetting(request *models.Request, field, value string) error {
+	if request == nil {
+		return fmt.Errorf("request cannot be nil")
+	}
+	settings, err := s.set(request.Settings, field, value)
+	if err != nil {
+		return err
+	}
+	request.Settings = settings
+	return nil
+}
+
+// SetCollectionSetting sets a client setting shared by every request of a collection
+func (s *SettingsService) SetCollectionSetting(collection *models.Collection, field, value string) error {
+	if collection == nil {
+		return fmt.Errorf("collection cannot be nil")
+	}
+	settings, err := s.set(collection.Settings, field, value)
+	if err != nil {
+		return err
+	}
+	collection.Settings = settings
+	return nil
+}
+
+// set validates and applies a setting, returning nil once no setting is left
+func (s *SettingsService) set(settings *models.ClientSettings, field, value string) (*models.ClientSettings, error) {
+	updated := settings.Clone()
+	if updated == nil {
+		updated = &models.ClientSettings{}
+	}
+	value = strings.TrimSpace(value)
+	hasVariables := strings.Contains(value, "{{")
+
+	switch field {
+	case "timeout":
+		if value != "" && !hasVariables {
+			if _, err := strconv.ParseFloat(value, 64); err == nil {
+				// Bare numbers are seconds, like curl's --max-time
+				value += "s"
+			}
+			if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid timeout: %s (use a duration like 10s or 500ms)", value)
+			}
+		}
+		updated.Timeout = value
+	case "follow_redirects":
+		b, ok := models.ParseBool(value)
+		if !ok {
+			return nil, fmt.Errorf("invalid value: %s (use true or false)", value)
+		}
+		updated.FollowRedirects = b
+	case "max_redirects":
+		n := 0
+		if value != "" {
+			var err error
+			n, err = strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid max redirects: %s", value)
+			}
+		}
+		updated.MaxRedirects = n
+	case "proxy":
+		if value != "" && !hasVariables {
+			raw := value
+			if !strings.Contains(raw, "://") {
+				raw = "http://" + raw
+			}
+			if u, err := url.Parse(raw); err != nil || u.Host == "" {
+				return nil, fmt.Errorf("invalid proxy URL: %s", value)
+			}
+		}
+		updated.Proxy = value
+	case "no_proxy":
+		updated.NoProxy = value
+	case "insecure":
+		b, ok := models.ParseBool(value)
+		if !ok {
+			return nil, fmt.Errorf("invalid value: %s (use true or false)", value)
+		}
+		updated.Insecure = b
+	case "ca_cert":
+		updated.CACert = value
+	case "client_cert":
+		updated.ClientCert = value
+	case "client_key":
+		updated.ClientKey = value
+	case "http_version":
+		value = strings.TrimPrefix(strings.ToLower(value), "http/")
+		switch value {
+		case "", models.HTTPVersion11, models.HTTPVersion2:
+		case "1":
+			value = models.HTTPVersion11
+		case "2.0":
+			value = models.HTTPVersion2
+		default:
+			return nil, fmt.Errorf("invalid HTTP version: %s (use 1.1 or 2)", value)
+		}
+		updated.HTTPVersion = value
+	case "cookies":
+		b, ok := models.ParseBool(value)
+		if !ok {
+			return nil, fmt.Errorf("invalid value: %s (use true or false)", value)
+		}
+		updated.Cookies = b
+	default:
+		return nil, fmt.Errorf("unknown setting: %s (use %s)", field, strings.Join(models.ClientSettingFields, ", "))
+	}
+
+	if updated.IsEmpty() {
+		return nil, nil
+	}
+	return updated, nil
+}
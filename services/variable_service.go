@@ -3,7 +3,10 @@ package services
 import (
 	"github.com/leobrines/curlman/config"
 	"github.com/leobrines/curlman/models"
+	"github.com/leobrines/curlman/secrets"
+	"github.com/leobrines/curlman/template"
 	"fmt"
+	"sort"
 	"strings"
 )
 
@@ -19,24 +22,233 @@ func NewVariableService(globalConfig *config.GlobalConfig) *VariableService {
 	}
 }
 
-// GetAllVariables returns all variables merged with proper precedence
+// GetAllVariables returns all variables merged with proper precedence, with the values of secret variables
 // Precedence: Global < Collection < Global Environment < Collection Environment
-func (s *VariableService) GetAllVariables(collection *models.Collection) map[string]string {
+// When the secret store cannot be read, the variables are returned with the secret ones left undefined,
+// along with the reason
+func (s *VariableService) GetAllVariables(collection *models.Collection) (map[string]string, error) {
+	globalVars, revealed, err := s.reveal(collection)
 	if collection == nil {
 		// If no collection, return only global variables
-		if s.globalConfig != nil {
-			return s.copyMap(s.globalConfig.Variables)
+		return s.copyMap(globalVars), err
+	}
+
+	// Use the collection's method which already implements precedence
+	return revealed.GetAllVariables(globalVars), err
+}
+
+// SecretVariables returns the effective variables whose values come from the secret store
+// Secrets that cannot be read are left out, along with the reason
+func (s *VariableService) SecretVariables(collection *models.Collection) (map[string]string, error) {
+	if collection == nil {
+		collection = &models.Collection{}
+	}
+	allVars, err := s.GetAllVariables(collection)
+
+	secretVars := make(map[string]string)
+	for name, value := range collection.GetAllVariables(s.globalVariables()) {
+		if revealed, ok := allVars[name]; ok && value == secrets.Marker {
+			secretVars[name] = revealed
 		}
+	}
+	return secretVars, err
+}
+
+// Mask replaces the values of the secret variables of a collection found in a text
+// Secrets that cannot be read cannot be sent either, so they have nothing to mask
+func (s *VariableService) Mask(collection *models.Collection, text string) string {
+	secretVars, _ := s.SecretVariables(collection)
+	if len(secretVars) == 0 {
+		return text
+	}
+	return secrets.Mask(text, secretValues(secretVars))
+}
+
+func secretValues(secretVars map[string]string) []string {
+	values := make([]string, 0, len(secretVars))
+	for _, value := range secretVars {
+		values = append(values, value)
+	}
+	return values
+}
+
+func (s *VariableService) globalVariables() map[string]string {
+	if s.globalConfig == nil || s.globalConfig.Variables == nil {
 		return make(map[string]string)
 	}
+	return s.globalConfig.Variables
+}
 
-	// Use the collection's method which already implements precedence
-	globalVars := make(map[string]string)
-	if s.globalConfig != nil {
-		globalVars = s.globalConfig.Variables
+// reveal returns the global variables and a shallow copy of the collection whose variable layers
+// hold the values of secret variables instead of their marker
+// Secrets missing from the store are left undefined; the first read error is returned
+func (s *VariableService) reveal(collection *models.Collection) (map[string]string, *models.Collection, error) {
+	var firstErr error
+	layer := func(variables map[string]string, scope string) map[string]string {
+		revealed, err := revealLayer(variables, scope)
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("cannot read secret variables: %w", err)
+		}
+		return revealed
+	}
+
+	globalVars := layer(s.globalVariables(), secrets.GlobalScope)
+	if collection == nil {
+		return globalVars, nil, firstErr
 	}
 
-	return collection.GetAllVariables(globalVars)
+	revealed := *collection
+	revealed.Variables = layer(collection.Variables, secrets.CollectionScope(collection.File))
+	revealed.EnvironmentVars = layer(collection.EnvironmentVars, secrets.EnvironmentScope(collection.ActiveEnvironment))
+	revealed.CollectionEnvVars = layer(collection.CollectionEnvVars, secrets.CollectionEnvironmentScope(collection.File, collection.ActiveCollectionEnv))
+	return globalVars, &revealed, firstErr
+}
+
+// revealLayer returns a copy of the variables of a layer with secret values, or the variables themselves without secrets
+func revealLayer(variables map[string]string, scope string) (map[string]string, error) {
+	hasSecrets := false
+	for _, value := range variables {
+		hasSecrets = hasSecrets || value == secrets.Marker
+	}
+	if !hasSecrets {
+		return variables, nil
+	}
+
+	values, err := secrets.Values(scope)
+	revealed := make(map[string]string, len(variables))
+	for key, value := range variables {
+		if value != secrets.Marker {
+			revealed[key] = value
+		} else if secret, ok := values[key]; ok {
+			revealed[key] = secret
+		}
+	}
+	return revealed, err
+}
+
+// ResolvedVariable is an effective variable, with where its value comes from
+type ResolvedVariable struct {
+	Name       string
+	Value      string // Value as defined, which may reference other variables
+	Resolved   string // Value with the variables it references replaced
+	Error      string // Why the value cannot be resolved, "" when it can
+	Source     models.VariableValue
+	Overridden []models.VariableValue // Values of lower precedence layers, highest first
+	UsedBy     []string               // Requests using the variable, directly or through other variables
+}
+
+// ResolveVariables lists every effective variable of a collection, sorted by name
+// Secret values are masked unless revealed; secrets that cannot be read are listed unresolved, and the reason returned
+func (s *VariableService) ResolveVariables(collection *models.Collection, reveal bool) ([]ResolvedVariable, error) {
+	if collection == nil {
+		collection = &models.Collection{}
+	}
+	globalVars, revealed, secretErr := s.reveal(collection)
+	allVars := revealed.GetAllVariables(globalVars)
+
+	usedBy := make(map[string][]string)
+	for _, request := range collection.Requests {
+		used := make(map[string]bool)
+		for _, text := range collection.Resolve(request).Texts() {
+			for _, name := range template.Uses(text, allVars) {
+				used[name] = true
+			}
+		}
+		for name := range used {
+			usedBy[name] = append(usedBy[name], request.Name)
+		}
+	}
+
+	var secretValues []string
+	layers := collection.VariableLayers(s.globalVariables())
+	revealedLayers := revealed.VariableLayers(globalVars)
+	for name, values := range layers {
+		for i, v := range values {
+			if v.Value != secrets.Marker {
+				continue
+			}
+			for _, r := range revealedLayers[name] {
+				if r.Source == v.Source {
+					secretValues = append(secretValues, r.Value)
+					if reveal {
+						values[i].Value = r.Value
+					}
+				}
+			}
+		}
+	}
+
+	resolved := make([]ResolvedVariable, 0, len(layers))
+	for _, name := range sortedLayerNames(layers) {
+		values := layers[name]
+		v := ResolvedVariable{
+			Name:       name,
+			Value:      values[0].Value,
+			Resolved:   values[0].Value,
+			Source:     values[0],
+			Overridden: values[1:],
+			UsedBy:     usedBy[name],
+		}
+		// A masked secret resolves to its marker
+		if value, ok := allVars[name]; ok && (reveal || v.Value != secrets.Marker) {
+			rendered, err := template.Render(value, allVars)
+			if err != nil {
+				v.Error = err.Error()
+			} else {
+				v.Resolved = rendered
+			}
+			if !reveal {
+				v.Resolved = secrets.Mask(v.Resolved, secretValues)
+				v.Error = secrets.Mask(v.Error, secretValues)
+			}
+		}
+		resolved = append(resolved, v)
+	}
+	return resolved, secretErr
+}
+
+func sortedLayerNames(layers map[string][]models.VariableValue) []string {
+	names := make([]string, 0, len(layers))
+	for name := range layers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// FormatResolvedVariables describes each variable with its source, the values it overrides and the requests using it
+func FormatResolvedVariables(variables []ResolvedVariable) string {
+	if len(variables) == 0 {
+		return "No variables defined.\n"
+	}
+
+	var s strings.Builder
+	for _, v := range variables {
+		s.WriteString(fmt.Sprintf("%s = %s  (%s)\n", v.Name, v.Value, v.Source.Origin()))
+		for _, line := range v.Details() {
+			s.WriteString("    " + line + "\n")
+		}
+	}
+	return s.String()
+}
+
+// Details describes the resolved value, the overridden values and the requests using the variable
+func (v ResolvedVariable) Details() []string {
+	var lines []string
+	if v.Error != "" {
+		lines = append(lines, "cannot resolve: "+v.Error)
+	} else if v.Resolved != v.Value {
+		lines = append(lines, "resolves to: "+v.Resolved)
+	}
+	for _, o := range v.Overridden {
+		lines = append(lines, fmt.Sprintf("overrides %s: %s", o.Origin(), o.Value))
+	}
+	if len(v.UsedBy) > 0 {
+		lines = append(lines, "used by: "+strings.Join(v.UsedBy, ", "))
+	} else {
+		lines = append(lines, "not used by any request")
+	}
+	return lines
 }
 
 // SetCollectionVariable sets a variable in the collection
@@ -51,8 +263,7 @@ func (s *VariableService) SetCollectionVariable(collection *models.Collection, k
 	if collection.Variables == nil {
 		collection.Variables = make(map[string]string)
 	}
-	collection.Variables[key] = value
-	return nil
+	return setVariable(collection.Variables, secrets.CollectionScope(collection.File), key, value)
 }
 
 // DeleteCollectionVariable deletes a variable from the collection
@@ -64,8 +275,7 @@ func (s *VariableService) DeleteCollectionVariable(collection *models.Collection
 		return fmt.Errorf("variable key cannot be empty")
 	}
 
-	delete(collection.Variables, key)
-	return nil
+	return deleteVariable(collection.Variables, secrets.CollectionScope(collection.File), key)
 }
 
 // GetCollectionVariable gets a variable value from the collection
@@ -95,6 +305,9 @@ func (s *VariableService) SetGlobalVariable(key, value string) error {
 		return fmt.Errorf("variable key cannot be empty")
 	}
 
+	if s.globalConfig.Variables[key] == secrets.Marker {
+		return secrets.Set(secrets.GlobalScope, key, value)
+	}
 	s.globalConfig.SetVariable(key, value)
 	if err := s.globalConfig.Save(); err != nil {
 		return fmt.Errorf("failed to save global config: %w", err)
@@ -112,7 +325,45 @@ func (s *VariableService) DeleteGlobalVariable(key string) error {
 		return fmt.Errorf("variable key cannot be empty")
 	}
 
-	s.globalConfig.DeleteVariable(key)
+	if err := deleteVariable(s.globalConfig.Variables, secrets.GlobalScope, key); err != nil {
+		return err
+	}
+	if err := s.globalConfig.Save(); err != nil {
+		return fmt.Errorf("failed to save global config: %w", err)
+	}
+
+	return nil
+}
+
+// SetCollectionVariableSecret moves the value of a collection variable to the secret store, or back
+// The collection keeps a marker in place of the value of a secret variable
+func (s *VariableService) SetCollectionVariableSecret(collection *models.Collection, key string, secret bool) error {
+	if collection == nil {
+		return fmt.Errorf("collection cannot be nil")
+	}
+	if err := checkSecretScope(collection); err != nil {
+		return err
+	}
+	return setSecret(collection.Variables, secrets.CollectionScope(collection.File), key, secret)
+}
+
+// checkSecretScope makes sure a collection has a file to key its secrets by
+func checkSecretScope(collection *models.Collection) error {
+	if collection.File == "" {
+		return fmt.Errorf("save the collection before marking its variables secret")
+	}
+	return nil
+}
+
+// SetGlobalVariableSecret moves the value of a global variable to the secret store, or back
+func (s *VariableService) SetGlobalVariableSecret(key string, secret bool) error {
+	if s.globalConfig == nil {
+		return fmt.Errorf("global config not initialized")
+	}
+
+	if err := setSecret(s.globalConfig.Variables, secrets.GlobalScope, key, secret); err != nil {
+		return err
+	}
 	if err := s.globalConfig.Save(); err != nil {
 		return fmt.Errorf("failed to save global config: %w", err)
 	}
@@ -157,66 +408,109 @@ func (s *VariableService) ValidateVariableName(name string) error {
 }
 
 // InjectVariables injects variables into a request (returns new request)
-func (s *VariableService) InjectVariables(request *models.Request, variables map[string]string) *models.Request {
+func (s *VariableService) InjectVariables(request *models.Request, variables map[string]string) (*models.Request, error) {
 	if request == nil {
-		return nil
+		return nil, nil
 	}
 	return request.InjectVariables(variables)
 }
 
-// FindUnresolvedVariables finds all variable references in a request that are not in the provided variables
-func (s *VariableService) FindUnresolvedVariables(request *models.Request, variables map[string]string) []string {
-	if request == nil {
-		return []string{}
-	}
-
-	unresolved := make(map[string]bool)
+// UnresolvedVariablesError reports the variables a request uses that are not defined
+type UnresolvedVariablesError struct {
+	Names []string
+}
 
-	// Helper to find variables in a string
-	findVars := func(text string) {
-		start := 0
-		for {
-			startIdx := strings.Index(text[start:], "{{")
-			if startIdx == -1 {
-				break
-			}
-			startIdx += start
+func (e *UnresolvedVariablesError) Error() string {
+	return fmt.Sprintf("undefined variables: %s", strings.Join(e.Names, ", "))
+}
 
-			endIdx := strings.Index(text[startIdx:], "}}")
-			if endIdx == -1 {
-				break
-			}
-			endIdx += startIdx
+// FindUnresolvedVariables finds all variable references in a request that are not in the provided variables,
+// including those reached through variables whose values reference other variables
+// It checks the fields InjectVariables renders, and fails like it when a placeholder cannot be evaluated,
+// naming the variables involved when some reference each other in a cycle
+// secretErr is the error GetAllVariables returned with the variables: secret variables are undefined when the
+// secret store cannot be read, so it is returned as the cause when the request uses undefined variables
+func (s *VariableService) FindUnresolvedVariables(request *models.Request, variables map[string]string, secretErr error) ([]string, error) {
+	if request == nil {
+		return []string{}, nil
+	}
 
-			varName := strings.TrimSpace(text[startIdx+2 : endIdx])
-			if _, exists := variables[varName]; !exists {
-				unresolved[varName] = true
-			}
+	result, err := unresolvedVariables(request, variables)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) > 0 && secretErr != nil {
+		return nil, secretErr
+	}
+	return result, nil
+}
 
-			start = endIdx + 2
+// unresolvedVariables lists the undefined variables of the fields of a request InjectVariables renders, sorted
+func unresolvedVariables(request *models.Request, variables map[string]string) ([]string, error) {
+	unresolved := make(map[string]bool)
+	_, err := request.Transform(func(text string) (string, error) {
+		names, err := template.Check(text, variables)
+		for _, name := range names {
+			unresolved[name] = true
 		}
+		return text, err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Check all fields that support variable injection
-	findVars(request.URL)
-	findVars(request.Path)
-	findVars(request.Body)
+	result := make([]string, 0, len(unresolved))
+	for name := range unresolved {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
 
-	for _, v := range request.Headers {
-		findVars(v)
+// setVariable sets a variable of a layer, in the secret store when the variable is secret
+func setVariable(variables map[string]string, scope, key, value string) error {
+	if variables[key] == secrets.Marker {
+		return secrets.Set(scope, key, value)
 	}
+	variables[key] = value
+	return nil
+}
 
-	for _, v := range request.QueryParams {
-		findVars(v)
+// deleteVariable deletes a variable of a layer, with its secret value
+func deleteVariable(variables map[string]string, scope, key string) error {
+	if variables[key] == secrets.Marker {
+		if err := secrets.Delete(scope, key); err != nil {
+			return err
+		}
 	}
+	delete(variables, key)
+	return nil
+}
 
-	// Convert map to slice
-	result := make([]string, 0, len(unresolved))
-	for varName := range unresolved {
-		result = append(result, varName)
+// setSecret moves the value of a variable of a layer to the secret store, leaving a marker, or back
+func setSecret(variables map[string]string, scope, key string, secret bool) error {
+	value, exists := variables[key]
+	if !exists {
+		return fmt.Errorf("variable '%s' not found", key)
 	}
 
-	return result
+	switch {
+	case secret && value != secrets.Marker:
+		if err := secrets.Set(scope, key, value); err != nil {
+			return err
+		}
+		variables[key] = secrets.Marker
+	case !secret && value == secrets.Marker:
+		values, err := secrets.Values(scope)
+		if err != nil {
+			return err
+		}
+		variables[key] = values[key]
+		if err := secrets.Delete(scope, key); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Helper function to copy a map
@@ -0,0 +1,546 @@
+package template
+
+import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Render replaces the {{ }} placeholders of a text
+// A placeholder holds a variable name, a dynamic value like $uuid or a "quoted string",
+// optionally piped through functions: {{ payload | hmac secret "sha512" | base64 }}
+// Bare words name variables and literal text is quoted, except for the layout names of date and
+// the hash names of hmac, like {{ $timestamp | date iso }}, when no variable has that name
+// Variable values holding placeholders of their own are rendered in turn, so
+// base_url = {{scheme}}://{{host}} resolves whatever the order variables are defined in
+// Placeholders using undefined variables are left as written, for Check to report; any other
+// failure, like an unknown function or variables referencing each other, is returned
+// Placeholders of other template languages, like {{#each items}} in a handlebars body, are left as written
+func Render(text string, variables map[string]string) (string, error) {
+	return (&renderer{variables: variables}).render(text)
+}
+
+// renderer evaluates placeholders, tracking the variables being expanded to stop at cycles
+type renderer struct {
+	variables map[string]string
+	expanding []string
+	undefined []string // Undefined variables met, in order
+}
+
+func (r *renderer) render(text string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+
+	var s strings.Builder
+	for {
+		start := strings.Index(text, "{{")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(text[start+2:], "}}")
+		if end == -1 {
+			break
+		}
+		end += start + 2
+
+		s.WriteString(text[:start])
+		if !isExpression(text[start+2 : end]) {
+			s.WriteString(text[start : end+2])
+			text = text[end+2:]
+			continue
+		}
+		value, err := r.evaluate(text[start+2 : end])
+		var cycle *CycleError
+		var failed *placeholderError
+		switch {
+		case err == nil:
+			s.WriteString(value)
+		case errors.Is(err, errUndefined):
+			s.WriteString(text[start : end+2])
+		case errors.As(err, &cycle) || errors.As(err, &failed):
+			return "", err
+		default:
+			return "", &placeholderError{expr: strings.TrimSpace(text[start+2 : end]), err: err}
+		}
+		text = text[end+2:]
+	}
+	s.WriteString(text)
+	return s.String(), nil
+}
+
+// placeholderError reports a placeholder that could not be evaluated
+type placeholderError struct {
+	expr string
+	err  error
+}
+
+func (e *placeholderError) Error() string {
+	return fmt.Sprintf("{{%s}}: %s", e.expr, e.err)
+}
+
+func (e *placeholderError) Unwrap() error {
+	return e.err
+}
+
+// variable returns the value of a variable with its own placeholders rendered
+func (r *renderer) variable(name string) (string, bool, error) {
+	value, ok := r.variables[name]
+	if !ok || !strings.Contains(value, "{{") {
+		return value, ok, nil
+	}
+	if i := slices.Index(r.expanding, name); i != -1 {
+		return "", true, &CycleError{Cycle: append(slices.Clone(r.expanding[i:]), name)}
+	}
+
+	r.expanding = append(r.expanding, name)
+	defer func() { r.expanding = r.expanding[:len(r.expanding)-1] }()
+	value, err := r.render(value)
+	return value, true, err
+}
+
+// CycleError reports variables whose values reference each other
+type CycleError struct {
+	Cycle []string // Starts and ends with the same variable
+}
+
+func (e *CycleError) Error() string {
+	return "variables reference each other: " + strings.Join(e.Cycle, " -> ")
+}
+
+// isExpression reports whether a placeholder holds an expression of this syntax: a single variable name,
+// $dynamic value or quoted string, optionally piped through functions
+// Blocks, partials and comments of mustache or handlebars templates start with a sigil, like {{#each items}},
+// and {{else}} splits their blocks
+func isExpression(expr string) bool {
+	head := strings.TrimSpace(splitPipeline(expr)[0])
+	if head == "" || head == "else" || strings.ContainsAny(head[:1], "#/^>!{&=@.") {
+		return false
+	}
+	return strings.HasPrefix(head, `"`) || !strings.ContainsAny(head, " \t")
+}
+
+// Placeholders returns the expressions of the {{ }} placeholders of a text, trimmed,
+// leaving out placeholders of other template languages
+func Placeholders(text string) []string {
+	var expressions []string
+	for {
+		start := strings.Index(text, "{{")
+		if start == -1 {
+			return expressions
+		}
+		end := strings.Index(text[start+2:], "}}")
+		if end == -1 {
+			return expressions
+		}
+		if expr := text[start+2 : start+2+end]; isExpression(expr) {
+			expressions = append(expressions, strings.TrimSpace(expr))
+		}
+		text = text[start+2+end+2:]
+	}
+}
+
+// Check lists the undefined variables a text references, directly or through the values of other variables
+// It fails like Render when a placeholder cannot be evaluated, with a CycleError when variables reference each other
+func Check(text string, variables map[string]string) ([]string, error) {
+	r := &renderer{variables: variables}
+	if _, err := r.render(text); err != nil {
+		return nil, err
+	}
+	return r.undefined, nil
+}
+
+// Uses lists the variables a text references, directly or through the values of defined variables
+func Uses(text string, variables map[string]string) []string {
+	var used []string
+	var walk func(text string)
+	walk = func(text string) {
+		for _, expr := range Placeholders(text) {
+			for _, name := range operands(expr, variables) {
+				if !slices.Contains(used, name) {
+					used = append(used, name)
+					walk(variables[name])
+				}
+			}
+		}
+	}
+	walk(text)
+	return used
+}
+
+// operands returns the bare words of an expression that name variables: its head and function arguments
+func operands(expr string, variables map[string]string) []string {
+	var words []string
+	for i, stage := range splitPipeline(expr) {
+		fields, err := splitFields(stage)
+		if err != nil || len(fields) == 0 {
+			continue
+		}
+		fn := ""
+		if i > 0 {
+			fn, fields = fields[0], fields[1:]
+		}
+		for _, field := range fields {
+			if strings.HasPrefix(field, "$") || strings.HasPrefix(field, `"`) || isNumber(field) {
+				continue
+			}
+			if _, defined := variables[field]; !defined && isKeyword(fn, field) {
+				continue
+			}
+			words = append(words, field)
+		}
+	}
+	return words
+}
+
+// keywords are the bare words functions take literally when no variable has that name
+var keywords = map[string][]string{
+	"date": {"iso", "rfc1123", "unix", "unixms"},
+	"hmac": {"sha1", "sha256", "sha512"},
+}
+
+func isKeyword(fn, word string) bool {
+	return slices.Contains(keywords[fn], word)
+}
+
+// hasDefault reports whether a pipeline falls back to a default value right after its head
+func hasDefault(stages []string) bool {
+	if len(stages) < 2 {
+		return false
+	}
+	fields, err := splitFields(stages[1])
+	return err == nil && len(fields) > 0 && fields[0] == "default"
+}
+
+// number matches decimal literals only, so words like inf or nan name variables
+var number = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+func isNumber(token string) bool {
+	return number.MatchString(token)
+}
+
+// Evaluate computes the value of a placeholder expression
+func Evaluate(expr string, variables map[string]string) (string, error) {
+	return (&renderer{variables: variables}).evaluate(expr)
+}
+
+// The whole pipeline is checked before giving up on undefined variables, so each of them is reported
+func (r *renderer) evaluate(expr string) (string, error) {
+	stages := splitPipeline(expr)
+
+	head := strings.TrimSpace(stages[0])
+	value, err := r.operand(head)
+	undefined := errors.Is(err, errUndefined)
+	if undefined && hasDefault(stages) {
+		value, err, undefined = "", nil, false
+	}
+	if undefined {
+		r.markUndefined(head)
+	} else if err != nil {
+		return "", err
+	}
+
+	for _, stage := range stages[1:] {
+		fields, err := splitFields(stage)
+		if err != nil {
+			return "", err
+		}
+		if len(fields) == 0 {
+			return "", fmt.Errorf("empty function in %q", expr)
+		}
+
+		fn, ok := functions[fields[0]]
+		if !ok {
+			return "", fmt.Errorf("unknown function %q", fields[0])
+		}
+		args := make([]string, len(fields)-1)
+		for i, field := range fields[1:] {
+			args[i], err = r.operand(field)
+			if errors.Is(err, errUndefined) && isKeyword(fields[0], field) {
+				args[i], err = field, nil
+			}
+			if errors.Is(err, errUndefined) {
+				r.markUndefined(field)
+				undefined = true
+			} else if err != nil {
+				return "", err
+			}
+		}
+		if undefined {
+			continue
+		}
+		if value, err = fn(value, args); err != nil {
+			return "", fmt.Errorf("%s: %w", fields[0], err)
+		}
+	}
+
+	if undefined {
+		return "", errUndefined
+	}
+	return value, nil
+}
+
+func (r *renderer) markUndefined(name string) {
+	if !slices.Contains(r.undefined, name) {
+		r.undefined = append(r.undefined, name)
+	}
+}
+
+// operand evaluates a "quoted string", a number, a $dynamic value or a variable
+func (r *renderer) operand(token string) (string, error) {
+	switch {
+	case token == "":
+		return "", fmt.Errorf("empty expression")
+	case strings.HasPrefix(token, `"`):
+		value, err := strconv.Unquote(token)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", token)
+		}
+		return value, nil
+	case strings.HasPrefix(token, "$"):
+		return dynamic(token)
+	case isNumber(token):
+		return token, nil
+	}
+
+	if value, ok, err := r.variable(token); ok {
+		return value, err
+	}
+	return "", fmt.Errorf("%w: %s", errUndefined, token)
+}
+
+var errUndefined = errors.New("undefined variable")
+
+// dynamic evaluates a built-in value, generated anew on every use
+func dynamic(name string) (string, error) {
+	if env, ok := strings.CutPrefix(name, "$env."); ok {
+		value, found := os.LookupEnv(env)
+		if !found {
+			return "", fmt.Errorf("environment variable %s is not set", env)
+		}
+		return value, nil
+	}
+
+	switch name {
+	case "$uuid", "$guid", "$randomUUID":
+		return uuid.New().String(), nil
+	case "$timestamp":
+		return strconv.FormatInt(time.Now().Unix(), 10), nil
+	case "$timestampMs":
+		return strconv.FormatInt(time.Now().UnixMilli(), 10), nil
+	case "$isoTimestamp":
+		return time.Now().UTC().Format("2006-01-02T15:04:05.000Z"), nil
+	case "$randomInt":
+		return strconv.Itoa(rand.IntN(1001)), nil
+	case "$randomEmail":
+		return fmt.Sprintf("user%d.%s@example.com", rand.IntN(100000), randomLetters(6)), nil
+	case "$randomString":
+		return randomLetters(12), nil
+	}
+	return "", fmt.Errorf("unknown dynamic value %s", name)
+}
+
+func randomLetters(n int) string {
+	const letters = "abcdefghijklmnopqrstuvwxyz0123456789"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letters[rand.IntN(len(letters))]
+	}
+	return string(b)
+}
+
+// function transforms the piped value, with the arguments written after the function name
+type function func(value string, args []string) (string, error)
+
+// functions are the filters available in pipelines
+var functions = map[string]function{
+	"base64": noArgs(func(value string) (string, error) {
+		return base64.StdEncoding.EncodeToString([]byte(value)), nil
+	}),
+	"base64url": noArgs(func(value string) (string, error) {
+		return base64.RawURLEncoding.EncodeToString([]byte(value)), nil
+	}),
+	"base64decode": noArgs(func(value string) (string, error) {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			decoded, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+		}
+		return string(decoded), err
+	}),
+	"urlencode": noArgs(func(value string) (string, error) {
+		return url.QueryEscape(value), nil
+	}),
+	"urldecode": noArgs(func(value string) (string, error) {
+		return url.QueryUnescape(value)
+	}),
+	"md5":    digest(md5.New),
+	"sha1":   digest(sha1.New),
+	"sha256": digest(sha256.New),
+	"sha512": digest(sha512.New),
+	"hmac":   hmacDigest,
+	"upper": noArgs(func(value string) (string, error) {
+		return strings.ToUpper(value), nil
+	}),
+	"lower": noArgs(func(value string) (string, error) {
+		return strings.ToLower(value), nil
+	}),
+	"trim": noArgs(func(value string) (string, error) {
+		return strings.TrimSpace(value), nil
+	}),
+	"default": func(value string, args []string) (string, error) {
+		if len(args) != 1 {
+			return "", fmt.Errorf("expects a fallback value")
+		}
+		if value == "" {
+			return args[0], nil
+		}
+		return value, nil
+	},
+	"date": formatDate,
+}
+
+func noArgs(fn func(value string) (string, error)) function {
+	return func(value string, args []string) (string, error) {
+		if len(args) > 0 {
+			return "", fmt.Errorf("takes no arguments")
+		}
+		return fn(value)
+	}
+}
+
+// digest hashes the value, in hex
+func digest(newHash func() hash.Hash) function {
+	return noArgs(func(value string) (string, error) {
+		h := newHash()
+		h.Write([]byte(value))
+		return hex.EncodeToString(h.Sum(nil)), nil
+	})
+}
+
+// hmacDigest signs the value with a key, with SHA-256 unless another hash is named, in hex
+func hmacDigest(value string, args []string) (string, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return "", fmt.Errorf("expects a key and an optional hash (sha1, sha256 or sha512)")
+	}
+	newHash := sha256.New
+	if len(args) == 2 {
+		switch args[1] {
+		case "sha1":
+			newHash = sha1.New
+		case "sha256":
+		case "sha512":
+			newHash = sha512.New
+		default:
+			return "", fmt.Errorf("unsupported hash %q", args[1])
+		}
+	}
+	mac := hmac.New(newHash, []byte(args[0]))
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// formatDate formats a time given as "now", Unix seconds or milliseconds, or RFC 3339, in UTC
+// The layout is a Go layout like 2006-01-02, or iso, rfc1123, unix or unixms
+func formatDate(value string, args []string) (string, error) {
+	if len(args) != 1 {
+		return "", fmt.Errorf("expects a layout like 2006-01-02 or iso")
+	}
+
+	var t time.Time
+	switch value = strings.TrimSpace(value); {
+	case value == "" || value == "now":
+		t = time.Now()
+	default:
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			if n > 1e12 {
+				t = time.UnixMilli(n)
+			} else {
+				t = time.Unix(n, 0)
+			}
+		} else if t, err = time.Parse(time.RFC3339, value); err != nil {
+			return "", fmt.Errorf("cannot read %q as a time", value)
+		}
+	}
+	t = t.UTC()
+
+	switch args[0] {
+	case "iso":
+		return t.Format(time.RFC3339), nil
+	case "rfc1123":
+		return t.Format(http.TimeFormat), nil
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10), nil
+	case "unixms":
+		return strconv.FormatInt(t.UnixMilli(), 10), nil
+	}
+	return t.Format(args[0]), nil
+}
+
+// splitPipeline splits an expression on the pipes outside of quoted strings
+func splitPipeline(expr string) []string {
+	var stages []string
+	start := 0
+	quoted := false
+	for i := 0; i < len(expr); i++ {
+		switch {
+		case quoted && expr[i] == '\\':
+			i++
+		case expr[i] == '"':
+			quoted = !quoted
+		case expr[i] == '|' && !quoted:
+			stages = append(stages, expr[start:i])
+			start = i + 1
+		}
+	}
+	return append(stages, expr[start:])
+}
+
+// splitFields splits a function call into its name and arguments, keeping quoted strings whole
+func splitFields(stage string) ([]string, error) {
+	var fields []string
+	for i := 0; i < len(stage); {
+		switch {
+		case stage[i] == ' ' || stage[i] == '\t':
+			i++
+		case stage[i] == '"':
+			end := i + 1
+			for end < len(stage) && stage[end] != '"' {
+				if stage[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(stage) {
+				return nil, fmt.Errorf("unterminated string in %q", stage)
+			}
+			fields = append(fields, stage[i:end+1])
+			i = end + 1
+		default:
+			end := i
+			for end < len(stage) && stage[end] != ' ' && stage[end] != '\t' {
+				end++
+			}
+			fields = append(fields, stage[i:end])
+			i = end
+		}
+	}
+	return fields, nil
+}
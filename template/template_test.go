@@ -0,0 +1,143 @@
+package template
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var variables = map[string]string{
+	"host":     "example.com",
+	"base":     "https://{{host}}",
+	"user":     "ann",
+	"password": "s3cret",
+	"key":      "k",
+	"empty":    "",
+	"layout":   "2006",
+}
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"plain", "plain"},
+		{"{{base}}/users", "https://example.com/users"},
+		{"{{ user }}:{{password}}", "ann:s3cret"},
+		{`{{ "x y" | upper }}`, "X Y"},
+		{"{{ user | upper | base64 }}", "QU5O"},
+		{`{{ user | hmac key "sha1" }}`, "60d04d03c22d4f476670132d64f520559f6f0c9c"},
+		{`{{ empty | default "guest" }}`, "guest"},
+		{`{{ missing | default "guest" }}`, "guest"},
+		{"{{ missing | default 1 }}", "1"},
+		{`{{ 0 | date "2006-01-02" }}`, "1970-01-01"},
+		{"{{ 0 | date layout }}", "1970"},
+		{"{{ 0 | date iso }}", "1970-01-01T00:00:00Z"},
+		{"{{ 0 | date unix }}", "0"},
+		{"{{ -1.5 | upper }}", "-1.5"},
+		{"{{ user | hmac key sha1 }}", "60d04d03c22d4f476670132d64f520559f6f0c9c"},
+		{"{{missing}} and {{user}}", "{{missing}} and ann"},
+		{"{{ user | hmac nokey }}", "{{ user | hmac nokey }}"},
+		{"unclosed {{user", "unclosed {{user"},
+		{"{{#each items}}<li>{{> item}}</li>{{/each}}", "{{#each items}}<li>{{> item}}</li>{{/each}}"},
+		{"{{! a comment }}{{{html}}}{{@index}}{{.}}", "{{! a comment }}{{{html}}}{{@index}}{{.}}"},
+		{"{{first name}} {{}}", "{{first name}} {{}}"},
+		{"{{#if user}}{{user}}{{else}}none{{/if}}", "{{#if user}}ann{{else}}none{{/if}}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Render(tt.text, variables)
+			if err != nil {
+				t.Fatalf("Render(%q): %v", tt.text, err)
+			}
+			if got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"{{$env.CURLMAN_TEST_UNSET}}", "{{$env.CURLMAN_TEST_UNSET}}"},
+		{"{{$nothing}}", "{{$nothing}}"},
+		{"{{ 0 | date }}", "expects a layout"},
+		{"{{ user | shout }}", "shout"},
+		{`{{ user | hmac key "md4" }}`, "md4"},
+		{`{{ "unclosed }}`, "unclosed"},
+		{"{{ user | }}", "empty function"},
+		{"a {{base}}", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			vars := variables
+			if tt.want == "" {
+				vars = map[string]string{"base": "{{host}}", "host": "{{base}}"}
+			}
+			got, err := Render(tt.text, vars)
+			if err == nil {
+				t.Fatalf("Render(%q) = %q, want an error", tt.text, got)
+			}
+			if tt.want == "" {
+				var cycle *CycleError
+				if !errors.As(err, &cycle) {
+					t.Errorf("Render(%q) error = %v, want a cycle", tt.text, err)
+				}
+				return
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Render(%q) error = %v, want it to mention %q", tt.text, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"{{base}}/{{user}}", nil},
+		{"{{missing}}", []string{"missing"}},
+		{"{{ user | hmac nokey }}", []string{"nokey"}},
+		{"{{ missing | hmac nokey | upper }}", []string{"missing", "nokey"}},
+		{"{{ missing | default other }}", []string{"other"}},
+		{`{{ missing | default "x" }}`, nil},
+		{"{{ 0 | date datelayout }}", []string{"datelayout"}},
+		{"{{$uuid}}", nil},
+		{"{{ 0 | date iso }}", nil},
+		{"{{inf}} {{nan}} {{infinity}} {{1e3}}", []string{"inf", "nan", "infinity", "1e3"}},
+		{"{{#if missing}}a{{else}}b{{/if}}", nil},
+		{"{{#if a}}{{else if b}}{{ else }}{{/if}}", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Check(tt.text, variables)
+			if err != nil {
+				t.Fatalf("Check(%q): %v", tt.text, err)
+			}
+			if len(got) == 0 && len(tt.want) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Check("{{ user | shout }}", variables); err == nil {
+		t.Errorf("Check with an unknown function succeeded, want an error")
+	}
+}
+
+func TestUses(t *testing.T) {
+	got := Uses(`{{base}}/{{ user | hmac key sha1 }}/{{missing}}{{#each items}}{{ 0 | date layout }}{{ 0 | date iso }}`, variables)
+	want := []string{"base", "host", "user", "key", "missing", "layout"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Uses = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"github.com/leobrines/curlman/models"
+	"github.com/leobrines/curlman/oauth2"
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// authorizeOAuth2 fetches a token for the OAuth2 auth of the selected request in the background
+// The authorization code grant opens a browser and waits for its callback
+func (m Model) authorizeOAuth2() (tea.Model, tea.Cmd) {
+	req := m.collection.Requests[m.selectedRequest]
+	auth := m.collection.EffectiveAuth(req)
+	if auth == nil || auth.Type != models.AuthOAuth2 {
+		m.message = "This request does not use OAuth2 auth"
+		return m, nil
+	}
+
+	if auth.OAuth2.Grant == models.GrantAuthorizationCode {
+		m.message = "Waiting for authorization in the browser..."
+	} else {
+		m.message = "Fetching OAuth2 token..."
+	}
+
+	collection := m.collection
+	allVars, secretErr := m.variableService.GetAllVariables(collection)
+	if _, err := m.variableService.FindUnresolvedVariables(collection.Resolve(req), allVars, secretErr); err != nil {
+		m.message = fmt.Sprintf("Error: %s", err)
+		return m, nil
+	}
+	authService := m.authService
+	return m, func() tea.Msg {
+		// The TUI cannot show the URL while waiting; `curlman auth -no-browser` prints it instead
+		open := func(authURL string) {
+			oauth2.OpenBrowser(authURL)
+		}
+		token, err := authService.AuthorizeOAuth2(context.Background(), collection, req, allVars, open)
+		return oauthTokenMsg{token: token, err: err}
+	}
+}
@@ -0,0 +1,294 @@
+package ui
+
+import (
+	"github.com/leobrines/curlman/services"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func (m Model) viewCollections() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Collections"))
+	s.WriteString("\n\n")
+
+	if len(m.availableCollections) == 0 {
+		s.WriteString(dimStyle.Render("No saved collections yet."))
+		s.WriteString("\n")
+	} else {
+		for i, name := range m.availableCollections {
+			line := name
+			if name == m.collectionFile {
+				line = fmt.Sprintf("%s (open)", name)
+			}
+
+			if i == m.cursor && !m.collectionActionFocus {
+				s.WriteString(selectedStyle.Render("> " + line))
+			} else if i == m.cursor {
+				s.WriteString("  " + line + " ←")
+			} else {
+				s.WriteString("  " + line)
+			}
+			s.WriteString("\n")
+		}
+	}
+
+	// Actions menu
+	s.WriteString("\nActions:\n")
+	actions := []string{
+		"Open Collection",
+		"Create New Collection",
+		"Rename Collection",
+		"Duplicate Collection",
+		"Delete Collection",
+		"Export to Postman",
+	}
+
+	for i, action := range actions {
+		if i == m.collectionActionCursor && m.collectionActionFocus {
+			s.WriteString(selectedStyle.Render("> "+action) + "\n")
+		} else {
+			s.WriteString("  " + action + "\n")
+		}
+	}
+
+	s.WriteString("\n")
+	s.WriteString(dimStyle.Render("↑/↓: navigate | enter: select | esc: back"))
+	s.WriteString("\n")
+
+	if m.editing {
+		s.WriteString("\n" + m.message + "\n")
+		s.WriteString(m.textInput.View())
+	} else if m.message != "" {
+		s.WriteString("\n" + successStyle.Render(m.message))
+	}
+
+	return s.String()
+}
+
+// handleCollectionAction runs the selected action of the collections view
+func (m Model) handleCollectionAction() (tea.Model, tea.Cmd) {
+	selected := ""
+	if m.cursor >= 0 && m.cursor < len(m.availableCollections) {
+		selected = m.availableCollections[m.cursor]
+	}
+
+	switch m.collectionActionCursor {
+	case 0: // Open Collection
+		if selected == "" {
+			m.message = "No collection selected"
+			return m, nil
+		}
+		if err := m.openCollection(selected); err != nil {
+			m.message = fmt.Sprintf("Error opening collection: %s", err)
+			return m, nil
+		}
+		m.currentView = viewMain
+		m.collectionActionFocus = false
+		m.message = fmt.Sprintf("Opened collection: %s", m.collection.Name)
+	case 1: // Create New Collection
+		m.startEditingCollection(editCollectionCreate, "Enter new collection name:", "")
+	case 2: // Rename Collection
+		if selected == "" {
+			m.message = "No collection selected"
+			return m, nil
+		}
+		collection, err := m.collectionService.LoadCollection(selected)
+		if err != nil {
+			m.message = fmt.Sprintf("Error: %s", err)
+			return m, nil
+		}
+		m.startEditingCollection(editCollectionRename, "Enter new collection name:", collection.Name)
+	case 3: // Duplicate Collection
+		if selected == "" {
+			m.message = "No collection selected"
+			return m, nil
+		}
+		collection, err := m.collectionService.LoadCollection(selected)
+		if err != nil {
+			m.message = fmt.Sprintf("Error: %s", err)
+			return m, nil
+		}
+		m.startEditingCollection(editCollectionDuplicate, "Enter name for the copy:", collection.Name+" Copy")
+	case 4: // Delete Collection
+		if selected == "" {
+			m.message = "No collection selected"
+			return m, nil
+		}
+		if err := m.collectionService.DeleteCollection(selected); err != nil {
+			m.message = fmt.Sprintf("Error: %s", err)
+			return m, nil
+		}
+		if selected == m.collectionFile {
+			// Keep the open collection in memory, but it is no longer backed by a file
+			m.collectionFile = ""
+			m.collection.File = ""
+		}
+		m.refreshCollections()
+		if m.cursor >= len(m.availableCollections) && m.cursor > 0 {
+			m.cursor--
+		}
+		m.message = fmt.Sprintf("Collection '%s' deleted", selected)
+	case 5: // Export to Postman
+		if selected == "" {
+			m.message = "No collection selected"
+			return m, nil
+		}
+		m.startEditingCollection(editPostmanExport, "Enter Postman file path:", strings.TrimSuffix(selected, ".json")+".postman_collection.json")
+	}
+
+	return m, nil
+}
+
+// handleCollectionInput completes create, rename and duplicate prompts of the collections view
+func (m Model) handleCollectionInput(value string) (tea.Model, tea.Cmd) {
+	selected := ""
+	if m.cursor >= 0 && m.cursor < len(m.availableCollections) {
+		selected = m.availableCollections[m.cursor]
+	}
+
+	switch m.editingField {
+	case editCollectionCreate:
+		_, fileName, err := m.collectionService.CreateCollection(value)
+		if err != nil {
+			m.message = fmt.Sprintf("Error: %s", err)
+			return m, nil
+		}
+		if err := m.openCollection(fileName); err != nil {
+			m.message = fmt.Sprintf("Error opening collection: %s", err)
+			return m, nil
+		}
+		m.refreshCollections()
+		m.selectCollection(fileName)
+		m.message = fmt.Sprintf("Collection '%s' created", value)
+	case editCollectionRename:
+		newFileName, err := m.collectionService.RenameCollection(selected, value)
+		if err != nil {
+			m.message = fmt.Sprintf("Error: %s", err)
+			return m, nil
+		}
+		m.message = fmt.Sprintf("Collection renamed to '%s'", value)
+		if selected == m.collectionFile {
+			m.collection.Name = value
+			m.collection.File = newFileName
+			m.collectionFile = newFileName
+			if err := m.globalConfig.SetLastCollection(newFileName); err != nil {
+				m.message = fmt.Sprintf("Error remembering last collection: %s", err)
+			}
+		}
+		m.refreshCollections()
+		m.selectCollection(newFileName)
+	case editCollectionDuplicate:
+		newFileName, err := m.collectionService.DuplicateCollection(selected, value)
+		if err != nil {
+			m.message = fmt.Sprintf("Error: %s", err)
+			return m, nil
+		}
+		m.refreshCollections()
+		m.selectCollection(newFileName)
+		m.message = fmt.Sprintf("Collection duplicated as '%s'", value)
+	case editPostmanExport:
+		collection, err := m.collectionService.LoadCollection(selected)
+		if err != nil {
+			m.message = fmt.Sprintf("Error: %s", err)
+			return m, nil
+		}
+		if err := m.collectionService.ExportToPostmanFile(collection, value); err != nil {
+			m.message = fmt.Sprintf("Error: %s", err)
+			return m, nil
+		}
+		m.message = fmt.Sprintf("Exported '%s' to %s", collection.Name, value)
+	}
+
+	return m, nil
+}
+
+func (m *Model) startEditingCollection(field editField, prompt, value string) {
+	m.editing = true
+	m.textInput.Focus()
+	m.editingField = field
+	m.textInput.SetValue(value)
+	m.message = prompt
+}
+
+// openCollection saves the current collection and switches to another saved one
+func (m *Model) openCollection(fileName string) error {
+	collection, err := m.collectionService.LoadCollection(fileName)
+	if err != nil {
+		return err
+	}
+
+	// Keep unsaved edits of the collection being left
+	if m.collectionFile != "" && m.collectionFile != fileName {
+		if _, err := m.collectionService.SaveCollection(m.collection, m.collectionFile); err != nil {
+			return fmt.Errorf("failed to save current collection: %w", err)
+		}
+	}
+
+	m.environmentService.RestoreActiveEnvironments(collection)
+	m.collection = collection
+	m.collectionFile = fileName
+	m.response = nil
+	m.selectedRequest = 0
+	if err := m.globalConfig.SetLastCollection(fileName); err != nil {
+		return fmt.Errorf("failed to remember last collection: %w", err)
+	}
+
+	return nil
+}
+
+// refreshCollections reloads the list of saved collections
+func (m *Model) refreshCollections() {
+	names, err := m.collectionService.ListCollections()
+	if err != nil {
+		m.message = fmt.Sprintf("Error loading collections: %s", err)
+		return
+	}
+	m.availableCollections = names
+}
+
+// selectCollection moves the cursor to a collection by file name
+func (m *Model) selectCollection(fileName string) {
+	for i, name := range m.availableCollections {
+		if name == fileName {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// importFile imports a collection file, or a Postman environment into the open collection
+func (m Model) importFile(path string) (tea.Model, tea.Cmd) {
+	format, err := m.collectionService.DetectImportFormat(path)
+	if err != nil {
+		m.message = fmt.Sprintf("Error importing: %s", err)
+		return m, nil
+	}
+
+	if format == services.ImportFormatPostmanEnvironment {
+		env, err := m.environmentService.ImportPostmanEnvironment(m.collection, path)
+		if err != nil {
+			m.message = fmt.Sprintf("Error importing: %s", err)
+			return m, nil
+		}
+		m.message = fmt.Sprintf("Imported environment '%s' with %d variables into %s", env.Name, len(env.Variables), m.collection.Name)
+		return m, nil
+	}
+
+	collection, savedPath, err := m.collectionService.ImportFromFile(path)
+	if err != nil {
+		m.message = fmt.Sprintf("Error importing: %s", err)
+		return m, nil
+	}
+	m.collection = collection
+	m.collectionFile = filepath.Base(savedPath)
+	m.message = fmt.Sprintf("Imported %d requests from %s (saved to %s)", len(collection.Requests), collection.Name, savedPath)
+	if err := m.globalConfig.SetLastCollection(m.collectionFile); err != nil {
+		m.message = fmt.Sprintf("Error remembering last collection: %s", err)
+	}
+
+	return m, nil
+}
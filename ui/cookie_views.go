@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"github.com/leobrines/curlman/cookies"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const cookieSyntax = "name=value; Domain=example.com; Path=/; Expires=... or Max-Age=...; Secure; HttpOnly; HostOnly"
+
+func (m Model) viewCookies() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Cookies"))
+	s.WriteString("\n\n")
+	s.WriteString(dimStyle.Render(fmt.Sprintf("Jar: %s / %s", m.collection.Name, cookies.Scope(m.collection))) + "\n\n")
+
+	if len(m.cookieList) == 0 {
+		s.WriteString(dimStyle.Render("No cookies yet."))
+		s.WriteString("\n\n")
+	}
+
+	for i, cookie := range m.cookieList {
+		line := fmt.Sprintf("%s%s  %s=%s", cookie.Domain, cookie.Path, cookie.Name, cookie.Value)
+		var flags []string
+		if cookie.Expires.IsZero() {
+			flags = append(flags, "session")
+		} else {
+			flags = append(flags, "expires "+cookie.Expires.Local().Format("2006-01-02 15:04"))
+		}
+		if cookie.Secure {
+			flags = append(flags, "secure")
+		}
+		if cookie.HttpOnly {
+			flags = append(flags, "httponly")
+		}
+		if cookie.HostOnly {
+			flags = append(flags, "host only")
+		}
+		line += " " + dimStyle.Render("("+strings.Join(flags, ", ")+")")
+
+		if i == m.cursor {
+			s.WriteString(selectedStyle.Render("> "+line) + "\n")
+		} else {
+			s.WriteString("  " + line + "\n")
+		}
+	}
+
+	if m.cursor == len(m.cookieList) {
+		s.WriteString(selectedStyle.Render("> [Add Cookie]") + "\n")
+	} else {
+		s.WriteString("  [Add Cookie]\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(dimStyle.Render("↑/↓: navigate | enter: edit/add | d: delete | c: clear all | esc: back"))
+	s.WriteString("\n")
+
+	if m.editing {
+		s.WriteString("\n" + m.message + "\n")
+		s.WriteString(m.textInput.View())
+	} else if m.message != "" {
+		s.WriteString("\n" + successStyle.Render(m.message))
+	}
+
+	return s.String()
+}
+
+// openCookies shows the cookie jar of the collection and its active environments
+func (m Model) openCookies() (tea.Model, tea.Cmd) {
+	jar, err := m.cookieService.Jar(m.collection)
+	if err != nil {
+		m.message = fmt.Sprintf("Error loading cookies: %s", err)
+		return m, nil
+	}
+	m.cookieJar = jar
+	m.cookieList = jar.List()
+	m.currentView = viewCookies
+	m.cursor = 0
+	m.message = ""
+	if !jar.Persistent() {
+		m.message = "This collection is not saved: its cookies are kept in memory until it is"
+	}
+	return m, nil
+}
+
+func (m *Model) startEditingCookie() {
+	m.editing = true
+	m.textInput.Focus()
+	m.editingField = editCookie
+	if m.cursor < len(m.cookieList) {
+		m.textInput.SetValue(m.cookieList[m.cursor].String())
+	} else {
+		m.textInput.SetValue("")
+	}
+	m.message = fmt.Sprintf("Cookie (%s):", cookieSyntax)
+}
+
+// handleCookieInput adds the entered cookie, or replaces the selected one
+func (m Model) handleCookieInput(value string) (tea.Model, tea.Cmd) {
+	var old *cookies.Cookie
+	if m.cursor < len(m.cookieList) {
+		old = m.cookieList[m.cursor]
+	}
+
+	cookie, err := m.cookieService.SetCookie(m.cookieJar, old, value)
+	if err != nil {
+		m.message = fmt.Sprintf("Error: %s", err)
+		return m, nil
+	}
+
+	m.cookieList = m.cookieJar.List()
+	m.cursor = len(m.cookieList)
+	for i, c := range m.cookieList {
+		if c == cookie {
+			m.cursor = i
+		}
+	}
+	m.message = fmt.Sprintf("Cookie %s saved", cookie.Name)
+	return m, nil
+}
+
+// deleteCookie removes the selected cookie from the jar
+func (m Model) deleteCookie() (tea.Model, tea.Cmd) {
+	if m.cursor >= len(m.cookieList) {
+		return m, nil
+	}
+	if err := m.cookieService.DeleteCookie(m.cookieJar, m.cookieList[m.cursor]); err != nil {
+		m.message = fmt.Sprintf("Error deleting cookie: %s", err)
+		return m, nil
+	}
+	m.cookieList = m.cookieJar.List()
+	if m.cursor >= len(m.cookieList) && m.cursor > 0 {
+		m.cursor--
+	}
+	m.message = "Cookie deleted"
+	return m, nil
+}
+
+// clearCookies empties the jar
+func (m Model) clearCookies() (tea.Model, tea.Cmd) {
+	if err := m.cookieService.ClearCookies(m.cookieJar); err != nil {
+		m.message = fmt.Sprintf("Error clearing cookies: %s", err)
+		return m, nil
+	}
+	m.cookieList = nil
+	m.cursor = 0
+	m.message = "Cookies cleared"
+	return m, nil
+}
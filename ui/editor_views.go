@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"github.com/leobrines/curlman/models"
 	"fmt"
 	"strings"
 )
@@ -74,3 +75,129 @@ func (m Model) viewQueryParams() string {
 
 	return s.String()
 }
+
+func (m Model) viewAssertions() string {
+	if m.selectedRequest < 0 || m.selectedRequest >= len(m.collection.Requests) {
+		return "No request selected"
+	}
+
+	req := m.collection.Requests[m.selectedRequest]
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Assertions"))
+	s.WriteString("\n\n")
+
+	if len(req.Assertions) == 0 {
+		s.WriteString(dimStyle.Render("No assertions set. Press 'enter' to add one."))
+	} else {
+		for i, a := range req.Assertions {
+			if i == m.cursor {
+				s.WriteString(selectedStyle.Render("> "+a.String()) + "\n")
+			} else {
+				s.WriteString("  " + a.String() + "\n")
+			}
+		}
+	}
+
+	s.WriteString("\n\n")
+	s.WriteString(dimStyle.Render("↑/↓: navigate | enter: add assertion | d: delete | esc: back"))
+	s.WriteString("\n")
+
+	if m.editing {
+		s.WriteString("\n" + m.message + "\n")
+		s.WriteString(m.textInput.View())
+	}
+
+	if m.message != "" && !m.editing {
+		s.WriteString("\n" + successStyle.Render(m.message))
+	}
+
+	return s.String()
+}
+
+func (m Model) viewExtractions() string {
+	if m.selectedRequest < 0 || m.selectedRequest >= len(m.collection.Requests) {
+		return "No request selected"
+	}
+
+	req := m.collection.Requests[m.selectedRequest]
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Extractions"))
+	s.WriteString("\n\n")
+
+	if len(req.Extractions) == 0 {
+		s.WriteString(dimStyle.Render("No extractions set. Press 'enter' to add one."))
+	} else {
+		for i, e := range req.Extractions {
+			if i == m.cursor {
+				s.WriteString(selectedStyle.Render("> "+e.String()) + "\n")
+			} else {
+				s.WriteString("  " + e.String() + "\n")
+			}
+		}
+	}
+
+	s.WriteString("\n\n")
+	s.WriteString(dimStyle.Render("↑/↓: navigate | enter: add extraction | d: delete | esc: back"))
+	s.WriteString("\n")
+
+	if m.editing {
+		s.WriteString("\n" + m.message + "\n")
+		s.WriteString(m.textInput.View())
+	}
+
+	if m.message != "" && !m.editing {
+		s.WriteString("\n" + successStyle.Render(m.message))
+	}
+
+	return s.String()
+}
+
+func (m Model) viewFormFields() string {
+	if m.selectedRequest < 0 || m.selectedRequest >= len(m.collection.Requests) {
+		return "No request selected"
+	}
+
+	req := m.collection.Requests[m.selectedRequest]
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Body Fields"))
+	s.WriteString("\n\n")
+	s.WriteString(fmt.Sprintf("Body mode: %s", req.GetBodyMode()))
+	if mode := req.GetBodyMode(); mode != models.BodyURLEncoded && mode != models.BodyFormData {
+		s.WriteString(dimStyle.Render(" (fields are sent in urlencoded and formdata modes only)"))
+	}
+	s.WriteString("\n\n")
+
+	if len(req.FormFields) == 0 {
+		s.WriteString(dimStyle.Render("No fields set. Press 'enter' to add one."))
+	} else {
+		for i, field := range req.FormFields {
+			line := field.String()
+			if field.IsFile() {
+				line += " (file)"
+			}
+			if i == m.cursor {
+				s.WriteString(selectedStyle.Render("> "+line) + "\n")
+			} else {
+				s.WriteString("  " + line + "\n")
+			}
+		}
+	}
+
+	s.WriteString("\n\n")
+	s.WriteString(dimStyle.Render("↑/↓: navigate | enter: add field | d: delete | esc: back"))
+	s.WriteString("\n")
+
+	if m.editing {
+		s.WriteString("\n" + m.message + "\n")
+		s.WriteString(m.textInput.View())
+	}
+
+	if m.message != "" && !m.editing {
+		s.WriteString("\n" + successStyle.Render(m.message))
+	}
+
+	return s.String()
+}
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"github.com/leobrines/curlman/secrets"
 	"fmt"
 	"strings"
 )
@@ -195,13 +196,17 @@ func (m Model) viewEnvironmentVariables() string {
 	if len(variables) == 0 {
 		s.WriteString(dimStyle.Render("No variables set. Press 'enter' to add one."))
 	} else {
-		for k, v := range variables {
-			s.WriteString(fmt.Sprintf("%s = %s\n", k, v))
+		scope := secrets.EnvironmentScope(envName)
+		if m.viewingCollectionEnv {
+			scope = secrets.CollectionEnvironmentScope(m.collection.File, envName)
+		}
+		for k := range variables {
+			s.WriteString(fmt.Sprintf("%s = %s\n", k, m.variableValue(variables, scope, k)))
 		}
 	}
 
 	s.WriteString("\n\n")
-	s.WriteString(dimStyle.Render("enter: add variable | esc: back"))
+	s.WriteString(dimStyle.Render("enter: add variable | s: mark/unmark secret | r: reveal/mask secrets | esc: back"))
 	s.WriteString("\n")
 
 	if m.editing {
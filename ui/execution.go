@@ -0,0 +1,290 @@
+package ui
+
+import (
+	"github.com/leobrines/curlman/executor"
+	"github.com/leobrines/curlman/history"
+	"github.com/leobrines/curlman/models"
+	"github.com/leobrines/curlman/services"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// responseMsg delivers the outcome of a request executed in the background
+type responseMsg struct {
+	id        int             // Execution the response belongs to
+	request   *models.Request // Request as stored in the collection
+	resolved  *models.Request // Request with the inherited auth and settings applied
+	variables map[string]string
+	response  *executor.Response
+	err       error
+}
+
+// runReportMsg delivers the report of a collection or folder run executed in the background
+type runReportMsg struct {
+	id     int
+	report *services.RunReport
+	err    error
+}
+
+// replayMsg delivers the response of a history entry replayed in the background
+type replayMsg struct {
+	id       int
+	response *executor.Response
+	err      error
+}
+
+// executeRequest starts executing the selected request in the background
+func (m Model) executeRequest() (tea.Model, tea.Cmd) {
+	return m.startExecution("", nil, false)
+}
+
+// downloadRequest starts executing the selected request in the background, streaming its body to file
+func (m Model) downloadRequest(file string) (tea.Model, tea.Cmd) {
+	if file == "" {
+		m.message = "Error: enter a file name to download to"
+		return m, nil
+	}
+	return m.startExecution(file, nil, false)
+}
+
+// downloadFileName suggests a file name for a download from the last segment of the request path
+func downloadFileName(req *models.Request) string {
+	name := path.Base(strings.SplitN(req.Path, "?", 2)[0])
+	if name == "." || name == "/" || strings.Contains(name, "{{") {
+		return "response.bin"
+	}
+	return name
+}
+
+// startExecution runs the selected request; a non-empty file downloads its body to that file
+// One-off values fill in variables for this execution only; undefined variables are prompted for first,
+// unless the request is sent anyway with their {{placeholders}} as written
+func (m Model) startExecution(file string, oneOff map[string]string, anyway bool) (tea.Model, tea.Cmd) {
+	req := m.collection.Requests[m.selectedRequest]
+	allVars, secretErr := m.variableService.GetAllVariables(m.collection)
+	for k, v := range oneOff {
+		allVars[k] = v
+	}
+	resolved := m.collection.Resolve(req)
+	unresolved, err := m.variableService.FindUnresolvedVariables(resolved, allVars, secretErr)
+	if err != nil {
+		m.message = fmt.Sprintf("Error: %s", err)
+		return m, nil
+	}
+	if len(unresolved) > 0 && !anyway {
+		return m.promptUnresolved(unresolved, file, false)
+	}
+	jar, err := m.cookieService.Jar(m.collection)
+	if err != nil {
+		m.message = fmt.Sprintf("Error loading cookies: %s", err)
+		return m, nil
+	}
+
+	label := "Executing " + req.Name
+	if file != "" {
+		label = fmt.Sprintf("Downloading %s to %s", req.Name, file)
+	}
+	id, ctx := m.startBackground("Request", label)
+	if file != "" {
+		m.downloadProgress = &executor.Progress{}
+	}
+
+	requestService := m.requestService
+	progress := m.downloadProgress
+	execute := func() tea.Msg {
+		var response *executor.Response
+		var err error
+		if file != "" {
+			response, err = requestService.DownloadRequest(ctx, resolved, allVars, jar, file, progress, anyway)
+		} else {
+			response, err = requestService.ExecuteRequest(ctx, resolved, allVars, jar, anyway)
+		}
+		return responseMsg{id: id, request: req, resolved: resolved, variables: allVars, response: response, err: err}
+	}
+	return m, tea.Batch(execute, m.spinner.Tick)
+}
+
+// startRun runs the requests of the collection, or of a folder when path is set, in the background
+func (m Model) startRun(path string) (tea.Model, tea.Cmd) {
+	label := "Running " + m.collection.Name
+	if path != "" {
+		label = "Running folder " + path
+	}
+	id, ctx := m.startBackground("Run", label)
+
+	runnerService := m.runnerService
+	collection := m.collection
+	run := func() tea.Msg {
+		var report *services.RunReport
+		var err error
+		if path != "" {
+			report, err = runnerService.RunFolder(ctx, collection, path, false)
+		} else {
+			report, err = runnerService.RunCollection(ctx, collection, nil, false)
+		}
+		return runReportMsg{id: id, report: report, err: err}
+	}
+	return m, tea.Batch(run, m.spinner.Tick)
+}
+
+// startReplay sends the request of a history entry again in the background
+func (m Model) startReplay(entry *history.Entry) (tea.Model, tea.Cmd) {
+	id, ctx := m.startBackground("Replay", "Replaying "+entry.Request.Method+" "+entry.Request.FullURL())
+
+	historyService := m.historyService
+	replay := func() tea.Msg {
+		response, err := historyService.Replay(ctx, entry)
+		return replayMsg{id: id, response: response, err: err}
+	}
+	return m, tea.Batch(replay, m.spinner.Tick)
+}
+
+// startBackground marks an execution as running and returns its id and the context cancelling it
+// kind names it in the cancel message, label in the progress line
+func (m *Model) startBackground(kind, label string) (int, context.Context) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.executionID++
+	m.executing = true
+	m.executionStart = time.Now()
+	m.cancelExecution = cancel
+	m.executionKind = kind
+	m.executionLabel = label
+	m.downloadProgress = nil
+	m.message = ""
+	return m.executionID, ctx
+}
+
+// finishBackground ends the running execution, reporting false for results of cancelled ones
+func (m *Model) finishBackground(id int) bool {
+	if !m.executing || id != m.executionID {
+		return false
+	}
+	m.cancelExecution()
+	m.executing = false
+	m.cancelExecution = nil
+	return true
+}
+
+// exportToCurl shows the selected request as a curl command, masking secrets unless they are revealed
+// One-off values fill in variables for this export only; undefined variables are prompted for first,
+// unless the request is exported anyway with their {{placeholders}} as written
+func (m Model) exportToCurl(oneOff map[string]string, anyway bool) (tea.Model, tea.Cmd) {
+	req := m.collection.Requests[m.selectedRequest]
+	allVars, secretErr := m.variableService.GetAllVariables(m.collection)
+	for k, v := range oneOff {
+		allVars[k] = v
+	}
+	resolved := m.collection.Resolve(req)
+	unresolved, err := m.variableService.FindUnresolvedVariables(resolved, allVars, secretErr)
+	if err != nil {
+		m.message = fmt.Sprintf("Error exporting: %s", err)
+		return m, nil
+	}
+	if len(unresolved) > 0 && !anyway {
+		return m.promptUnresolved(unresolved, "", true)
+	}
+
+	curlCmd, err := m.requestService.ExportToCurl(resolved, allVars, anyway)
+	if err != nil {
+		m.message = fmt.Sprintf("Error exporting: %s", err)
+		return m, nil
+	}
+	if !m.revealSecrets {
+		curlCmd = m.variableService.Mask(m.collection, curlCmd)
+	}
+	m.message = "Curl command:\n" + curlCmd
+	return m, nil
+}
+
+// handleResponse shows the response of the running request
+// Responses of cancelled executions are dropped
+func (m Model) handleResponse(msg responseMsg) (tea.Model, tea.Cmd) {
+	if !m.finishBackground(msg.id) {
+		return m, nil
+	}
+
+	if msg.err != nil && msg.response == nil {
+		m.message = fmt.Sprintf("Error executing request: %s", msg.err)
+		return m, nil
+	}
+
+	if msg.response.BodyFile != "" && msg.response.Error == nil {
+		m.message = fmt.Sprintf("Downloaded %s to %s", executor.FormatSize(msg.response.Size), msg.response.BodyFile)
+	}
+
+	m.historyService.Record(m.collection, msg.resolved, msg.response)
+	assertions := m.requestService.CheckAssertions(msg.request, msg.response, msg.variables)
+	extractions := m.extractionService.ApplyExtractions(m.collection, msg.request, msg.response)
+	m.showResponse(msg.response, assertions, extractions, viewRequestDetail)
+	// The response arrived, but its cookies could not be saved
+	if msg.err != nil {
+		m.message = fmt.Sprintf("Warning: %s", msg.err)
+	}
+	return m, nil
+}
+
+// handleRunReport shows the report of the finished run
+func (m Model) handleRunReport(msg runReportMsg) (tea.Model, tea.Cmd) {
+	if !m.finishBackground(msg.id) {
+		return m, nil
+	}
+
+	if msg.err != nil {
+		m.message = fmt.Sprintf("Error running requests: %s", msg.err)
+		return m, nil
+	}
+	m.runReport = msg.report
+	m.currentView = viewRunReport
+	m.cursor = 0
+	return m, nil
+}
+
+// handleReplay shows the response of the replayed history entry
+func (m Model) handleReplay(msg replayMsg) (tea.Model, tea.Cmd) {
+	if !m.finishBackground(msg.id) {
+		return m, nil
+	}
+
+	if msg.err != nil {
+		m.message = fmt.Sprintf("Error replaying request: %s", msg.err)
+		return m, nil
+	}
+	m.showResponse(msg.response, nil, nil, viewHistory)
+	m.loadHistory()
+	m.cursor = 0
+	return m, nil
+}
+
+// cancelRequest aborts the running request, run or replay
+func (m Model) cancelRequest() (tea.Model, tea.Cmd) {
+	m.cancelExecution()
+	m.executing = false
+	m.cancelExecution = nil
+	m.message = fmt.Sprintf("%s cancelled after %s", m.executionKind, m.executionElapsed())
+	return m, nil
+}
+
+// executionElapsed is the time spent on the running request
+func (m Model) executionElapsed() time.Duration {
+	return time.Since(m.executionStart).Truncate(100 * time.Millisecond)
+}
+
+// viewExecuting renders the progress line of the running request, run or replay
+func (m Model) viewExecuting() string {
+	if m.downloadProgress == nil {
+		return fmt.Sprintf("%s %s... %s ", m.spinner.View(), m.executionLabel, m.executionElapsed()) +
+			dimStyle.Render("(esc: cancel)")
+	}
+
+	received := executor.FormatSize(m.downloadProgress.Received())
+	if total := m.downloadProgress.Total(); total > 0 {
+		received = fmt.Sprintf("%s / %s (%d%%)", received, executor.FormatSize(total), m.downloadProgress.Received()*100/total)
+	}
+	return fmt.Sprintf("%s %s... %s  %s ", m.spinner.View(), m.executionLabel, received, m.executionElapsed()) +
+		dimStyle.Render("(esc: cancel)")
+}
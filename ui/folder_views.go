@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"github.com/leobrines/curlman/models"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// requestRow is a line of the request tree: a folder or a request
+type requestRow struct {
+	folder  string // Folder path, set for folder rows
+	request int    // Index into collection.Requests, -1 for folder rows
+	depth   int
+}
+
+// isFolder reports whether the row shows a folder
+func (r requestRow) isFolder() bool {
+	return r.request < 0
+}
+
+// requestRows flattens the folder tree of the collection into visible rows
+// Folders come before requests at each level; collapsed folders hide their contents
+func (m Model) requestRows() []requestRow {
+	rows := []requestRow{}
+
+	var add func(parent string, depth int)
+	add = func(parent string, depth int) {
+		for _, path := range m.collection.SubFolders(parent) {
+			rows = append(rows, requestRow{folder: path, request: -1, depth: depth})
+			if !m.collapsedFolders[path] {
+				add(path, depth+1)
+			}
+		}
+		for i, req := range m.collection.Requests {
+			if req.Folder == parent {
+				rows = append(rows, requestRow{request: i, depth: depth})
+			}
+		}
+	}
+	add("", 0)
+
+	return rows
+}
+
+// renderRequestRow renders a row of the request tree
+func (m Model) renderRequestRow(row requestRow) string {
+	indent := strings.Repeat("  ", row.depth)
+	if row.isFolder() {
+		marker := "▾"
+		if m.collapsedFolders[row.folder] {
+			marker = "▸"
+		}
+		count := len(m.collection.RequestsInFolder(row.folder))
+		return fmt.Sprintf("%s%s %s/ (%d)", indent, marker, models.FolderName(row.folder), count)
+	}
+
+	req := m.collection.Requests[row.request]
+	return fmt.Sprintf("%s[%s] %s", indent, req.Method, req.Name)
+}
+
+// selectedRequestRow returns the tree row under the cursor
+func (m Model) selectedRequestRow() (requestRow, bool) {
+	rows := m.requestRows()
+	if m.cursor >= 0 && m.cursor < len(rows) {
+		return rows[m.cursor], true
+	}
+	return requestRow{}, false
+}
+
+// focusFolderRow moves the cursor to a folder row, expanding its parents
+func (m *Model) focusFolderRow(path string) {
+	for parent := models.FolderParent(path); parent != ""; parent = models.FolderParent(parent) {
+		delete(m.collapsedFolders, parent)
+	}
+	for i, row := range m.requestRows() {
+		if row.isFolder() && row.folder == path {
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// deleteRequestRow deletes the request or folder under the cursor
+func (m Model) deleteRequestRow(row requestRow) (tea.Model, tea.Cmd) {
+	if row.isFolder() {
+		if err := m.folderService.DeleteFolder(m.collection, row.folder); err != nil {
+			m.message = fmt.Sprintf("Error deleting folder: %s", err)
+			return m, nil
+		}
+		delete(m.collapsedFolders, row.folder)
+		m.message = fmt.Sprintf("Folder '%s' deleted, its requests moved up", row.folder)
+		return m, nil
+	}
+
+	if err := m.requestService.DeleteRequest(m.collection, row.request); err != nil {
+		m.message = fmt.Sprintf("Error deleting request: %s", err)
+		return m, nil
+	}
+	if m.cursor >= len(m.requestRows()) && m.cursor > 0 {
+		m.cursor--
+	}
+	m.message = "Request deleted"
+	return m, nil
+}
+
+// startFolderPrompt opens a text prompt for a folder action of the request list
+func (m *Model) startFolderPrompt(field editField, prompt, value string) {
+	m.editing = true
+	m.textInput.Focus()
+	m.editingField = field
+	m.textInput.SetValue(value)
+	m.message = prompt
+}
+
+// handleFolderInput completes the folder and auth prompts of the request list
+func (m Model) handleFolderInput(value string) (tea.Model, tea.Cmd) {
+	row, _ := m.selectedRequestRow()
+
+	switch m.editingField {
+	case editFolderCreate:
+		path, err := m.folderService.CreateFolder(m.collection, value)
+		if err != nil {
+			m.message = fmt.Sprintf("Error: %s", err)
+			return m, nil
+		}
+		m.focusFolderRow(path)
+		m.message = fmt.Sprintf("Folder '%s' created", path)
+	case editFolderRename:
+		path, err := m.folderService.RenameFolder(m.collection, row.folder, value)
+		if err != nil {
+			m.message = fmt.Sprintf("Error: %s", err)
+			return m, nil
+		}
+		if m.collapsedFolders[row.folder] {
+			delete(m.collapsedFolders, row.folder)
+			m.collapsedFolders[path] = true
+		}
+		m.focusFolderRow(path)
+		m.message = fmt.Sprintf("Folder renamed to '%s'", path)
+	case editFolderAuth:
+		if err := m.authService.SetFolderAuth(m.collection, row.folder, value); err != nil {
+			m.message = fmt.Sprintf("Error: %s", err)
+			return m, nil
+		}
+		m.message = fmt.Sprintf("Auth of folder '%s' updated", row.folder)
+	case editCollectionAuth:
+		if err := m.authService.SetCollectionAuth(m.collection, value); err != nil {
+			m.message = fmt.Sprintf("Error: %s", err)
+			return m, nil
+		}
+		m.message = "Collection auth updated"
+	case editRequestFolder:
+		req := m.collection.Requests[row.request]
+		path, err := m.folderService.MoveRequest(m.collection, req, value)
+		if err != nil {
+			m.message = fmt.Sprintf("Error: %s", err)
+			return m, nil
+		}
+		if path == "" {
+			m.message = fmt.Sprintf("Moved '%s' to the top level", req.Name)
+		} else {
+			m.message = fmt.Sprintf("Moved '%s' to %s", req.Name, path)
+		}
+	}
+
+	return m, nil
+}
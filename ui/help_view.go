@@ -22,34 +22,106 @@ func (m Model) viewHelp() string {
 	s.WriteString("Main View:\n")
 	s.WriteString("  ↑/↓ or j/k - Navigate menu\n")
 	s.WriteString("  enter - Select menu item\n")
+	s.WriteString("  Import Collection File - OpenAPI, Postman, Insomnia or HAR file, or a Postman\n")
+	s.WriteString("    environment added to the open collection\n")
 	s.WriteString("  q - Quit application\n\n")
 
+	s.WriteString("Cookies View:\n")
+	s.WriteString("  Cookies of the jar of the collection and its active environments\n")
+	s.WriteString("  enter - Edit selected cookie, or add one: name=value; Domain=example.com; Path=/; Secure; HttpOnly\n")
+	s.WriteString("  d - Delete selected cookie\n")
+	s.WriteString("  c - Clear all cookies\n")
+	s.WriteString("  esc - Back to main\n\n")
+
+	s.WriteString("Collections View:\n")
+	s.WriteString("  ↑/↓ - Navigate saved collections\n")
+	s.WriteString("  enter - Switch to the actions menu / run the selected action\n")
+	s.WriteString("  Actions: Open, Create, Rename, Duplicate, Delete, Export to Postman\n")
+	s.WriteString("  The last opened collection is reopened at startup\n")
+	s.WriteString("  esc - Back to main\n\n")
+
 	s.WriteString("Request List View:\n")
 	s.WriteString("  ↑/↓ or j/k - Navigate requests\n")
-	s.WriteString("  enter - View request details or create new\n")
-	s.WriteString("  d - Delete selected request\n")
+	s.WriteString("  enter - View request details, expand/collapse a folder, or create new\n")
+	s.WriteString("  [Import from cURL] - Paste a curl command to add it as a request\n")
+	s.WriteString("  [New Folder] - Create a folder (use / to nest, e.g. Users/Admin)\n")
+	s.WriteString("  d - Delete selected request, or folder (its requests move up)\n")
+	s.WriteString("  m - Move selected request to a folder\n")
+	s.WriteString("  r - Run every request of the selected folder\n")
+	s.WriteString("  e - Rename or move the selected folder\n")
+	s.WriteString("  a - Set the auth of the selected folder, or of the collection\n")
+	s.WriteString("  c - Edit the client settings of the collection\n")
 	s.WriteString("  esc - Back to main\n\n")
 
 	s.WriteString("Request Detail View:\n")
 	s.WriteString("  ↑/↓ or j/k - Navigate actions\n")
 	s.WriteString("  enter - Execute selected action\n")
-	s.WriteString("  Actions: Execute, Edit, Headers, Query Params, Clone, Export\n")
-	s.WriteString("  esc - Back to request list\n\n")
+	s.WriteString("  Actions: Execute, Edit, Headers, Query Params, Body Fields, Auth, Client Settings, Assertions, Extractions, Clone, Export, Download\n")
+	s.WriteString("  Auth: inherit, none, basic user pass, bearer token, apikey header|query name value, digest user pass,\n")
+	s.WriteString("        oauth2 client_credentials|password|refresh_token|authorization_code token_url=... [auth_url=... redirect_url=...\n")
+	s.WriteString("        client_id=... client_secret=... scope=\"a b\" refresh_token=... username=... password=...]\n")
+	s.WriteString("  o - Fetch an OAuth2 token (opens a browser for the authorization code grant)\n")
+	s.WriteString("  Execute and Export warn about undefined variables: enter values for them, then optionally save them,\n")
+	s.WriteString("  or send/export anyway with the {{placeholders}} as written\n")
+	s.WriteString("    to collection, global or environment (empty uses them once)\n")
+	s.WriteString("  esc - Cancel the running request, or back to request list\n\n")
 
 	s.WriteString("Request Edit View:\n")
 	s.WriteString("  ↑/↓ - Navigate fields\n")
 	s.WriteString("  enter - Edit selected field\n")
+	s.WriteString("  Body Mode: raw, urlencoded, formdata or binary (sends Body File)\n")
 	s.WriteString("  esc - Back to request detail\n\n")
 
+	s.WriteString("Client Settings View:\n")
+	s.WriteString("  enter - Edit selected setting (empty to unset)\n")
+	s.WriteString("  Settings: timeout, follow_redirects, max_redirects, proxy, no_proxy, insecure, ca_cert, client_cert, client_key, http_version, cookies\n")
+	s.WriteString("  Request settings override the collection's\n")
+	s.WriteString("  esc - Back\n\n")
+
 	s.WriteString("Response View:\n")
-	s.WriteString("  s - Save response body to file\n")
+	s.WriteString("  tab/shift+tab or 1-4 - Body, Headers, Cookies and Timing (waterfall of DNS, connect, TLS, waiting, transfer) tabs\n")
+	s.WriteString("  ↑/↓, pgup/pgdn, g/G - Scroll | ←/→ - Pan long lines\n")
+	s.WriteString("  enter/space - Fold or unfold the JSON node under the cursor | c/e - Fold/unfold all\n")
+	s.WriteString("  p - Toggle raw/pretty body | w - Toggle line wrapping\n")
+	s.WriteString("  / - Filter the JSON body: $.items[*].id, $..name, $.items[?(@.price < 10)] or .items[] | select(.ok) | .id\n")
+	s.WriteString("  s - Save response body (or the filtered body) to file\n")
+	s.WriteString("  esc - Back to request detail (or history)\n\n")
+
+	s.WriteString("Body Fields View:\n")
+	s.WriteString("  enter - Add field as key=value, or key=@path to upload a file (formdata)\n")
+	s.WriteString("  d - Delete selected field\n")
 	s.WriteString("  esc - Back to request detail\n\n")
 
+	s.WriteString("Assertions View:\n")
+	s.WriteString("  enter - Add assertion, e.g. 'status 200', 'json $.id exists', 'duration max 500ms'\n")
+	s.WriteString("  d - Delete selected assertion\n")
+	s.WriteString("  esc - Back to request detail\n\n")
+
+	s.WriteString("Extractions View:\n")
+	s.WriteString("  enter - Add extraction, e.g. 'json $.token as token', 'header Location as url in environment'\n")
+	s.WriteString("  d - Delete selected extraction\n")
+	s.WriteString("  esc - Back to request detail\n\n")
+
+	s.WriteString("Run Collection:\n")
+	s.WriteString("  Executes every request in order and shows a pass/fail/timing summary\n")
+	s.WriteString("  A request passes when its assertions pass (or with a status below 400 if it has none)\n")
+	s.WriteString("  esc - Stop the run (the remaining requests are skipped), or back to main\n\n")
+
+	s.WriteString("Request History:\n")
+	s.WriteString("  enter - View the recorded response\n")
+	s.WriteString("  r - Re-run the recorded request\n")
+	s.WriteString("  m - Mark entry for diff (mark two to compare)\n")
+	s.WriteString("  / - Filter by request name, status (404, 5xx) or 'error'\n")
+	s.WriteString("  c - Clear history\n")
+	s.WriteString("  esc - Back to main\n\n")
+
 	s.WriteString("Variables View:\n")
 	s.WriteString("  ↑/↓ or j/k - Navigate variables\n")
 	s.WriteString("  enter - Edit selected variable\n")
 	s.WriteString("  n - Create new variable\n")
 	s.WriteString("  d - Delete selected variable\n")
+	s.WriteString("  Toggle Secret action - Store the value encrypted and mask it in output (values under 4 characters are not masked)\n")
+	s.WriteString("  r - Reveal or mask secret values\n")
 	s.WriteString("  esc - Back to main\n\n")
 
 	s.WriteString("Environment Management:\n")
@@ -61,11 +133,18 @@ func (m Model) viewHelp() string {
 	s.WriteString("  Environment Detail:\n")
 	s.WriteString("    ↑/↓ - Navigate actions\n")
 	s.WriteString("    enter - Execute selected action\n")
-	s.WriteString("    Actions: Activate, Variables, Edit Name, Save, Delete\n\n")
+	s.WriteString("    Actions: Activate, Variables, Edit Name, Save, Delete\n")
+	s.WriteString("  Environment Variables:\n")
+	s.WriteString("    s - Mark a variable secret, or make it plain again\n")
+	s.WriteString("    r - Reveal or mask secret values\n\n")
 
 	s.WriteString("Variables Usage:\n")
 	s.WriteString("  Use {{variable_name}} in requests\n")
+	s.WriteString("  Variable values can reference other variables: base_url = {{scheme}}://{{host}}\n")
+	s.WriteString("  Dynamic values: {{$uuid}}, {{$timestamp}}, {{$isoTimestamp}}, {{$randomInt}}, {{$randomEmail}}, {{$env.NAME}}\n")
+	s.WriteString("  Functions: {{ value | base64 }}, urlencode, sha256, hmac key, upper, default \"x\", date \"2006-01-02\"\n")
 	s.WriteString("  Environment variables override collection variables\n")
+	s.WriteString("  \"Inspect Variables\" shows where each value comes from, what it overrides and which requests use it\n")
 	s.WriteString("  Variables are injected before execution\n\n")
 
 	s.WriteString(dimStyle.Render("Press 'esc' or 'q' to go back"))
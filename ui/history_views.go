@@ -0,0 +1,132 @@
+package ui
+
+import (
+	"github.com/leobrines/curlman/history"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func (m Model) viewHistory() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Request History"))
+	s.WriteString("\n\n")
+
+	if m.historyFilter != "" {
+		s.WriteString(dimStyle.Render(fmt.Sprintf("Filter: %s", m.historyFilter)) + "\n\n")
+	}
+
+	if len(m.historyEntries) == 0 {
+		s.WriteString(dimStyle.Render("No history entries."))
+		s.WriteString("\n")
+	} else {
+		for i, entry := range m.historyEntries {
+			mark := "  "
+			for _, id := range m.historyMarks {
+				if id == entry.ID {
+					mark = "* "
+				}
+			}
+
+			line := mark + entry.Summary()
+			if i == m.cursor {
+				s.WriteString(selectedStyle.Render("> "+line) + "\n")
+			} else if entry.Error != "" || entry.StatusCode >= 400 {
+				s.WriteString(errorStyle.Render("  "+line) + "\n")
+			} else {
+				s.WriteString("  " + line + "\n")
+			}
+		}
+	}
+
+	s.WriteString("\n")
+	s.WriteString(dimStyle.Render("↑/↓: navigate | enter: view | r: re-run | m: mark for diff | /: filter | c: clear | esc: back"))
+	s.WriteString("\n")
+
+	if m.executing {
+		s.WriteString("\n" + m.viewExecuting())
+	} else if m.editing {
+		s.WriteString("\n" + m.message + "\n")
+		s.WriteString(m.textInput.View())
+	} else if m.message != "" {
+		s.WriteString("\n" + successStyle.Render(m.message))
+	}
+
+	return s.String()
+}
+
+func (m Model) viewHistoryDiff() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("History Diff"))
+	s.WriteString("\n\n")
+
+	for _, line := range strings.Split(m.historyDiff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "- "):
+			s.WriteString(errorStyle.Render(line) + "\n")
+		case strings.HasPrefix(line, "+ "):
+			s.WriteString(successStyle.Render(line) + "\n")
+		default:
+			s.WriteString(line + "\n")
+		}
+	}
+
+	s.WriteString("\n")
+	s.WriteString(dimStyle.Render("esc: back"))
+	s.WriteString("\n")
+
+	return s.String()
+}
+
+// loadHistory reloads history entries using the current filter
+func (m *Model) loadHistory() {
+	entries, err := m.historyService.List(m.historyFilter)
+	if err != nil {
+		m.message = fmt.Sprintf("Error loading history: %s", err)
+		return
+	}
+	m.historyEntries = entries
+}
+
+// toggleHistoryMark marks an entry for diffing and opens the diff once two are marked
+func (m Model) toggleHistoryMark(entry *history.Entry) (tea.Model, tea.Cmd) {
+	for i, id := range m.historyMarks {
+		if id == entry.ID {
+			m.historyMarks = append(m.historyMarks[:i], m.historyMarks[i+1:]...)
+			m.message = "Entry unmarked"
+			return m, nil
+		}
+	}
+
+	m.historyMarks = append(m.historyMarks, entry.ID)
+	if len(m.historyMarks) < 2 {
+		m.message = "Entry marked, mark another one to diff"
+		return m, nil
+	}
+
+	// Diff the older entry against the newer one
+	var first, second *history.Entry
+	for _, e := range m.historyEntries {
+		if e.ID == m.historyMarks[0] || e.ID == m.historyMarks[1] {
+			if second == nil {
+				second = e
+			} else {
+				first = e
+			}
+		}
+	}
+	m.historyMarks = nil
+
+	if first == nil || second == nil {
+		m.message = "Marked entries are no longer listed"
+		return m, nil
+	}
+
+	m.historyDiff = history.Diff(first, second)
+	m.currentView = viewHistoryDiff
+	m.message = ""
+	return m, nil
+}
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"github.com/leobrines/curlman/models"
 	"github.com/leobrines/curlman/storage"
 	"fmt"
 	"strings"
@@ -12,7 +13,11 @@ func (m Model) viewMain() string {
 	s.WriteString(titleStyle.Render("CurlMan - Postman CLI Alternative"))
 	s.WriteString("\n\n")
 
-	s.WriteString(fmt.Sprintf("Collection: %s\n", m.collection.Name))
+	if m.collectionFile != "" {
+		s.WriteString(fmt.Sprintf("Collection: %s (%s)\n", m.collection.Name, m.collectionFile))
+	} else {
+		s.WriteString(fmt.Sprintf("Collection: %s (unsaved)\n", m.collection.Name))
+	}
 	s.WriteString(fmt.Sprintf("Requests: %d\n", len(m.collection.Requests)))
 	s.WriteString(fmt.Sprintf("Variables: %d\n", len(m.collection.Variables)))
 
@@ -41,11 +46,16 @@ func (m Model) viewMain() string {
 
 	// Menu items as a selectable list
 	menuItems := []string{
-		"Import OpenAPI YAML",
+		"Import Collection File",
+		"Manage Collections",
 		"View Requests",
+		"Run Collection",
+		"Request History",
 		"Manage Variables",
 		"Manage Global Variables",
+		"Inspect Variables",
 		"Manage Environments",
+		"Manage Cookies",
 		"Save Collection",
 		"Help",
 		"Quit",
@@ -65,7 +75,9 @@ func (m Model) viewMain() string {
 	s.WriteString(dimStyle.Render("↑/↓: navigate | enter: select | q: quit"))
 	s.WriteString("\n")
 
-	if m.editing {
+	if m.executing {
+		s.WriteString("\n" + m.viewExecuting() + "\n")
+	} else if m.editing {
 		s.WriteString("\n" + m.message + "\n")
 		s.WriteString(m.textInput.View() + "\n")
 	} else if m.message != "" {
@@ -81,37 +93,41 @@ func (m Model) viewRequestList() string {
 	s.WriteString(titleStyle.Render("Requests"))
 	s.WriteString("\n\n")
 
-	if len(m.collection.Requests) == 0 {
+	rows := m.requestRows()
+	if len(rows) == 0 {
 		s.WriteString(dimStyle.Render("No requests yet."))
 		s.WriteString("\n\n")
-		// Show "Create New" option
-		cursor := "> "
-		s.WriteString(selectedStyle.Render(cursor + "[Create New Request]") + "\n")
-	} else {
-		for i, req := range m.collection.Requests {
-			cursor := "  "
-			if i == m.cursor {
-				cursor = "> "
-				s.WriteString(selectedStyle.Render(fmt.Sprintf("%s[%s] %s\n", cursor, req.Method, req.Name)))
-			} else {
-				s.WriteString(fmt.Sprintf("%s[%s] %s\n", cursor, req.Method, req.Name))
-			}
+	}
+
+	for i, row := range rows {
+		line := m.renderRequestRow(row)
+		if i == m.cursor {
+			s.WriteString(selectedStyle.Render("> "+line) + "\n")
+		} else {
+			s.WriteString("  " + line + "\n")
 		}
-		// Add "Create New" option at the end
-		cursor := "  "
-		if m.cursor == len(m.collection.Requests) {
-			cursor = "> "
-			s.WriteString(selectedStyle.Render(cursor + "[Create New Request]") + "\n")
+	}
+
+	// Add "Create New", "Import" and "New Folder" options at the end
+	options := []string{"[Create New Request]", "[Import from cURL]", "[New Folder]"}
+	for i, option := range options {
+		if m.cursor == len(rows)+i {
+			s.WriteString(selectedStyle.Render("> "+option) + "\n")
 		} else {
-			s.WriteString(cursor + "[Create New Request]\n")
+			s.WriteString("  " + option + "\n")
 		}
 	}
 
 	s.WriteString("\n")
-	s.WriteString(dimStyle.Render("↑/↓: navigate | enter: select | d: delete | esc: back"))
+	s.WriteString(dimStyle.Render("↑/↓: navigate | enter: select/expand | d: delete | m: move request | r: run folder | e: rename folder | a: folder/collection auth | c: collection settings | esc: back"))
 	s.WriteString("\n")
 
-	if m.message != "" {
+	if m.executing {
+		s.WriteString("\n" + m.viewExecuting())
+	} else if m.editing {
+		s.WriteString("\n" + m.message + "\n")
+		s.WriteString(m.textInput.View())
+	} else if m.message != "" {
 		s.WriteString("\n" + successStyle.Render(m.message))
 	}
 
@@ -129,12 +145,16 @@ func (m Model) viewRequestDetail() string {
 	s.WriteString(titleStyle.Render(req.Name))
 	s.WriteString("\n\n")
 
+	if req.Folder != "" {
+		s.WriteString(fmt.Sprintf("Folder: %s\n", req.Folder))
+	}
 	s.WriteString(fmt.Sprintf("Method: %s\n", req.Method))
 	s.WriteString(fmt.Sprintf("URL: %s\n", req.URL))
 	if req.Path != "" {
 		s.WriteString(fmt.Sprintf("Path: %s\n", req.Path))
 	}
-	s.WriteString(fmt.Sprintf("Full URL: %s\n\n", req.FullURL()))
+	s.WriteString(fmt.Sprintf("Full URL: %s\n", req.FullURL()))
+	s.WriteString(fmt.Sprintf("Auth: %s\n\n", m.authService.DescribeAuth(m.collection, req)))
 
 	if len(req.Headers) > 0 {
 		s.WriteString("Headers:\n")
@@ -152,9 +172,40 @@ func (m Model) viewRequestDetail() string {
 		s.WriteString("\n")
 	}
 
-	if req.Body != "" {
-		s.WriteString("Body:\n")
-		s.WriteString(req.Body + "\n\n")
+	switch req.GetBodyMode() {
+	case models.BodyURLEncoded, models.BodyFormData:
+		s.WriteString(fmt.Sprintf("Body (%s):\n", req.GetBodyMode()))
+		for _, field := range req.FormFields {
+			s.WriteString(fmt.Sprintf("  %s\n", field))
+		}
+		s.WriteString("\n")
+	case models.BodyBinary:
+		s.WriteString(fmt.Sprintf("Body (binary): %s\n\n", req.BodyFile))
+	default:
+		if req.Body != "" {
+			if req.BodyLanguage != "" {
+				s.WriteString(fmt.Sprintf("Body (%s):\n", req.BodyLanguage))
+			} else {
+				s.WriteString("Body:\n")
+			}
+			s.WriteString(req.Body + "\n\n")
+		}
+	}
+
+	if len(req.Assertions) > 0 {
+		s.WriteString("Assertions:\n")
+		for _, a := range req.Assertions {
+			s.WriteString(fmt.Sprintf("  %s\n", a))
+		}
+		s.WriteString("\n")
+	}
+
+	if len(req.Extractions) > 0 {
+		s.WriteString("Extractions:\n")
+		for _, e := range req.Extractions {
+			s.WriteString(fmt.Sprintf("  %s\n", e))
+		}
+		s.WriteString("\n")
 	}
 
 	// Action menu as a selectable list
@@ -164,8 +215,14 @@ func (m Model) viewRequestDetail() string {
 		"Edit Request",
 		"Manage Headers",
 		"Manage Query Params",
+		"Manage Body Fields",
+		"Set Auth",
+		"Client Settings",
+		"Manage Assertions",
+		"Manage Extractions",
 		"Clone Request",
 		"Export to cURL",
+		"Download Response to File",
 	}
 
 	for i, action := range actions {
@@ -179,10 +236,19 @@ func (m Model) viewRequestDetail() string {
 	}
 
 	s.WriteString("\n")
-	s.WriteString(dimStyle.Render("↑/↓: navigate | enter: select | esc: back"))
+	if auth := m.collection.EffectiveAuth(req); auth != nil && auth.Type == models.AuthOAuth2 {
+		s.WriteString(dimStyle.Render("↑/↓: navigate | enter: select | o: fetch OAuth2 token | esc: back"))
+	} else {
+		s.WriteString(dimStyle.Render("↑/↓: navigate | enter: select | esc: back"))
+	}
 	s.WriteString("\n")
 
-	if m.message != "" {
+	if m.executing {
+		s.WriteString("\n" + m.viewExecuting())
+	} else if m.editing {
+		s.WriteString("\n" + m.message + "\n")
+		s.WriteString(m.textInput.View())
+	} else if m.message != "" {
 		s.WriteString("\n" + m.message)
 	}
 
@@ -206,6 +272,9 @@ func (m Model) viewRequestEdit() string {
 		fmt.Sprintf("URL: %s", req.URL),
 		fmt.Sprintf("Path: %s", req.Path),
 		fmt.Sprintf("Body: %s", req.Body),
+		fmt.Sprintf("Body Mode: %s", req.GetBodyMode()),
+		fmt.Sprintf("Body Language: %s", req.BodyLanguage),
+		fmt.Sprintf("Body File: %s", req.BodyFile),
 	}
 
 	for i, field := range fields {
@@ -1,24 +1,598 @@
 package ui
 
 import (
+	"github.com/leobrines/curlman/assertion"
 	"github.com/leobrines/curlman/executor"
+	"github.com/leobrines/curlman/extract"
+	"github.com/leobrines/curlman/jsonpath"
+	"github.com/leobrines/curlman/pretty"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
 	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 )
 
-func (m Model) viewResponse() string {
+// Tabs of the response view
+const (
+	responseTabBody = iota
+	responseTabHeaders
+	responseTabCookies
+	responseTabTiming
+)
+
+var responseTabs = []string{"Body", "Headers", "Cookies", "Timing"}
+
+// hexDumpLimit is the number of bytes of a binary body shown as hex
+const hexDumpLimit = 64 << 10
+
+// responseFooterHeight is the number of lines below the viewport: hints, message and prompt
+const responseFooterHeight = 5
+
+// Highlighting of formatted bodies
+var highlightStyles = map[pretty.Kind]lipgloss.Style{
+	pretty.Key:     lipgloss.NewStyle().Foreground(lipgloss.Color("81")),
+	pretty.String:  lipgloss.NewStyle().Foreground(lipgloss.Color("42")),
+	pretty.Number:  lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+	pretty.Bool:    lipgloss.NewStyle().Foreground(lipgloss.Color("170")),
+	pretty.Null:    lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+	pretty.Punct:   lipgloss.NewStyle().Foreground(lipgloss.Color("250")),
+	pretty.Tag:     lipgloss.NewStyle().Foreground(lipgloss.Color("81")),
+	pretty.Attr:    lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+	pretty.Comment: lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+}
+
+// showResponse opens the response view on a response
+func (m *Model) showResponse(response *executor.Response, assertions []assertion.Result, extractions []extract.Result, returnView view) {
+	if !m.revealSecrets {
+		response = m.maskResponse(response)
+	}
+	m.response = response
+	m.assertionResults = assertions
+	m.extractionResults = extractions
+	m.responseReturnView = returnView
+	m.currentView = viewResponse
+
+	m.responseTab = responseTabBody
+	m.responseRaw = false
+	m.responseCursor = 0
+	m.responseCollapsed = make(map[string]bool)
+	m.responseTree = nil
+	m.responseData = nil
+	m.responseFilter = ""
+	m.responseFiltered = ""
+	m.responseFilterErr = nil
+	if response != nil && response.Error == nil && !response.Truncated && m.responseFormat() == pretty.FormatJSON {
+		m.responseTree, _ = pretty.ParseJSON(response.Body)
+		m.responseData, _ = jsonpath.Decode(response.Body)
+	}
+	m.responseViewport.SetYOffset(0)
+	m.responseViewport.SetXOffset(0)
+	m.refreshResponseViewport()
+}
+
+// maskResponse returns a copy of a response with the values of secret variables masked in its headers and text body
+func (m *Model) maskResponse(response *executor.Response) *executor.Response {
+	if secretVars, _ := m.variableService.SecretVariables(m.collection); response == nil || len(secretVars) == 0 {
+		return response
+	}
+
+	masked := *response
+	if !masked.Binary {
+		masked.Body = m.variableService.Mask(m.collection, response.Body)
+	}
+	masked.Headers = make(http.Header, len(response.Headers))
+	for k, vs := range response.Headers {
+		for _, v := range vs {
+			masked.Headers[k] = append(masked.Headers[k], m.variableService.Mask(m.collection, v))
+		}
+	}
+	if response.Error != nil {
+		masked.Error = errors.New(m.variableService.Mask(m.collection, response.Error.Error()))
+	}
+	return &masked
+}
+
+// responseFormat is the detected format of the response body
+func (m Model) responseFormat() string {
+	if m.response.Binary {
+		return pretty.FormatText
+	}
+	return pretty.Detect(m.response.ContentType, m.response.Body)
+}
+
+// responseBodyLines formats the body as shown: raw, pretty-printed, or as hex for binary bodies
+func (m Model) responseBodyLines() []pretty.Line {
+	if m.response.Error != nil {
+		return pretty.PlainLines(fmt.Sprintf("Error: %s", m.response.Error))
+	}
+	if m.response.Binary {
+		return m.binaryBodyLines()
+	}
+	if m.response.Truncated {
+		note := fmt.Sprintf("… truncated: showing the first %s of %s", executor.FormatSize(int64(len(m.response.Body))), executor.FormatSize(m.response.Size))
+		if m.response.BodyFile == "" {
+			note += " (use Download Response to File to keep all of it)"
+		}
+		return append(pretty.PlainLines(m.response.Body), pretty.Line{Segments: []pretty.Segment{{Text: note, Kind: pretty.Comment}}})
+	}
+	if m.responseRaw {
+		if m.responseFiltered != "" {
+			return pretty.PlainLines(m.responseFiltered)
+		}
+		return pretty.PlainLines(m.response.Body)
+	}
+	if m.responseTree != nil {
+		return pretty.RenderJSON(m.responseTree, m.responseCollapsed)
+	}
+	return pretty.Format(m.responseFormat(), m.response.Body, nil)
+}
+
+// binaryBodyLines describes a binary body and dumps its first bytes as hex
+func (m Model) binaryBodyLines() []pretty.Line {
+	comment := func(text string) pretty.Line {
+		return pretty.Line{Segments: []pretty.Segment{{Text: text, Kind: pretty.Comment}}}
+	}
+
+	lines := []pretty.Line{comment(fmt.Sprintf("Binary body: %s, %s", m.response.ContentType, executor.FormatSize(m.response.Size)))}
+	if m.response.BodyFile != "" {
+		lines = append(lines, comment("Saved to "+m.response.BodyFile))
+	}
+	if m.response.Size > hexDumpLimit {
+		lines = append(lines, comment(fmt.Sprintf("Showing the first %s", executor.FormatSize(hexDumpLimit))))
+	}
+	lines = append(lines, pretty.Line{})
+	return append(lines, pretty.HexLines(m.response.Body, hexDumpLimit)...)
+}
+
+// filterResponse shows the part of the JSON body matching expr, or the whole body for an empty expr
+// An invalid expression keeps the previous result and sets responseFilterErr
+func (m *Model) filterResponse(expr string) {
+	if m.responseData == nil {
+		return
+	}
+
+	m.responseFilterErr = nil
+	if strings.TrimSpace(expr) == "" {
+		m.responseFiltered = ""
+		m.responseMatches = 0
+		m.responseTree, _ = pretty.ParseJSON(m.response.Body)
+	} else {
+		results, err := jsonpath.Evaluate(m.responseData, expr)
+		var filtered string
+		if err == nil {
+			filtered, err = jsonpath.Format(results)
+		}
+		if err != nil {
+			m.responseFilterErr = err
+			m.refreshResponseViewport()
+			return
+		}
+		m.responseFiltered = filtered
+		m.responseMatches = len(results)
+		m.responseTree, _ = pretty.ParseJSON(filtered)
+	}
+
+	m.responseCollapsed = make(map[string]bool)
+	m.responseCursor = 0
+	m.responseViewport.SetYOffset(0)
+	m.refreshResponseViewport()
+}
+
+// responseTabLines renders the lines of the current tab
+func (m Model) responseTabLines() []string {
+	var lines []string
+	keyStyle := highlightStyles[pretty.Key]
+
+	switch m.responseTab {
+	case responseTabBody:
+		for _, line := range m.responseBodyLines() {
+			lines = append(lines, highlight(line))
+		}
+	case responseTabHeaders:
+		keys := make([]string, 0, len(m.response.Headers))
+		for key := range m.response.Headers {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			for _, value := range m.response.Headers[key] {
+				lines = append(lines, keyStyle.Render(key+":")+" "+value)
+			}
+		}
+		if len(lines) == 0 {
+			lines = append(lines, dimStyle.Render("No headers."))
+		}
+	case responseTabCookies:
+		for _, cookie := range (&http.Response{Header: m.response.Headers}).Cookies() {
+			lines = append(lines, keyStyle.Render(cookie.Name)+"="+cookie.Value)
+			var attrs []string
+			if cookie.Domain != "" {
+				attrs = append(attrs, "Domain="+cookie.Domain)
+			}
+			if cookie.Path != "" {
+				attrs = append(attrs, "Path="+cookie.Path)
+			}
+			if !cookie.Expires.IsZero() {
+				attrs = append(attrs, "Expires="+cookie.Expires.Local().Format("2006-01-02 15:04:05"))
+			}
+			if cookie.MaxAge != 0 {
+				attrs = append(attrs, fmt.Sprintf("Max-Age=%d", max(cookie.MaxAge, 0)))
+			}
+			if cookie.Secure {
+				attrs = append(attrs, "Secure")
+			}
+			if cookie.HttpOnly {
+				attrs = append(attrs, "HttpOnly")
+			}
+			if len(attrs) > 0 {
+				lines = append(lines, dimStyle.Render("  "+strings.Join(attrs, "; ")))
+			}
+		}
+		if len(lines) == 0 {
+			lines = append(lines, dimStyle.Render("The response sets no cookies."))
+		}
+	case responseTabTiming:
+		lines = m.timingLines()
+	}
+	return lines
+}
+
+// timingPhase is a row of the timing waterfall
+type timingPhase struct {
+	name     string
+	duration time.Duration
+	color    lipgloss.Color
+}
+
+// timingLines renders the phases of the request as a waterfall, followed by connection details
+func (m Model) timingLines() []string {
+	keyStyle := highlightStyles[pretty.Key]
+	timing := m.response.Timing
+	phases := []timingPhase{
+		{"DNS lookup", timing.DNSLookup, "81"},
+		{"TCP connect", timing.TCPConnect, "214"},
+		{"TLS handshake", timing.TLSHandshake, "170"},
+		{"Waiting (TTFB)", timing.Waiting(), "42"},
+		{"Content transfer", timing.ContentTransfer, "205"},
+	}
+
+	var sum time.Duration
+	for _, phase := range phases {
+		sum += phase.duration
+	}
+	total := max(m.response.Duration, sum)
+	if sum == 0 {
+		return []string{keyStyle.Render("Total:") + " " + m.response.Duration.String(), "", dimStyle.Render("No timing breakdown was recorded.")}
+	}
+
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+	barWidth := max(width-32, 10)
+
+	lines := []string{keyStyle.Render("Total:") + " " + m.response.Duration.String(), ""}
+	var offset time.Duration
+	for _, phase := range phases {
+		start, length := 0, 0
+		if total > 0 {
+			start = int(int64(barWidth) * int64(offset) / int64(total))
+			end := int(int64(barWidth) * int64(offset+phase.duration) / int64(total))
+			length = end - start
+			if phase.duration > 0 && length == 0 {
+				length = 1
+			}
+		}
+		bar := strings.Repeat(" ", min(start, barWidth-length)) + lipgloss.NewStyle().Foreground(phase.color).Render(strings.Repeat("█", length))
+		lines = append(lines, fmt.Sprintf("%-17s %10s  %s", phase.name, executor.FormatDuration(phase.duration), bar))
+		offset += phase.duration
+	}
+
+	lines = append(lines, "", keyStyle.Render("Time to first byte:")+" "+executor.FormatDuration(timing.TimeToFirstByte))
+	if timing.RemoteAddr != "" {
+		lines = append(lines, keyStyle.Render("Remote address:")+" "+timing.RemoteAddr)
+	}
+	if timing.Protocol != "" {
+		lines = append(lines, keyStyle.Render("Protocol:")+" "+timing.Protocol)
+	}
+	if timing.TLSVersion != "" {
+		lines = append(lines, keyStyle.Render("TLS:")+" "+timing.TLSVersion)
+	}
+	if timing.ReusedConnection {
+		lines = append(lines, dimStyle.Render("Reused an open connection, so there was no DNS lookup, connect or handshake"))
+	}
+	return lines
+}
+
+// highlight renders a formatted line with colors
+func highlight(line pretty.Line) string {
+	var s strings.Builder
+	for _, segment := range line.Segments {
+		if style, ok := highlightStyles[segment.Kind]; ok {
+			s.WriteString(style.Render(segment.Text))
+		} else {
+			s.WriteString(segment.Text)
+		}
+	}
+	return s.String()
+}
+
+// refreshResponseViewport lays out the current tab in the viewport and keeps the body cursor visible
+func (m *Model) refreshResponseViewport() {
+	if m.response == nil {
+		return
+	}
+
+	width := m.width
+	if width <= 0 {
+		width = 80
+	}
+	height := m.height - lipgloss.Height(m.viewResponseHeader()) - responseFooterHeight
+	if height < 3 {
+		height = 3
+	}
+	m.responseViewport.Width = width
+	m.responseViewport.Height = height
+
+	lines := m.responseTabLines()
+	body := m.responseTab == responseTabBody
+	if body {
+		m.responseCursor = max(0, min(m.responseCursor, len(lines)-1))
+	}
+
+	// Wrapped lines span several rows; starts maps each line to its first row
+	var rows []string
+	starts := make([]int, len(lines)+1)
+	for i, line := range lines {
+		starts[i] = len(rows)
+		if body {
+			gutter := "  "
+			if i == m.responseCursor {
+				gutter = selectedStyle.Render("▌ ")
+			}
+			line = gutter + line
+		}
+		if m.responseWrap {
+			line = lipgloss.NewStyle().Width(width).Render(line)
+		}
+		rows = append(rows, strings.Split(line, "\n")...)
+	}
+	starts[len(lines)] = len(rows)
+
+	m.responseViewport.SetContent(strings.Join(rows, "\n"))
+
+	if body && len(lines) > 0 {
+		first, last := starts[m.responseCursor], starts[m.responseCursor+1]
+		if first < m.responseViewport.YOffset {
+			m.responseViewport.SetYOffset(first)
+		} else if last > m.responseViewport.YOffset+height {
+			m.responseViewport.SetYOffset(last - height)
+		}
+	}
+}
+
+// handleResponseKey handles the keys of the response view; ok is false for keys it leaves to Update
+func (m Model) handleResponseKey(msg tea.KeyMsg) (model tea.Model, cmd tea.Cmd, ok bool) {
+	if m.response == nil {
+		return m, nil, false
+	}
+	body := m.responseTab == responseTabBody
+
+	switch msg.String() {
+	case "tab":
+		m.responseTab = (m.responseTab + 1) % len(responseTabs)
+		m.responseViewport.SetYOffset(0)
+	case "shift+tab":
+		m.responseTab = (m.responseTab + len(responseTabs) - 1) % len(responseTabs)
+		m.responseViewport.SetYOffset(0)
+	case "left":
+		m.responseViewport.ScrollLeft(4)
+		return m, nil, true
+	case "right":
+		m.responseViewport.ScrollRight(4)
+		return m, nil, true
+	case "1", "2", "3", "4":
+		m.responseTab = int(msg.String()[0] - '1')
+		m.responseViewport.SetYOffset(0)
+	case "up", "k":
+		if body {
+			m.responseCursor--
+		} else {
+			m.responseViewport.LineUp(1)
+			return m, nil, true
+		}
+	case "down", "j":
+		if body {
+			m.responseCursor++
+		} else {
+			m.responseViewport.LineDown(1)
+			return m, nil, true
+		}
+	case "pgup":
+		if body {
+			m.responseCursor -= m.responseViewport.Height
+		} else {
+			m.responseViewport.PageUp()
+			return m, nil, true
+		}
+	case "pgdown":
+		if body {
+			m.responseCursor += m.responseViewport.Height
+		} else {
+			m.responseViewport.PageDown()
+			return m, nil, true
+		}
+	case "home", "g":
+		m.responseCursor = 0
+		m.responseViewport.GotoTop()
+	case "end", "G":
+		m.responseCursor = m.responseViewport.TotalLineCount()
+		m.responseViewport.GotoBottom()
+	case "enter", " ":
+		// Fold or unfold the JSON node on the cursor line
+		if !body || m.responseRaw || m.responseTree == nil {
+			return m, nil, true
+		}
+		lines := m.responseBodyLines()
+		if m.responseCursor < len(lines) && lines[m.responseCursor].Path != "" {
+			path := lines[m.responseCursor].Path
+			m.responseCollapsed[path] = !m.responseCollapsed[path]
+		}
+	case "c":
+		if m.responseTree == nil {
+			return m, nil, true
+		}
+		// Fold everything below the root
+		if paths := pretty.ContainerPaths(m.responseTree); len(paths) > 1 {
+			for _, path := range paths[1:] {
+				m.responseCollapsed[path] = true
+			}
+		}
+		m.responseCursor = 0
+	case "e":
+		m.responseCollapsed = make(map[string]bool)
+	case "p":
+		m.responseRaw = !m.responseRaw
+		m.responseCursor = 0
+		m.responseViewport.SetYOffset(0)
+		if m.responseRaw {
+			m.message = "Showing the raw body"
+		} else {
+			m.message = "Showing the pretty-printed body"
+		}
+	case "w":
+		m.responseWrap = !m.responseWrap
+		m.responseViewport.SetXOffset(0)
+	case "/":
+		if m.responseData == nil {
+			m.message = "Filters apply to JSON bodies only"
+			return m, nil, true
+		}
+		m.responseTab = responseTabBody
+		m.message = "Filter with JSONPath ($.items[*].id) or jq-style (.items[] | .id), empty to clear:"
+		m.textInput.SetValue(m.responseFilter)
+		m.textInput.Focus()
+		m.editing = true
+		m.editingField = editResponseFilter
+	default:
+		return m, nil, false
+	}
+
+	m.refreshResponseViewport()
+	return m, nil, true
+}
+
+// viewResponseHeader renders the status, test results and tabs above the viewport
+func (m Model) viewResponseHeader() string {
 	var s strings.Builder
 
 	s.WriteString(titleStyle.Render("Response"))
 	s.WriteString("\n\n")
 
-	if m.response != nil {
-		s.WriteString(executor.FormatResponse(m.response))
+	if m.response.Error != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Failed after %s", m.response.Duration)) + "\n")
 	} else {
-		s.WriteString("No response yet")
+		status := fmt.Sprintf("%s  %s  %s", m.response.Status, m.response.Duration, executor.FormatSize(m.response.Size))
+		if contentType := m.response.ContentType; contentType != "" {
+			status += "  " + contentType
+		}
+		if m.response.BodyFile != "" {
+			status += "  → " + m.response.BodyFile
+		}
+		if m.response.StatusCode >= 400 {
+			s.WriteString(errorStyle.Render(status) + "\n")
+		} else {
+			s.WriteString(successStyle.Render(status) + "\n")
+		}
 	}
 
+	if len(m.assertionResults) > 0 {
+		passed := 0
+		for _, r := range m.assertionResults {
+			if r.Passed {
+				passed++
+			}
+		}
+		summary := fmt.Sprintf("Tests: %d/%d passed", passed, len(m.assertionResults))
+		if passed == len(m.assertionResults) {
+			s.WriteString(successStyle.Render(summary) + "\n")
+		} else {
+			s.WriteString(errorStyle.Render(summary) + "\n")
+		}
+		for _, r := range m.assertionResults {
+			line := fmt.Sprintf("%s (%s)", r.Assertion, r.Message)
+			if r.Passed {
+				s.WriteString(successStyle.Render("  ✓ "+line) + "\n")
+			} else {
+				s.WriteString(errorStyle.Render("  ✗ "+line) + "\n")
+			}
+		}
+	}
+
+	if len(m.extractionResults) > 0 {
+		s.WriteString("Extracted:\n")
+		for _, r := range m.extractionResults {
+			if r.Error != nil {
+				s.WriteString(errorStyle.Render(fmt.Sprintf("  ✗ %s (%s)", r.Extraction.Variable, r.Error)) + "\n")
+			} else {
+				s.WriteString(successStyle.Render(fmt.Sprintf("  ✓ %s = %s", r.Extraction.Variable, r.Value)) + "\n")
+			}
+		}
+	}
+
+	if m.responseFilterErr != nil {
+		s.WriteString(errorStyle.Render(fmt.Sprintf("Filter: %s", m.responseFilterErr)) + "\n")
+	} else if m.responseFiltered != "" {
+		expr := m.responseFilter
+		if m.editing && m.editingField == editResponseFilter {
+			expr = m.textInput.Value()
+		}
+		matches := fmt.Sprintf("%d matches", m.responseMatches)
+		if m.responseMatches == 1 {
+			matches = "1 match"
+		}
+		s.WriteString(dimStyle.Render(fmt.Sprintf("Filter: %s (%s)", strings.TrimSpace(expr), matches)) + "\n")
+	}
+
+	s.WriteString("\n")
+	for i, tab := range responseTabs {
+		label := fmt.Sprintf(" %d %s ", i+1, tab)
+		if i == m.responseTab {
+			s.WriteString(selectedStyle.Render("["+label+"]"))
+		} else {
+			s.WriteString(dimStyle.Render(" " + label + " "))
+		}
+	}
+	mode := "pretty"
+	if m.responseRaw {
+		mode = "raw"
+	}
+	if m.responseWrap {
+		mode += ", wrap"
+	}
+	s.WriteString(dimStyle.Render("  (" + mode + ")"))
+	s.WriteString("\n")
+
+	return s.String()
+}
+
+func (m Model) viewResponse() string {
+	if m.response == nil {
+		return titleStyle.Render("Response") + "\n\nNo response yet\n\n" + dimStyle.Render("esc: back") + "\n"
+	}
+
+	var s strings.Builder
+
+	s.WriteString(m.viewResponseHeader())
+	s.WriteString(m.responseViewport.View())
 	s.WriteString("\n\n")
-	s.WriteString(dimStyle.Render("s: save body | esc: back"))
+	s.WriteString(dimStyle.Render(fmt.Sprintf("tab: tabs | ↑/↓: scroll | ←/→: pan | enter: fold | c/e: fold/unfold all | p: raw | w: wrap | /: filter | s: save | esc: back  %3.f%%",
+		m.responseViewport.ScrollPercent()*100)))
 	s.WriteString("\n")
 
 	if m.editing {
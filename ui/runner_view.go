@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"github.com/leobrines/curlman/services"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (m Model) viewRunReport() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Collection Run"))
+	s.WriteString("\n\n")
+
+	if m.runReport == nil {
+		s.WriteString("No run yet")
+		return s.String()
+	}
+
+	s.WriteString(fmt.Sprintf("Collection: %s\n\n", m.runReport.CollectionName))
+
+	for _, r := range m.runReport.Results {
+		detail := ""
+		duration := time.Duration(0)
+		switch {
+		case r.Error != nil:
+			detail = r.Error.Error()
+		case r.Response.Error != nil:
+			detail = r.Response.Error.Error()
+			duration = r.Response.Duration
+		default:
+			detail = r.Response.Status
+			duration = r.Response.Duration
+		}
+
+		line := fmt.Sprintf("[%s] %s  %s  (%s)", r.Request.Method, r.Request.Name, detail, duration.Round(time.Millisecond))
+		if r.Passed {
+			s.WriteString(successStyle.Render("PASS  "+line) + "\n")
+		} else {
+			s.WriteString(errorStyle.Render("FAIL  "+line) + "\n")
+		}
+		for _, warning := range r.Warnings {
+			s.WriteString(dimStyle.Render("      ! "+warning) + "\n")
+		}
+	}
+
+	s.WriteString("\n")
+	summary := fmt.Sprintf("%d requests, %d passed, %d failed in %s",
+		len(m.runReport.Results), m.runReport.PassedCount(), m.runReport.FailedCount(), m.runReport.Duration.Round(time.Millisecond))
+	if m.runReport.FailedCount() > 0 {
+		s.WriteString(errorStyle.Render(summary))
+	} else {
+		s.WriteString(successStyle.Render(summary))
+	}
+	if m.runReport.WithoutAssertions() {
+		s.WriteString("\n" + dimStyle.Render("Note: "+services.PassRule))
+	}
+
+	s.WriteString("\n\n")
+	s.WriteString(dimStyle.Render("esc: back"))
+	s.WriteString("\n")
+
+	return s.String()
+}
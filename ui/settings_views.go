@@ -0,0 +1,122 @@
+package ui
+
+import (
+	"github.com/leobrines/curlman/models"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// settingHints describes the accepted values of each client setting
+var settingHints = map[string]string{
+	"timeout":          "duration like 10s or 500ms (default 30s)",
+	"follow_redirects": "true or false (default true)",
+	"max_redirects":    "number of hops (default 10)",
+	"proxy":            "http://host:port (default: HTTP_PROXY/HTTPS_PROXY)",
+	"no_proxy":         "comma separated hosts, domains or CIDRs",
+	"insecure":         "true to skip TLS verification",
+	"ca_cert":          "path of a PEM CA bundle",
+	"client_cert":      "path of a PEM client certificate",
+	"client_key":       "path of the PEM client key",
+	"http_version":     "1.1 or 2 (default: negotiate)",
+	"cookies":          "false to not use the cookie jar (default true)",
+}
+
+// editedSettings returns the settings shown in the settings view: the selected
+// request's, or the collection's
+func (m Model) editedSettings() *models.ClientSettings {
+	if m.settingsForCollection {
+		return m.collection.Settings
+	}
+	return m.collection.Requests[m.selectedRequest].Settings
+}
+
+func (m Model) viewSettings() string {
+	var s strings.Builder
+
+	if m.settingsForCollection {
+		s.WriteString(titleStyle.Render("Collection Client Settings"))
+	} else {
+		s.WriteString(titleStyle.Render("Client Settings: " + m.collection.Requests[m.selectedRequest].Name))
+	}
+	s.WriteString("\n\n")
+
+	settings := m.editedSettings()
+	for i, field := range models.ClientSettingFields {
+		value := settings.Get(field)
+		line := field + ":"
+		if value != "" {
+			line += " " + value
+		} else {
+			// Show where an unset value comes from
+			if inherited := m.collection.Settings.Get(field); !m.settingsForCollection && inherited != "" {
+				line += dimStyle.Render(fmt.Sprintf(" (collection: %s)", inherited))
+			} else {
+				line += dimStyle.Render(" (default)")
+			}
+		}
+
+		if i == m.cursor {
+			s.WriteString(selectedStyle.Render("> "+field+": "+value) + "\n")
+		} else {
+			s.WriteString("  " + line + "\n")
+		}
+	}
+
+	s.WriteString("\n")
+	if m.settingsForCollection {
+		s.WriteString(dimStyle.Render("Requests can override each setting."))
+	} else {
+		s.WriteString(dimStyle.Render("Empty values use the collection settings."))
+	}
+	s.WriteString("\n\n")
+	s.WriteString(dimStyle.Render("↑/↓: navigate | enter: edit | esc: back"))
+	s.WriteString("\n")
+
+	if m.editing {
+		s.WriteString("\n" + m.message + "\n")
+		s.WriteString(m.textInput.View())
+	} else if m.message != "" {
+		s.WriteString("\n" + successStyle.Render(m.message))
+	}
+
+	return s.String()
+}
+
+// openSettings shows the client settings of the selected request, or of the collection
+func (m *Model) openSettings(forCollection bool) {
+	m.settingsForCollection = forCollection
+	m.settingsReturnView = m.currentView
+	m.currentView = viewSettings
+	m.cursor = 0
+	m.message = ""
+}
+
+func (m *Model) startEditingSetting() {
+	field := models.ClientSettingFields[m.cursor]
+	m.editing = true
+	m.textInput.Focus()
+	m.editingField = editSetting
+	m.textInput.SetValue(m.editedSettings().Get(field))
+	m.message = fmt.Sprintf("%s - %s, empty to unset:", field, settingHints[field])
+}
+
+// handleSettingInput applies the value entered for the selected setting
+func (m Model) handleSettingInput(value string) (tea.Model, tea.Cmd) {
+	field := models.ClientSettingFields[m.cursor]
+
+	var err error
+	if m.settingsForCollection {
+		err = m.settingsService.SetCollectionSetting(m.collection, field, value)
+	} else {
+		err = m.settingsService.SetRequestSetting(m.collection.Requests[m.selectedRequest], field, value)
+	}
+
+	if err != nil {
+		m.message = fmt.Sprintf("Error: %s", err)
+	} else {
+		m.message = fmt.Sprintf("%s updated", field)
+	}
+	return m, nil
+}
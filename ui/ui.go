@@ -1,15 +1,28 @@
 package ui
 
 import (
+	"github.com/leobrines/curlman/assertion"
 	"github.com/leobrines/curlman/config"
+	"github.com/leobrines/curlman/cookies"
 	"github.com/leobrines/curlman/environment"
 	"github.com/leobrines/curlman/executor"
+	"github.com/leobrines/curlman/extract"
+	"github.com/leobrines/curlman/history"
 	"github.com/leobrines/curlman/models"
+	"github.com/leobrines/curlman/oauth2"
+	"github.com/leobrines/curlman/pretty"
+	"github.com/leobrines/curlman/secrets"
 	"github.com/leobrines/curlman/services"
+	"context"
 	"fmt"
+	"path/filepath"
 	"sort"
+	"strings"
+	"time"
 
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -30,6 +43,16 @@ const (
 	viewEnvironmentDetail
 	viewEnvironmentVariables
 	viewGlobalVariables
+	viewRunReport
+	viewAssertions
+	viewFormFields
+	viewSettings
+	viewCookies
+	viewExtractions
+	viewCollections
+	viewHistory
+	viewHistoryDiff
+	viewResolvedVariables
 )
 
 type editField int
@@ -42,8 +65,37 @@ const (
 	editHeader
 	editQuery
 	editBody
+	editAssertion
+	editFormField
+	editBodyMode
+	editBodyLanguage
+	editBodyFile
+	editExtraction
+	editCollectionCreate
+	editCollectionRename
+	editCollectionDuplicate
+	editHistoryFilter
+	editCurlImport
+	editPostmanExport
+	editFolderCreate
+	editFolderRename
+	editRequestFolder
+	editRequestAuth
+	editFolderAuth
+	editCollectionAuth
+	editSetting
+	editCookie
+	editResponseFilter
+	editDownload
+	editSecretVariable
+	editUnresolvedChoice
+	editUnresolvedVariable
+	editUnresolvedSave
 )
 
+// authSyntax lists the definitions accepted by the auth prompts
+const authSyntax = "inherit | none | basic user pass | bearer token | apikey header|query name value | digest user pass | oauth2 grant token_url=... client_id=..."
+
 // Message types for async operations
 type collectionsLoadedMsg struct {
 	collections []*models.Collection
@@ -51,11 +103,24 @@ type collectionsLoadedMsg struct {
 	err         error
 }
 
+type oauthTokenMsg struct {
+	token *oauth2.Token
+	err   error
+}
+
 type Model struct {
 	// Data
 	collection            *models.Collection
+	collectionFile        string   // File name of the open collection, empty until saved
 	availableCollections  []string // List of available collection filenames
 	response              *executor.Response
+	assertionResults      []assertion.Result
+	extractionResults     []extract.Result
+	historyEntries        []*history.Entry
+	historyFilter         string
+	historyMarks          []string // IDs of entries marked for diffing
+	historyDiff           string
+	runReport             *services.RunReport
 	environments          []string
 	currentEnv            *environment.Environment
 	currentCollectionEnv  *models.CollectionEnvironment
@@ -66,9 +131,18 @@ type Model struct {
 	requestService     *services.RequestService
 	variableService    *services.VariableService
 	environmentService *services.EnvironmentService
+	runnerService      *services.RunnerService
+	extractionService  *services.ExtractionService
+	historyService     *services.HistoryService
+	folderService      *services.FolderService
+	authService        *services.AuthService
+	settingsService    *services.SettingsService
+	cookieService      *services.CookieService
 
 	// UI State
 	currentView          view
+	responseReturnView   view // view to return to when leaving the response view
+	settingsReturnView   view // view to return to when leaving the settings view
 	selectedRequest      int
 	selectedField        int
 	cursor               int
@@ -87,12 +161,41 @@ type Model struct {
 	envListActionFocus     bool // true when focused on actions menu in environments view
 	variableActionFocus    bool // true when focused on actions menu in variables view
 	variableActionCursor   int  // cursor for variable actions menu
+	collectionActionFocus  bool // true when focused on actions menu in collections view
+	collectionActionCursor int  // cursor for collection actions menu
+	collapsedFolders       map[string]bool // folder paths collapsed in the request list
+	settingsForCollection  bool // true when the settings view edits the collection settings
+	cookieJar              *cookies.Jar     // jar shown in the cookies view
+	cookieList             []*cookies.Cookie // cookies of cookieJar in display order
+	resolvedVariables      []services.ResolvedVariable // effective variables shown by the inspector
+	revealSecrets          bool // show the values of secret variables instead of masking them
+	pendingVariables       *pendingVariables // one-off values prompted for before running or exporting a request
+	spinner                spinner.Model
+	responseViewport       viewport.Model
+	responseTab            int             // tab shown in the response view
+	responseRaw            bool            // show the body as received instead of pretty-printed
+	responseWrap           bool            // wrap long lines instead of panning
+	responseCursor         int             // body line under the cursor
+	responseCollapsed      map[string]bool // paths of the folded JSON nodes
+	responseTree           *pretty.Node    // parsed JSON body, nil for other bodies
+	responseData           interface{}     // decoded JSON body for filters, nil for other bodies
+	responseFilter         string          // JSONPath or jq-style expression applied to the body
+	responseFiltered       string          // JSON matching the filter shown, "" without a filter
+	responseMatches        int             // number of values matching the filter shown
+	responseFilterErr      error           // why the filter being typed is invalid
+	executing              bool               // true while a request, run or replay runs in the background
+	executionID            int                // incremented per execution to drop responses of cancelled ones
+	executionStart         time.Time
+	executionKind          string             // Request, Run or Replay
+	executionLabel         string             // what the running execution does, shown with the spinner
+	cancelExecution        context.CancelFunc
+	downloadProgress       *executor.Progress // bytes received by the running download
 }
 
 func NewModel() Model {
 	ti := textinput.New()
 	ti.Placeholder = "Enter value..."
-	ti.CharLimit = 500
+	ti.CharLimit = 0 // No limit, pasted curl commands and bodies can be long
 
 	// Load global config
 	globalConfig, err := config.Load()
@@ -106,6 +209,10 @@ func NewModel() Model {
 	requestService := services.NewRequestService()
 	variableService := services.NewVariableService(globalConfig)
 	environmentService := services.NewEnvironmentService()
+	extractionService := services.NewExtractionService(variableService, environmentService)
+	historyService := services.NewHistoryService(requestService, variableService, collectionService, environmentService)
+	cookieService := services.NewCookieService()
+	runnerService := services.NewRunnerService(requestService, variableService, extractionService, historyService, cookieService)
 
 	// Create initial collection using service
 	collection := collectionService.CreateEmptyCollection()
@@ -121,10 +228,20 @@ func NewModel() Model {
 		requestService:     requestService,
 		variableService:    variableService,
 		environmentService: environmentService,
+		runnerService:      runnerService,
+		extractionService:  extractionService,
+		historyService:     historyService,
+		folderService:      services.NewFolderService(),
+		authService:        services.NewAuthService(),
+		settingsService:    services.NewSettingsService(),
+		cookieService:      cookieService,
 
 		// UI State
-		currentView: viewMain,
-		textInput:   ti,
+		currentView:      viewMain,
+		textInput:        ti,
+		spinner:          spinner.New(spinner.WithSpinner(spinner.Dot)),
+		responseViewport: viewport.New(0, 0),
+		collapsedFolders: make(map[string]bool),
 	}
 }
 
@@ -167,6 +284,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
+		if m.currentView == viewResponse {
+			m.refreshResponseViewport()
+		}
 		return m, nil
 
 	case collectionsLoadedMsg:
@@ -179,26 +299,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.availableCollections = msg.names
 
 		if len(msg.collections) > 0 {
-			// Load the first collection
-			m.collection = msg.collections[0]
-
-			// Initialize runtime maps if needed
-			if m.collection.EnvironmentVars == nil {
-				m.collection.EnvironmentVars = make(map[string]string)
-			}
-			if m.collection.CollectionEnvVars == nil {
-				m.collection.CollectionEnvVars = make(map[string]string)
-			}
-
-			// Restore active global environment
-			if m.collection.ActiveEnvironment != "" {
-				m.environmentService.ActivateGlobalEnvironment(m.collection, m.collection.ActiveEnvironment)
+			// Open the last used collection, falling back to the first one
+			selected := 0
+			for i, name := range msg.names {
+				if name == m.globalConfig.LastCollection {
+					selected = i
+					break
+				}
 			}
+			m.collection = msg.collections[selected]
+			m.collectionFile = msg.names[selected]
 
-			// Restore active collection environment
-			if m.collection.ActiveCollectionEnv != "" {
-				m.environmentService.ActivateCollectionEnvironment(m.collection, m.collection.ActiveCollectionEnv)
-			}
+			// Restore active global and collection environments
+			m.environmentService.RestoreActiveEnvironments(m.collection)
 
 			m.message = fmt.Sprintf("Loaded collection: %s (%d available)", m.collection.Name, len(msg.collections))
 		} else {
@@ -206,11 +319,56 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case oauthTokenMsg:
+		if msg.err != nil {
+			m.message = fmt.Sprintf("OAuth2 error: %s", msg.err)
+		} else if msg.token.ExpiresAt.IsZero() {
+			m.message = "OAuth2 token acquired (no expiry)"
+		} else {
+			m.message = fmt.Sprintf("OAuth2 token acquired, expires at %s", msg.token.ExpiresAt.Format("15:04:05"))
+		}
+		return m, nil
+
+	case responseMsg:
+		return m.handleResponse(msg)
+
+	case runReportMsg:
+		return m.handleRunReport(msg)
+
+	case replayMsg:
+		return m.handleReplay(msg)
+
+	case spinner.TickMsg:
+		if !m.executing {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+
 	case tea.KeyMsg:
+		// Only cancelling and quitting work while a request runs
+		if m.executing {
+			switch msg.String() {
+			case "esc":
+				return m.cancelRequest()
+			case "ctrl+c":
+				m.cancelExecution()
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+
 		if m.editing {
 			return m.handleEditingInput(msg)
 		}
 
+		if m.currentView == viewResponse {
+			if model, cmd, ok := m.handleResponseKey(msg); ok {
+				return model, cmd
+			}
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
 			if m.currentView == viewMain {
@@ -225,11 +383,115 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.currentView == viewResponse && m.response != nil {
 				m.message = "Enter filename to save response:"
 				m.textInput.SetValue("response.txt")
+				if m.response.Binary {
+					m.textInput.SetValue("response.bin")
+				}
+				if m.responseFilter != "" {
+					m.message = fmt.Sprintf("Enter filename to save the body filtered by %s:", m.responseFilter)
+					m.textInput.SetValue("response.json")
+				}
 				m.textInput.Focus()
 				m.editing = true
 				m.editingField = editBody
 				return m, nil
 			}
+			if m.currentView == viewEnvironmentVariables {
+				m.editing = true
+				m.textInput.Focus()
+				m.editingField = editSecretVariable
+				m.textInput.SetValue("")
+				m.message = "Variable to mark as secret, or to make plain again:"
+				return m, nil
+			}
+
+		case "r":
+			if row, ok := m.selectedRequestRow(); ok && m.currentView == viewRequestList && row.isFolder() {
+				return m.startRun(row.folder)
+			}
+			switch m.currentView {
+			case viewVariables, viewGlobalVariables, viewEnvironmentVariables, viewResolvedVariables:
+				m.revealSecrets = !m.revealSecrets
+				m.message = "Secret values masked"
+				if m.revealSecrets {
+					m.message = "Secret values revealed"
+				}
+				if m.currentView == viewResolvedVariables {
+					m.refreshResolvedVariables()
+				}
+				return m, nil
+			}
+			if m.currentView == viewHistory && m.cursor < len(m.historyEntries) {
+				return m.startReplay(m.historyEntries[m.cursor])
+			}
+
+		case "m":
+			if row, ok := m.selectedRequestRow(); ok && m.currentView == viewRequestList && !row.isFolder() {
+				m.startFolderPrompt(editRequestFolder, "Move to folder (e.g. Users/Admin, empty for top level):", m.collection.Requests[row.request].Folder)
+				return m, nil
+			}
+			if m.currentView == viewHistory && m.cursor < len(m.historyEntries) {
+				return m.toggleHistoryMark(m.historyEntries[m.cursor])
+			}
+
+		case "e":
+			if row, ok := m.selectedRequestRow(); ok && m.currentView == viewRequestList && row.isFolder() {
+				m.startFolderPrompt(editFolderRename, "Rename folder (full path, e.g. Users/Admin):", row.folder)
+				return m, nil
+			}
+
+		case "o":
+			if m.currentView == viewRequestDetail && m.selectedRequest >= 0 {
+				return m.authorizeOAuth2()
+			}
+
+		case "a":
+			if m.currentView == viewRequestList {
+				// Folders carry their own auth; elsewhere the list edits the collection auth
+				if row, ok := m.selectedRequestRow(); ok && row.isFolder() {
+					value := "inherit"
+					if folder := m.collection.GetFolder(row.folder); folder != nil && folder.Auth != nil {
+						value = folder.Auth.String()
+					}
+					m.startFolderPrompt(editFolderAuth, fmt.Sprintf("Auth of folder %s (%s):", row.folder, authSyntax), value)
+				} else {
+					value := "none"
+					if m.collection.Auth != nil {
+						value = m.collection.Auth.String()
+					}
+					m.startFolderPrompt(editCollectionAuth, fmt.Sprintf("Collection auth (%s):", authSyntax), value)
+				}
+				return m, nil
+			}
+
+		case "/":
+			if m.currentView == viewHistory {
+				m.message = "Filter by request name, status code (404), status class (5xx) or 'error':"
+				m.textInput.SetValue(m.historyFilter)
+				m.textInput.Focus()
+				m.editing = true
+				m.editingField = editHistoryFilter
+				return m, nil
+			}
+
+		case "c":
+			if m.currentView == viewRequestList {
+				m.openSettings(true)
+				return m, nil
+			}
+			if m.currentView == viewCookies {
+				return m.clearCookies()
+			}
+			if m.currentView == viewHistory {
+				if err := m.historyService.Clear(); err != nil {
+					m.message = fmt.Sprintf("Error clearing history: %s", err)
+				} else {
+					m.historyEntries = nil
+					m.historyMarks = nil
+					m.cursor = 0
+					m.message = "History cleared"
+				}
+				return m, nil
+			}
 
 		case "tab":
 			// Tab switching disabled - use Enter to access action menu
@@ -281,6 +543,16 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if m.selectedField > 0 {
 					m.selectedField--
 				}
+			case viewCollections:
+				if m.collectionActionFocus {
+					if m.collectionActionCursor > 0 {
+						m.collectionActionCursor--
+					}
+				} else {
+					if m.cursor > 0 {
+						m.cursor--
+					}
+				}
 			case viewVariables:
 				if m.variableActionFocus {
 					if m.variableActionCursor > 0 {
@@ -311,11 +583,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "down", "j":
 			switch m.currentView {
 			case viewMain:
-				if m.mainMenuCursor < 7 { // 8 menu items (0-7)
+				if m.mainMenuCursor < 12 { // 13 menu items (0-12)
 					m.mainMenuCursor++
 				}
 			case viewRequestDetail:
-				if m.detailActionCursor < 5 { // 6 actions (0-5)
+				if m.detailActionCursor < 11 { // 12 actions (0-11)
 					m.detailActionCursor++
 				}
 			case viewEnvironmentDetail:
@@ -344,18 +616,59 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						m.cursor = 0 // Wrap around
 					}
 				}
+			case viewAssertions:
+				req := m.collection.Requests[m.selectedRequest]
+				if m.cursor < len(req.Assertions)-1 {
+					m.cursor++
+				}
+			case viewFormFields:
+				req := m.collection.Requests[m.selectedRequest]
+				if m.cursor < len(req.FormFields)-1 {
+					m.cursor++
+				}
+			case viewExtractions:
+				req := m.collection.Requests[m.selectedRequest]
+				if m.cursor < len(req.Extractions)-1 {
+					m.cursor++
+				}
+			case viewSettings:
+				if m.cursor < len(models.ClientSettingFields)-1 {
+					m.cursor++
+				}
+			case viewCookies:
+				if m.cursor < len(m.cookieList) { // Cookies plus the add option
+					m.cursor++
+				}
+			case viewCollections:
+				if m.collectionActionFocus {
+					if m.collectionActionCursor < 5 { // 6 actions (0-5)
+						m.collectionActionCursor++
+					}
+				} else {
+					if m.cursor < len(m.availableCollections)-1 {
+						m.cursor++
+					}
+				}
+			case viewHistory:
+				if m.cursor < len(m.historyEntries)-1 {
+					m.cursor++
+				}
+			case viewResolvedVariables:
+				if m.cursor < len(m.resolvedVariables)-1 {
+					m.cursor++
+				}
 			case viewRequestList:
-				// Allow selecting up to "Create New" option
-				if m.cursor < len(m.collection.Requests) {
+				// Allow selecting up to "New Folder" option
+				if m.cursor < len(m.requestRows())+2 {
 					m.cursor++
 				}
 			case viewRequestEdit:
-				if m.selectedField < 4 { // 5 fields (0-4)
+				if m.selectedField < 7 { // 8 fields (0-7)
 					m.selectedField++
 				}
 			case viewVariables:
 				if m.variableActionFocus {
-					if m.variableActionCursor < 2 { // 3 actions (0-2)
+					if m.variableActionCursor < 3 { // 4 actions (0-3)
 						m.variableActionCursor++
 					}
 				} else {
@@ -366,7 +679,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			case viewGlobalVariables:
 				if m.variableActionFocus {
-					if m.variableActionCursor < 2 { // 3 actions (0-2)
+					if m.variableActionCursor < 3 { // 4 actions (0-3)
 						m.variableActionCursor++
 					}
 				} else {
@@ -382,15 +695,54 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 
 		case "d":
-			if m.currentView == viewRequestList && m.cursor < len(m.collection.Requests) && len(m.collection.Requests) > 0 {
-				err := m.requestService.DeleteRequest(m.collection, m.cursor)
-				if err != nil {
-					m.message = fmt.Sprintf("Error deleting request: %s", err)
-				} else {
-					if m.cursor >= len(m.collection.Requests) && m.cursor > 0 {
-						m.cursor--
+			if row, ok := m.selectedRequestRow(); ok && m.currentView == viewRequestList {
+				return m.deleteRequestRow(row)
+			}
+			if m.currentView == viewCookies {
+				return m.deleteCookie()
+			}
+			if m.currentView == viewFormFields && m.selectedRequest >= 0 {
+				req := m.collection.Requests[m.selectedRequest]
+				if m.cursor < len(req.FormFields) {
+					err := m.requestService.DeleteFormField(req, m.cursor)
+					if err != nil {
+						m.message = fmt.Sprintf("Error deleting field: %s", err)
+					} else {
+						if m.cursor >= len(req.FormFields) && m.cursor > 0 {
+							m.cursor--
+						}
+						m.message = "Field deleted"
+					}
+				}
+				return m, nil
+			}
+			if m.currentView == viewAssertions && m.selectedRequest >= 0 {
+				req := m.collection.Requests[m.selectedRequest]
+				if m.cursor < len(req.Assertions) {
+					err := m.requestService.DeleteAssertion(req, m.cursor)
+					if err != nil {
+						m.message = fmt.Sprintf("Error deleting assertion: %s", err)
+					} else {
+						if m.cursor >= len(req.Assertions) && m.cursor > 0 {
+							m.cursor--
+						}
+						m.message = "Assertion deleted"
+					}
+				}
+				return m, nil
+			}
+			if m.currentView == viewExtractions && m.selectedRequest >= 0 {
+				req := m.collection.Requests[m.selectedRequest]
+				if m.cursor < len(req.Extractions) {
+					err := m.extractionService.DeleteExtraction(req, m.cursor)
+					if err != nil {
+						m.message = fmt.Sprintf("Error deleting extraction: %s", err)
+					} else {
+						if m.cursor >= len(req.Extractions) && m.cursor > 0 {
+							m.cursor--
+						}
+						m.message = "Extraction deleted"
 					}
-					m.message = "Request deleted"
 				}
 				return m, nil
 			}
@@ -401,14 +753,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.detailActionCursor = 0
 				return m, nil
 			}
-			if m.currentView == viewRequestList || m.currentView == viewEnvironments {
+			if m.currentView == viewRequestList || m.currentView == viewEnvironments || m.currentView == viewRunReport {
 				m.currentView = viewMain
 				m.cursor = 0
 				m.envListActionFocus = false
 				m.envListActionCursor = 0
 				return m, nil
 			}
-			if m.currentView == viewVariables || m.currentView == viewGlobalVariables {
+			if m.currentView == viewCollections {
+				m.currentView = viewMain
+				m.cursor = 0
+				m.collectionActionFocus = false
+				m.collectionActionCursor = 0
+				return m, nil
+			}
+			if m.currentView == viewVariables || m.currentView == viewGlobalVariables || m.currentView == viewResolvedVariables {
 				m.currentView = viewMain
 				m.cursor = 0
 				m.variableActionFocus = false
@@ -421,11 +780,33 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 			if m.currentView == viewResponse {
-				m.currentView = viewRequestDetail
+				m.currentView = m.responseReturnView
 				m.detailActionCursor = 0
 				return m, nil
 			}
-			if m.currentView == viewHeaders || m.currentView == viewQueryParams {
+			if m.currentView == viewSettings {
+				m.currentView = m.settingsReturnView
+				m.cursor = 0
+				m.message = ""
+				return m, nil
+			}
+			if m.currentView == viewHistory {
+				m.currentView = viewMain
+				m.cursor = 0
+				m.historyMarks = nil
+				return m, nil
+			}
+			if m.currentView == viewCookies {
+				m.currentView = viewMain
+				m.cursor = 0
+				m.message = ""
+				return m, nil
+			}
+			if m.currentView == viewHistoryDiff {
+				m.currentView = viewHistory
+				return m, nil
+			}
+			if m.currentView == viewHeaders || m.currentView == viewQueryParams || m.currentView == viewFormFields || m.currentView == viewAssertions || m.currentView == viewExtractions {
 				m.currentView = viewRequestDetail
 				m.detailActionCursor = 0
 				return m, nil
@@ -448,26 +829,51 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 	case viewMain:
 		// Handle main menu selection
 		switch m.mainMenuCursor {
-		case 0: // Import OpenAPI YAML
-			m.message = "Enter OpenAPI file path:"
+		case 0: // Import Collection File
+			m.message = "Enter file path (OpenAPI, Postman, Insomnia or HAR):"
 			m.textInput.SetValue("")
 			m.textInput.Focus()
 			m.editing = true
 			m.editingField = editName
-		case 1: // View Requests
+		case 1: // Manage Collections
+			m.refreshCollections()
+			m.currentView = viewCollections
+			m.cursor = 0
+			for i, name := range m.availableCollections {
+				if name == m.collectionFile {
+					m.cursor = i
+				}
+			}
+			m.collectionActionFocus = false
+			m.collectionActionCursor = 0
+		case 2: // View Requests
 			m.currentView = viewRequestList
 			m.cursor = 0
-		case 2: // Manage Variables
+		case 3: // Run Collection
+			if len(m.collection.Requests) == 0 {
+				m.message = "No requests to run"
+				return m, nil
+			}
+			return m.startRun("")
+		case 4: // Request History
+			m.historyFilter = ""
+			m.historyMarks = nil
+			m.loadHistory()
+			m.currentView = viewHistory
+			m.cursor = 0
+		case 5: // Manage Variables
 			m.currentView = viewVariables
 			m.cursor = 0
 			m.variableActionFocus = false
 			m.variableActionCursor = 0
-		case 3: // Manage Global Variables
+		case 6: // Manage Global Variables
 			m.currentView = viewGlobalVariables
 			m.cursor = 0
 			m.variableActionFocus = false
 			m.variableActionCursor = 0
-		case 4: // Manage Environments
+		case 7: // Inspect Variables
+			m.openResolvedVariables()
+		case 8: // Manage Environments
 			m.viewingCollectionEnv = false
 			envs, err := m.environmentService.ListGlobalEnvironments()
 			if err != nil {
@@ -479,23 +885,34 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 			m.cursor = 0
 			m.envListActionFocus = false
 			m.envListActionCursor = 0
-		case 5: // Save Collection
+		case 9: // Manage Cookies
+			return m.openCookies()
+		case 10: // Save Collection
 			m.message = "Enter filename to save:"
-			m.textInput.SetValue("collection.json")
+			if m.collectionFile != "" {
+				m.textInput.SetValue(m.collectionFile)
+			} else {
+				m.textInput.SetValue(m.collectionService.CollectionFileName(m.collection.Name))
+			}
 			m.textInput.Focus()
 			m.editing = true
 			m.editingField = editPath
-		case 6: // Help
+		case 11: // Help
 			m.currentView = viewHelp
-		case 7: // Quit
+		case 12: // Quit
 			return m, tea.Quit
 		}
 	case viewRequestList:
-		if m.cursor < len(m.collection.Requests) {
-			m.selectedRequest = m.cursor
+		rows := m.requestRows()
+		if m.cursor < len(rows) && rows[m.cursor].isFolder() {
+			// Expand or collapse the folder
+			folder := rows[m.cursor].folder
+			m.collapsedFolders[folder] = !m.collapsedFolders[folder]
+		} else if m.cursor < len(rows) {
+			m.selectedRequest = rows[m.cursor].request
 			m.currentView = viewRequestDetail
 			m.detailActionCursor = 0
-		} else if m.cursor == len(m.collection.Requests) {
+		} else if m.cursor == len(rows) {
 			// Create new request
 			newReq := m.requestService.CreateRequest()
 			err := m.requestService.AddRequest(m.collection, newReq)
@@ -506,20 +923,22 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 				m.currentView = viewRequestEdit
 				m.message = "New request created"
 			}
+		} else if m.cursor == len(rows)+1 {
+			// Import request from a curl command
+			m.message = "Paste curl command:"
+			m.textInput.SetValue("")
+			m.textInput.Focus()
+			m.editing = true
+			m.editingField = editCurlImport
+		} else if m.cursor == len(rows)+2 {
+			m.startFolderPrompt(editFolderCreate, "Enter folder name (use / to nest, e.g. Users/Admin):", "")
 		}
 	case viewRequestDetail:
 		if m.selectedRequest >= 0 {
 			req := m.collection.Requests[m.selectedRequest]
 			switch m.detailActionCursor {
 			case 0: // Execute Request
-				allVars := m.variableService.GetAllVariables(m.collection)
-				response, err := m.requestService.ExecuteRequest(req, allVars)
-				if err != nil {
-					m.message = fmt.Sprintf("Error executing request: %s", err)
-				} else {
-					m.response = response
-					m.currentView = viewResponse
-				}
+				return m.executeRequest()
 			case 1: // Edit Request
 				m.currentView = viewRequestEdit
 				m.selectedField = 0
@@ -529,7 +948,24 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 			case 3: // Manage Query Params
 				m.currentView = viewQueryParams
 				m.cursor = 0
-			case 4: // Clone Request
+			case 4: // Manage Body Fields
+				m.currentView = viewFormFields
+				m.cursor = 0
+			case 5: // Set Auth
+				m.editing = true
+				m.textInput.Focus()
+				m.editingField = editRequestAuth
+				m.textInput.SetValue(req.Auth.String())
+				m.message = fmt.Sprintf("Auth (%s):", authSyntax)
+			case 6: // Client Settings
+				m.openSettings(false)
+			case 7: // Manage Assertions
+				m.currentView = viewAssertions
+				m.cursor = 0
+			case 8: // Manage Extractions
+				m.currentView = viewExtractions
+				m.cursor = 0
+			case 9: // Clone Request
 				cloned, err := m.requestService.CloneRequest(m.collection, m.selectedRequest)
 				if err != nil {
 					m.message = fmt.Sprintf("Error cloning request: %s", err)
@@ -537,14 +973,14 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 					m.collection.Requests = append(m.collection.Requests, cloned)
 					m.message = "Request cloned successfully!"
 				}
-			case 5: // Export to cURL
-				allVars := m.variableService.GetAllVariables(m.collection)
-				curlCmd, err := m.requestService.ExportToCurl(req, allVars)
-				if err != nil {
-					m.message = fmt.Sprintf("Error exporting: %s", err)
-				} else {
-					m.message = "Curl command:\n" + curlCmd
-				}
+			case 10: // Export to cURL
+				return m.exportToCurl(nil, false)
+			case 11: // Download Response to File
+				m.editing = true
+				m.textInput.Focus()
+				m.editingField = editDownload
+				m.textInput.SetValue(downloadFileName(req))
+				m.message = "Stream the response body to file:"
 			}
 		}
 	case viewRequestEdit:
@@ -579,6 +1015,20 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 				} else {
 					m.message = "No variables to delete"
 				}
+			case 3: // Toggle Secret
+				varKeys := getSortedVariableKeys(m.collection.Variables)
+				if m.cursor >= 0 && m.cursor < len(varKeys) {
+					key := varKeys[m.cursor]
+					value := m.collection.Variables[key]
+					secret := value != secrets.Marker
+					if err := m.variableService.SetCollectionVariableSecret(m.collection, key, secret); err != nil {
+						m.message = fmt.Sprintf("Error: %s", err)
+					} else {
+						m.message = secretToggledMessage(key, value, secret)
+					}
+				} else {
+					m.message = "No variables to mark as secret"
+				}
 			}
 		} else {
 			// If focused on variables list, switch to action menu
@@ -589,6 +1039,16 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 		m.startEditingHeader()
 	case viewQueryParams:
 		m.startEditingQueryParam()
+	case viewFormFields:
+		m.startEditingFormField()
+	case viewAssertions:
+		m.startEditingAssertion()
+	case viewExtractions:
+		m.startEditingExtraction()
+	case viewSettings:
+		m.startEditingSetting()
+	case viewCookies:
+		m.startEditingCookie()
 	case viewEnvironments:
 		if m.envListActionFocus {
 			// Handle environment list actions menu
@@ -795,6 +1255,17 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 		}
 	case viewEnvironmentVariables:
 		m.startEditingEnvironmentVariable()
+	case viewHistory:
+		if m.cursor < len(m.historyEntries) {
+			m.showResponse(m.historyEntries[m.cursor].Response(), nil, nil, viewHistory)
+		}
+	case viewCollections:
+		if m.collectionActionFocus {
+			return m.handleCollectionAction()
+		}
+		// If focused on collections list, switch to action menu
+		m.collectionActionFocus = true
+		m.collectionActionCursor = 0
 	case viewGlobalVariables:
 		if m.variableActionFocus {
 			// Handle action menu selection
@@ -825,6 +1296,20 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 				} else {
 					m.message = "No global variables to delete"
 				}
+			case 3: // Toggle Secret
+				varKeys := getSortedVariableKeys(m.globalConfig.Variables)
+				if m.cursor >= 0 && m.cursor < len(varKeys) {
+					key := varKeys[m.cursor]
+					value := m.globalConfig.Variables[key]
+					secret := value != secrets.Marker
+					if err := m.variableService.SetGlobalVariableSecret(key, secret); err != nil {
+						m.message = fmt.Sprintf("Error: %s", err)
+					} else {
+						m.message = secretToggledMessage(key, value, secret)
+					}
+				} else {
+					m.message = "No global variables to mark as secret"
+				}
 			}
 		} else {
 			// If focused on variables list, switch to action menu
@@ -860,6 +1345,18 @@ func (m *Model) startEditing() {
 	case 4: // Body
 		m.editingField = editBody
 		m.textInput.SetValue(req.Body)
+	case 5: // Body Mode
+		m.editingField = editBodyMode
+		m.textInput.SetValue(req.GetBodyMode())
+		m.message = "Body mode: raw, urlencoded, formdata or binary"
+	case 6: // Body Language
+		m.editingField = editBodyLanguage
+		m.textInput.SetValue(req.BodyLanguage)
+		m.message = "Raw body language: json, xml, html, text, javascript (empty for none)"
+	case 7: // Body File
+		m.editingField = editBodyFile
+		m.textInput.SetValue(req.BodyFile)
+		m.message = "Path of the file sent in binary mode"
 	}
 }
 
@@ -876,6 +1373,10 @@ func (m *Model) startEditingVariable() {
 		m.editingField = editHeader
 		m.textInput.SetValue(value)
 		m.message = fmt.Sprintf("Editing variable '%s' (press enter to save):", key)
+		if value == secrets.Marker {
+			m.textInput.SetValue("")
+			m.message = fmt.Sprintf("Enter the new value of secret variable '%s':", key)
+		}
 	} else {
 		// Otherwise, create a new variable
 		m.startEditingNewVariable()
@@ -907,6 +1408,30 @@ func (m *Model) startEditingQueryParam() {
 	m.message = "Enter query parameter name:"
 }
 
+func (m *Model) startEditingFormField() {
+	m.editing = true
+	m.textInput.Focus()
+	m.editingField = editFormField
+	m.textInput.SetValue("")
+	m.message = "Enter field as key=value, or key=@path/to/file to upload a file:"
+}
+
+func (m *Model) startEditingAssertion() {
+	m.editing = true
+	m.textInput.Focus()
+	m.editingField = editAssertion
+	m.textInput.SetValue("")
+	m.message = "Enter assertion (e.g. status 200, header Content-Type contains json, json $.id exists, duration max 500ms):"
+}
+
+func (m *Model) startEditingExtraction() {
+	m.editing = true
+	m.textInput.Focus()
+	m.editingField = editExtraction
+	m.textInput.SetValue("")
+	m.message = "Enter extraction (e.g. json $.token as token, header Location as url in environment, regex id=(\\d+) as id):"
+}
+
 func (m Model) handleEditingInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 
@@ -918,20 +1443,18 @@ func (m Model) handleEditingInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 		// Handle different editing contexts
 		if m.currentView == viewMain {
-			if m.editingField == editName { // Import OpenAPI
-				collection, savedPath, err := m.collectionService.ImportFromOpenAPI(value)
-				if err != nil {
-					m.message = fmt.Sprintf("Error importing: %s", err)
-				} else {
-					m.collection = collection
-					m.message = fmt.Sprintf("Imported %d requests from %s (saved to %s)", len(collection.Requests), collection.Name, savedPath)
-				}
+			if m.editingField == editName { // Import collection file
+				return m.importFile(value)
 			} else if m.editingField == editPath { // Save collection
 				fullPath, err := m.collectionService.SaveCollection(m.collection, value)
 				if err != nil {
 					m.message = fmt.Sprintf("Error saving: %s", err)
 				} else {
+					m.collectionFile = filepath.Base(fullPath)
 					m.message = fmt.Sprintf("Collection saved to %s", fullPath)
+					if err := m.globalConfig.SetLastCollection(m.collectionFile); err != nil {
+						m.message = fmt.Sprintf("Error remembering last collection: %s", err)
+					}
 				}
 			}
 		} else if m.currentView == viewRequestEdit && m.selectedRequest >= 0 {
@@ -948,6 +1471,12 @@ func (m Model) handleEditingInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				err = m.requestService.UpdateRequestField(req, "path", value)
 			case editBody:
 				err = m.requestService.UpdateRequestField(req, "body", value)
+			case editBodyMode:
+				err = m.requestService.UpdateRequestField(req, "body_mode", value)
+			case editBodyLanguage:
+				err = m.requestService.UpdateRequestField(req, "body_language", value)
+			case editBodyFile:
+				err = m.requestService.UpdateRequestField(req, "body_file", value)
 			}
 			if err != nil {
 				m.message = fmt.Sprintf("Error: %s", err)
@@ -1007,9 +1536,84 @@ func (m Model) handleEditingInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				}
 				m.editingKey = ""
 			}
+		} else if m.currentView == viewRequestDetail && m.editingField == editRequestAuth && m.selectedRequest >= 0 {
+			req := m.collection.Requests[m.selectedRequest]
+			if err := m.authService.SetRequestAuth(req, value); err != nil {
+				m.message = fmt.Sprintf("Error: %s", err)
+			} else {
+				m.message = "Auth: " + m.authService.DescribeAuth(m.collection, req)
+			}
+		} else if m.currentView == viewRequestDetail && m.pendingVariables != nil {
+			return m.handleUnresolvedInput(value)
+		} else if m.currentView == viewRequestDetail && m.editingField == editDownload && m.selectedRequest >= 0 {
+			return m.downloadRequest(value)
+		} else if m.currentView == viewSettings {
+			return m.handleSettingInput(value)
+		} else if m.currentView == viewCookies {
+			return m.handleCookieInput(value)
+		} else if m.currentView == viewRequestList && m.editingField == editCurlImport {
+			request, err := m.requestService.ImportFromCurl(m.collection, value)
+			if err != nil {
+				m.message = fmt.Sprintf("Error: %s", err)
+			} else {
+				m.selectedRequest = len(m.collection.Requests) - 1
+				m.currentView = viewRequestDetail
+				m.detailActionCursor = 0
+				m.message = fmt.Sprintf("Imported request '%s' from curl", request.Name)
+			}
+		} else if m.currentView == viewRequestList {
+			return m.handleFolderInput(value)
+		} else if m.currentView == viewCollections {
+			return m.handleCollectionInput(value)
+		} else if m.currentView == viewHistory && m.editingField == editHistoryFilter {
+			m.historyFilter = value
+			m.historyMarks = nil
+			m.loadHistory()
+			m.cursor = 0
+			m.message = ""
+		} else if m.currentView == viewFormFields && m.selectedRequest >= 0 {
+			req := m.collection.Requests[m.selectedRequest]
+			added, err := m.requestService.AddFormField(req, value)
+			if err != nil {
+				m.message = fmt.Sprintf("Error: %s", err)
+			} else {
+				m.cursor = len(req.FormFields) - 1
+				m.message = fmt.Sprintf("Field '%s' added", added.Key)
+			}
+		} else if m.currentView == viewAssertions && m.selectedRequest >= 0 {
+			req := m.collection.Requests[m.selectedRequest]
+			added, err := m.requestService.AddAssertion(req, value)
+			if err != nil {
+				m.message = fmt.Sprintf("Error: %s", err)
+			} else {
+				m.cursor = len(req.Assertions) - 1
+				m.message = fmt.Sprintf("Assertion '%s' added", added)
+			}
+		} else if m.currentView == viewExtractions && m.selectedRequest >= 0 {
+			req := m.collection.Requests[m.selectedRequest]
+			added, err := m.extractionService.AddExtraction(req, value)
+			if err != nil {
+				m.message = fmt.Sprintf("Error: %s", err)
+			} else {
+				m.cursor = len(req.Extractions) - 1
+				m.message = fmt.Sprintf("Extraction '%s' added", added)
+			}
+		} else if m.currentView == viewResponse && m.editingField == editResponseFilter {
+			previous := m.responseFilter
+			m.responseFilter = strings.TrimSpace(value)
+			m.filterResponse(m.responseFilter)
+			if m.responseFilterErr != nil {
+				m.message = fmt.Sprintf("Invalid filter: %s", m.responseFilterErr)
+				m.responseFilter = previous
+				m.filterResponse(previous)
+			} else if m.responseFilter == "" {
+				m.message = "Filter cleared"
+			} else {
+				m.message = fmt.Sprintf("Filtered by %s", m.responseFilter)
+			}
 		} else if m.currentView == viewResponse && m.response != nil {
 			// Save response body to file
-			err := executor.SaveResponseBody(m.response, value)
+			err := executor.SaveResponseBody(m.response, value, m.responseFilter)
 			if err != nil {
 				m.message = fmt.Sprintf("Error saving response: %s", err)
 			} else {
@@ -1047,6 +1651,8 @@ func (m Model) handleEditingInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.currentView = viewEnvironmentDetail
 				m.message = fmt.Sprintf("Environment '%s' created", value)
 			}
+		} else if m.currentView == viewEnvironmentVariables && m.editingField == editSecretVariable {
+			return m.toggleEnvironmentSecret(value)
 		} else if m.currentView == viewEnvironmentVariables {
 			if m.editingKey == "" {
 				m.editingKey = value
@@ -1128,10 +1734,19 @@ func (m Model) handleEditingInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.editing = false
 		m.editingKey = ""
 		m.message = ""
+		m.pendingVariables = nil
+		if m.currentView == viewResponse && m.editingField == editResponseFilter {
+			// Back to the filter applied before editing
+			m.filterResponse(m.responseFilter)
+		}
 		return m, nil
 	}
 
 	m.textInput, cmd = m.textInput.Update(msg)
+	if m.currentView == viewResponse && m.editingField == editResponseFilter {
+		// Live preview of the expression being typed
+		m.filterResponse(m.textInput.Value())
+	}
 	return m, cmd
 }
 
@@ -1167,6 +1782,26 @@ func (m Model) View() string {
 		return m.viewEnvironmentVariables()
 	case viewGlobalVariables:
 		return m.viewGlobalVariables()
+	case viewRunReport:
+		return m.viewRunReport()
+	case viewFormFields:
+		return m.viewFormFields()
+	case viewAssertions:
+		return m.viewAssertions()
+	case viewExtractions:
+		return m.viewExtractions()
+	case viewSettings:
+		return m.viewSettings()
+	case viewCookies:
+		return m.viewCookies()
+	case viewCollections:
+		return m.viewCollections()
+	case viewHistory:
+		return m.viewHistory()
+	case viewResolvedVariables:
+		return m.viewResolvedVariables()
+	case viewHistoryDiff:
+		return m.viewHistoryDiff()
 	}
 
 	return ""
@@ -1227,6 +1862,10 @@ func (m *Model) startEditingGlobalVariable() {
 		m.editingField = editHeader
 		m.textInput.SetValue(value)
 		m.message = fmt.Sprintf("Editing global variable '%s' (press enter to save):", key)
+		if value == secrets.Marker {
+			m.textInput.SetValue("")
+			m.message = fmt.Sprintf("Enter the new value of secret global variable '%s':", key)
+		}
 	} else {
 		// Otherwise, create a new variable
 		m.startEditingNewGlobalVariable()
@@ -0,0 +1,142 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// pendingVariables holds the values prompted for the undefined variables of a request about to run or be exported
+type pendingVariables struct {
+	names  []string          // Undefined variables, in prompt order
+	values map[string]string // Values entered so far
+	file   string            // File the pending execution downloads to, "" for a plain execution
+	export bool              // Export the request to curl instead of executing it
+}
+
+// action names what going ahead with undefined variables does
+func (p *pendingVariables) action() string {
+	if p.export {
+		return "export"
+	}
+	return "send"
+}
+
+// promptUnresolved warns about the undefined variables of the selected request before running or exporting it,
+// offering to enter one-off values for them or to go ahead with their {{placeholders}} as written
+func (m Model) promptUnresolved(names []string, file string, export bool) (tea.Model, tea.Cmd) {
+	m.pendingVariables = &pendingVariables{
+		names:  names,
+		values: make(map[string]string),
+		file:   file,
+		export: export,
+	}
+	m.promptUnresolvedChoice()
+	return m, nil
+}
+
+// promptUnresolvedChoice asks whether to enter values for the undefined variables or to go ahead without them
+func (m *Model) promptUnresolvedChoice() {
+	action := m.pendingVariables.action()
+	m.editing = true
+	m.textInput.Focus()
+	m.editingField = editUnresolvedChoice
+	m.textInput.SetValue("")
+	m.message = fmt.Sprintf("Warning: undefined variables %s. Enter values for them (v, default) or %s anyway with the {{placeholders}} as written (%s)? esc to cancel:",
+		strings.Join(m.pendingVariables.names, ", "), action, action[:1])
+}
+
+// promptNextVariable asks for the value of the next undefined variable
+func (m *Model) promptNextVariable() {
+	pending := m.pendingVariables
+	name := pending.names[len(pending.values)]
+	m.editing = true
+	m.textInput.Focus()
+	m.editingField = editUnresolvedVariable
+	m.textInput.SetValue("")
+	m.message = fmt.Sprintf("Undefined variable '%s' (%d of %d), enter a value for this request (esc to cancel):",
+		name, len(pending.values)+1, len(pending.names))
+}
+
+// promptSaveVariables asks which layer to keep the entered values in
+func (m *Model) promptSaveVariables() {
+	m.editing = true
+	m.textInput.Focus()
+	m.editingField = editUnresolvedSave
+	m.textInput.SetValue("")
+	m.message = fmt.Sprintf("Save %s to collection, global or environment (the active one)? Leave empty to use them once:",
+		strings.Join(m.pendingVariables.names, ", "))
+}
+
+// handleUnresolvedInput stores an entered value, then saves the values when asked and resumes the request
+func (m Model) handleUnresolvedInput(value string) (tea.Model, tea.Cmd) {
+	pending := m.pendingVariables
+
+	if m.editingField == editUnresolvedChoice {
+		switch strings.ToLower(strings.TrimSpace(value)) {
+		case "", "v", "values":
+			m.promptNextVariable()
+		case pending.action(), pending.action()[:1]:
+			m.pendingVariables = nil
+			if pending.export {
+				return m.exportToCurl(nil, true)
+			}
+			return m.startExecution(pending.file, nil, true)
+		default:
+			m.promptUnresolvedChoice()
+			m.message = fmt.Sprintf("Error: unknown choice '%s'\n%s", value, m.message)
+		}
+		return m, nil
+	}
+
+	if m.editingField == editUnresolvedVariable {
+		pending.values[pending.names[len(pending.values)]] = value
+		if len(pending.values) < len(pending.names) {
+			m.promptNextVariable()
+		} else {
+			m.promptSaveVariables()
+		}
+		return m, nil
+	}
+
+	if err := m.saveVariables(strings.ToLower(strings.TrimSpace(value)), pending.values); err != nil {
+		m.promptSaveVariables()
+		m.message = fmt.Sprintf("Error: %s\n%s", err, m.message)
+		return m, nil
+	}
+
+	m.pendingVariables = nil
+	if pending.export {
+		return m.exportToCurl(pending.values, false)
+	}
+	return m.startExecution(pending.file, pending.values, false)
+}
+
+// saveVariables sets the values in a variable layer; an empty layer keeps them for one request only
+func (m *Model) saveVariables(layer string, values map[string]string) error {
+	var set func(key, value string) error
+	switch layer {
+	case "":
+		return nil
+	case "collection", "c":
+		set = func(key, value string) error {
+			return m.variableService.SetCollectionVariable(m.collection, key, value)
+		}
+	case "global", "g":
+		set = m.variableService.SetGlobalVariable
+	case "environment", "env", "e":
+		set = func(key, value string) error {
+			return m.environmentService.SetActiveEnvironmentVariable(m.collection, key, value)
+		}
+	default:
+		return fmt.Errorf("unknown layer '%s'", layer)
+	}
+
+	for _, key := range getSortedVariableKeys(values) {
+		if err := set(key, values[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
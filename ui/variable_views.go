@@ -1,8 +1,11 @@
 package ui
 
 import (
+	"github.com/leobrines/curlman/secrets"
 	"fmt"
 	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
 )
 
 func (m Model) viewVariables() string {
@@ -17,7 +20,7 @@ func (m Model) viewVariables() string {
 	} else {
 		varKeys := getSortedVariableKeys(m.collection.Variables)
 		for i, key := range varKeys {
-			value := m.collection.Variables[key]
+			value := m.variableValue(m.collection.Variables, secrets.CollectionScope(m.collection.File), key)
 			line := fmt.Sprintf("%s = %s", key, value)
 
 			if i == m.cursor && !m.variableActionFocus {
@@ -37,6 +40,7 @@ func (m Model) viewVariables() string {
 		"Add New Variable",
 		"Edit Selected",
 		"Delete Selected",
+		"Toggle Secret",
 	}
 
 	for i, action := range actions {
@@ -48,7 +52,7 @@ func (m Model) viewVariables() string {
 	}
 
 	s.WriteString("\n")
-	s.WriteString(dimStyle.Render("↑/↓: navigate | tab: switch section | enter: select | esc: back"))
+	s.WriteString(dimStyle.Render("↑/↓: navigate | tab: switch section | enter: select | r: reveal/mask secrets | esc: back"))
 	s.WriteString("\n")
 
 	if m.editing {
@@ -77,7 +81,7 @@ func (m Model) viewGlobalVariables() string {
 	} else {
 		varKeys := getSortedVariableKeys(m.globalConfig.Variables)
 		for i, key := range varKeys {
-			value := m.globalConfig.Variables[key]
+			value := m.variableValue(m.globalConfig.Variables, secrets.GlobalScope, key)
 			line := fmt.Sprintf("%s = %s", key, value)
 
 			if i == m.cursor && !m.variableActionFocus {
@@ -97,6 +101,7 @@ func (m Model) viewGlobalVariables() string {
 		"Add New Variable",
 		"Edit Selected",
 		"Delete Selected",
+		"Toggle Secret",
 	}
 
 	for i, action := range actions {
@@ -108,7 +113,7 @@ func (m Model) viewGlobalVariables() string {
 	}
 
 	s.WriteString("\n")
-	s.WriteString(dimStyle.Render("↑/↓: navigate | tab: switch section | enter: select | esc: back"))
+	s.WriteString(dimStyle.Render("↑/↓: navigate | tab: switch section | enter: select | r: reveal/mask secrets | esc: back"))
 	s.WriteString("\n")
 
 	if m.editing {
@@ -122,3 +127,117 @@ func (m Model) viewGlobalVariables() string {
 
 	return s.String()
 }
+
+// viewResolvedVariables lists the effective variables with the layer each value comes from
+func (m Model) viewResolvedVariables() string {
+	var s strings.Builder
+
+	s.WriteString(titleStyle.Render("Resolved Variables"))
+	s.WriteString("\n\n")
+	s.WriteString(dimStyle.Render("Precedence: global < collection < global environment < collection environment"))
+	s.WriteString("\n\n")
+
+	if len(m.resolvedVariables) == 0 {
+		s.WriteString(dimStyle.Render("No variables defined."))
+		s.WriteString("\n")
+	}
+
+	for i, v := range m.resolvedVariables {
+		line := fmt.Sprintf("%s = %s", v.Name, v.Value)
+		origin := dimStyle.Render(fmt.Sprintf("(%s)", v.Source.Origin()))
+		if len(v.Overridden) > 0 {
+			origin += dimStyle.Render(fmt.Sprintf(" overrides %d", len(v.Overridden)))
+		}
+
+		if i == m.cursor {
+			s.WriteString(selectedStyle.Render("> "+line) + " " + origin + "\n")
+		} else {
+			s.WriteString("  " + line + " " + origin + "\n")
+		}
+	}
+
+	if m.cursor < len(m.resolvedVariables) {
+		v := m.resolvedVariables[m.cursor]
+		s.WriteString("\n" + v.Name + ":\n")
+		for _, line := range v.Details() {
+			s.WriteString("  " + line + "\n")
+		}
+	}
+
+	s.WriteString("\n")
+	s.WriteString(dimStyle.Render("↑/↓: navigate | r: reveal/mask secrets | esc: back"))
+	s.WriteString("\n")
+
+	if m.message != "" {
+		s.WriteString("\n" + successStyle.Render(m.message))
+	}
+
+	return s.String()
+}
+
+// openResolvedVariables shows where each effective variable of the collection comes from
+func (m *Model) openResolvedVariables() {
+	m.message = ""
+	m.refreshResolvedVariables()
+	m.currentView = viewResolvedVariables
+	m.cursor = 0
+}
+
+// refreshResolvedVariables resolves the variables shown by the inspector again, warning when secrets cannot be read
+func (m *Model) refreshResolvedVariables() {
+	variables, err := m.variableService.ResolveVariables(m.collection, m.revealSecrets)
+	m.resolvedVariables = variables
+	if err != nil {
+		m.message = fmt.Sprintf("Warning: %s", err)
+	}
+}
+
+// variableValue renders the value of a variable of a layer, showing secret values only when revealed
+func (m Model) variableValue(variables map[string]string, scope, key string) string {
+	value := variables[key]
+	if value != secrets.Marker || !m.revealSecrets {
+		return value
+	}
+	values, err := secrets.Values(scope)
+	if err != nil {
+		return value + dimStyle.Render(fmt.Sprintf(" (%s)", err))
+	}
+	return values[key] + dimStyle.Render(" (secret)")
+}
+
+// secretToggledMessage confirms a variable was marked secret or plain, given its value before the change
+func secretToggledMessage(key, value string, secret bool) string {
+	if secret && len(value) < secrets.MinMaskLength {
+		return fmt.Sprintf("Variable '%s' is secret, its value is stored encrypted but too short to be masked in output (under %d characters)", key, secrets.MinMaskLength)
+	}
+	if secret {
+		return fmt.Sprintf("Variable '%s' is secret, its value is stored encrypted", key)
+	}
+	return fmt.Sprintf("Variable '%s' is no longer secret", key)
+}
+
+// toggleEnvironmentSecret marks a variable of the environment being viewed as secret, or makes it plain again
+func (m Model) toggleEnvironmentSecret(key string) (tea.Model, tea.Cmd) {
+	var err error
+	var value string
+	var secret bool
+	if m.viewingCollectionEnv && m.currentCollectionEnv != nil {
+		value = m.currentCollectionEnv.Variables[key]
+		secret = value != secrets.Marker
+		err = m.environmentService.SetCollectionEnvironmentVariableSecret(m.collection, m.currentCollectionEnv.Name, key, secret)
+	} else if !m.viewingCollectionEnv && m.currentEnv != nil {
+		value = m.currentEnv.Variables[key]
+		secret = value != secrets.Marker
+		err = m.environmentService.SetGlobalEnvironmentVariableSecret(m.currentEnv.Name, key, secret)
+		if env, loadErr := m.environmentService.GetGlobalEnvironment(m.currentEnv.Name); err == nil && loadErr == nil {
+			m.currentEnv = env
+		}
+	}
+
+	if err != nil {
+		m.message = fmt.Sprintf("Error: %s", err)
+	} else {
+		m.message = secretToggledMessage(key, value, secret)
+	}
+	return m, nil
+}